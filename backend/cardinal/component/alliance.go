@@ -0,0 +1,140 @@
+package component
+
+import (
+	"pkg.world.dev/world-engine/cardinal"
+	"sync"
+)
+
+type AllianceComponent struct {
+	AllianceName string `json:"name"`
+	Leader       string `json:"leader"`
+	// Persona tags of the members in the order they joined, the leader included
+	Members []string `json:"members"`
+	// Persona tags invited by the leader that have not accepted yet
+	Invites     []string `json:"invites"`
+	CreatedTick int64    `json:"createdTick"`
+}
+
+func (AllianceComponent) Name() string {
+	return "AllianceComponent"
+}
+
+type AllianceEntity struct {
+	Component AllianceComponent
+	EntityId  cardinal.EntityID
+}
+
+// AllianceIndex maps the name of an alliance to its AllianceEntity
+var AllianceIndex sync.Map
+
+// AllianceMembershipIndex maps the persona tag of a member to the name of its alliance
+var AllianceMembershipIndex sync.Map
+
+func (alliance AllianceComponent) Set(wCtx cardinal.WorldContext, id cardinal.EntityID) error {
+	err := cardinal.SetComponent[AllianceComponent](wCtx, id, &alliance)
+	if err != nil {
+		wCtx.Logger().Error().Err(err).Msg("Failed to set alliance component")
+		return err
+	}
+
+	AllianceIndex.Store(alliance.AllianceName, AllianceEntity{
+		Component: alliance,
+		EntityId:  id,
+	})
+	storeAllianceMembers(alliance)
+	return nil
+}
+
+func (alliance AllianceComponent) Remove(wCtx cardinal.WorldContext, id cardinal.EntityID) error {
+	err := cardinal.Remove(wCtx, id)
+	if err != nil {
+		return err
+	}
+
+	AllianceIndex.Delete(alliance.AllianceName)
+	alliance.Members = nil
+	storeAllianceMembers(alliance)
+	return nil
+}
+
+// storeAllianceMembers points the membership index at the alliance for exactly its members
+func storeAllianceMembers(alliance AllianceComponent) {
+	AllianceMembershipIndex.Range(func(key, value interface{}) bool {
+		if value == alliance.AllianceName {
+			AllianceMembershipIndex.Delete(key)
+		}
+		return true
+	})
+	for _, member := range alliance.Members {
+		AllianceMembershipIndex.Store(member, alliance.AllianceName)
+	}
+}
+
+func LoadAllianceComponent(name string) (AllianceEntity, bool) {
+	value, ok := AllianceIndex.Load(name)
+	if !ok {
+		return AllianceEntity{}, false
+	}
+
+	alliance, ok := value.(AllianceEntity)
+	if !ok {
+		return AllianceEntity{}, false
+	}
+
+	return alliance, true
+}
+
+// LoadAllianceOf returns the alliance the persona is a member of
+func LoadAllianceOf(personaTag string) (AllianceEntity, bool) {
+	value, ok := AllianceMembershipIndex.Load(personaTag)
+	if !ok {
+		return AllianceEntity{}, false
+	}
+
+	name, ok := value.(string)
+	if !ok {
+		return AllianceEntity{}, false
+	}
+
+	return LoadAllianceComponent(name)
+}
+
+// AreFriendly reports whether ships of one persona reinforce planets of the other, i.e. they are
+// the same persona or members of the same alliance. Nobody is friendly with unowned planets.
+func AreFriendly(personaTag string, otherPersonaTag string) bool {
+	if personaTag == "" || otherPersonaTag == "" {
+		return false
+	}
+	if personaTag == otherPersonaTag {
+		return true
+	}
+	alliance, ok := AllianceMembershipIndex.Load(personaTag)
+	if !ok {
+		return false
+	}
+	otherAlliance, ok := AllianceMembershipIndex.Load(otherPersonaTag)
+	return ok && alliance == otherAlliance
+}
+
+func RebuildAllianceIndex(wCtx cardinal.WorldContext) error {
+	search, err := wCtx.NewSearch(cardinal.Exact(AllianceComponent{}))
+	if err != nil {
+		wCtx.Logger().Error().Err(err).Msg("Error performing search for alliance component in RebuildAllianceIndex()")
+		return err
+	}
+	search.Each(wCtx, func(id cardinal.EntityID) bool {
+		alliance, err := cardinal.GetComponent[AllianceComponent](wCtx, id)
+		if err != nil {
+			return true
+		}
+		AllianceIndex.Store(alliance.AllianceName, AllianceEntity{
+			Component: *alliance,
+			EntityId:  id,
+		})
+		for _, member := range alliance.Members {
+			AllianceMembershipIndex.Store(member, alliance.AllianceName)
+		}
+		return true
+	})
+	return nil
+}
@@ -0,0 +1,90 @@
+package component
+
+import (
+	"pkg.world.dev/world-engine/cardinal"
+	"sort"
+	"sync"
+)
+
+// BattleForce is the combined energy the ships of one persona brought to a battle
+type BattleForce struct {
+	OwnerPersonaTag string   `json:"ownerPersonaTag"`
+	ShipIds         []uint64 `json:"shipIds"`
+	Energy          string   `json:"energy"`
+}
+
+// BattleReportComponent records how the hostile ships landing on a planet in one tick were resolved
+type BattleReportComponent struct {
+	LocationHash       string        `json:"locationHash"`
+	Tick               int64         `json:"tick"`
+	DefenderPersonaTag string        `json:"defenderPersonaTag"`
+	Forces             []BattleForce `json:"forces"`
+	// Energy of the planet after its refill and the ships of the defender landed
+	DefenderEnergy string `json:"defenderEnergy"`
+	// Strongest attacker of the strongest side (alliance or persona), empty if the strongest sides are tied and cancel each other out
+	AttackerPersonaTag string `json:"attackerPersonaTag"`
+	// Energy of the strongest side minus the runner-up, after the defense of the planet
+	NetAttackEnergy string `json:"netAttackEnergy"`
+	Conquered       bool   `json:"conquered"`
+	OwnerPersonaTag string `json:"ownerPersonaTag"`
+	EnergyAfter     string `json:"energyAfter"`
+}
+
+func (BattleReportComponent) Name() string {
+	return "BattleReportComponent"
+}
+
+// BattleReportIndex maps a location hash to the battle reports of the planet, oldest first
+var BattleReportIndex sync.Map
+
+func (report BattleReportComponent) Set(wCtx cardinal.WorldContext, id cardinal.EntityID) error {
+	err := cardinal.SetComponent[BattleReportComponent](wCtx, id, &report)
+	if err != nil {
+		wCtx.Logger().Error().Err(err).Msg("Failed to set battle report component")
+		return err
+	}
+
+	reports := LoadBattleReports(report.LocationHash)
+	BattleReportIndex.Store(report.LocationHash, append(reports, report))
+	return nil
+}
+
+func LoadBattleReports(locationHash string) []BattleReportComponent {
+	value, ok := BattleReportIndex.Load(locationHash)
+	if !ok {
+		return nil
+	}
+
+	reports, ok := value.([]BattleReportComponent)
+	if !ok {
+		return nil
+	}
+
+	// copy so that appending to the result doesn't share memory with the index
+	return append([]BattleReportComponent(nil), reports...)
+}
+
+func RebuildBattleReportIndex(wCtx cardinal.WorldContext) error {
+	search, err := wCtx.NewSearch(cardinal.Exact(BattleReportComponent{}))
+	if err != nil {
+		wCtx.Logger().Error().Err(err).Msg("Error performing search for battle report component in RebuildBattleReportIndex()")
+		return err
+	}
+	reportsByPlanet := make(map[string][]BattleReportComponent)
+	search.Each(wCtx, func(id cardinal.EntityID) bool {
+		report, err := cardinal.GetComponent[BattleReportComponent](wCtx, id)
+		if err != nil {
+			return true
+		}
+		reportsByPlanet[report.LocationHash] = append(reportsByPlanet[report.LocationHash], *report)
+		return true
+	})
+	for locationHash, reports := range reportsByPlanet {
+		// at most one battle per planet per tick
+		sort.Slice(reports, func(i, j int) bool {
+			return reports[i].Tick < reports[j].Tick
+		})
+		BattleReportIndex.Store(locationHash, reports)
+	}
+	return nil
+}
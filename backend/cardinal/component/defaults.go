@@ -1,11 +1,16 @@
 package component
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
 	"pkg.world.dev/world-engine/cardinal"
 )
 
 type DefaultsComponent struct {
+	// Random ID of the game, generated with the component. A world that is reset starts a new game and
+	// counts its ticks from 0 again. Empty for worlds created before games had an ID.
+	GameID               string
 	WorldConstants       game.WorldConstant
 	NebulaSpaceConstants game.SpaceConstant
 	SafeSpaceConstants   game.SpaceConstant
@@ -29,8 +34,11 @@ func LoadDefaultsComponent(wCtx cardinal.WorldContext) (dc *DefaultsComponent, e
 		return nil, err
 	}
 
-	// Found existing defaults component, set game constants using it
+	// Found existing defaults component, set game constants using it. The instance name stays the
+	// one cardinal was started with, the leaderboards are namespaced by it.
+	instanceName := game.WorldConstants.InstanceName
 	game.WorldConstants = dc.WorldConstants
+	game.WorldConstants.InstanceName = instanceName
 	game.NebulaSpaceConstants = dc.NebulaSpaceConstants
 	game.SafeSpaceConstants = dc.SafeSpaceConstants
 	game.DeepSpaceConstants = dc.DeepSpaceConstants
@@ -64,7 +72,13 @@ func LoadDefaultsComponent(wCtx cardinal.WorldContext) (dc *DefaultsComponent, e
 }
 
 func BuildAndSetDefaultsComponent(wCtx cardinal.WorldContext) error {
+	gameID := make([]byte, 16)
+	if _, err := rand.Read(gameID); err != nil {
+		wCtx.Logger().Error().Err(err).Msg("Failed to generate the game ID")
+		return err
+	}
 	dc := DefaultsComponent{
+		GameID:               hex.EncodeToString(gameID),
 		WorldConstants:       game.WorldConstants,
 		NebulaSpaceConstants: game.NebulaSpaceConstants,
 		SafeSpaceConstants:   game.SafeSpaceConstants,
@@ -171,3 +185,12 @@ func GetDefaultsComponent(wCtx cardinal.WorldContext) (dc *DefaultsComponent, id
 
 	return dc, id, nil
 }
+
+// GetGameID returns the GameID of the DefaultsComponent
+func GetGameID(wCtx cardinal.WorldContext) (string, error) {
+	dc, _, err := GetDefaultsComponent(wCtx)
+	if err != nil {
+		return "", err
+	}
+	return dc.GameID, nil
+}
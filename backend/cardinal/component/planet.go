@@ -19,6 +19,16 @@ type PlanetComponent struct {
 	LastUpdateRefillAge *decimal.Big `json:"lastUpdateRefillAge"`
 	LastUpdateTick      *decimal.Big `json:"lastUpdateTick"`
 	SpaceArea           int64        `json:"spaceArea"`
+	// Set once someone proves the coordinates of the planet with a reveal-location message
+	Revealed     bool   `json:"revealed"`
+	RevealedX    int64  `json:"revealedX"`
+	RevealedY    int64  `json:"revealedY"`
+	RevealedBy   string `json:"revealedBy"`
+	RevealedTick int64  `json:"revealedTick"`
+	// Ranks of the stat branches raised with upgrade-planet messages, see game.PlanetUpgradeConstants
+	RangeRank   int64 `json:"rangeRank"`
+	SpeedRank   int64 `json:"speedRank"`
+	DefenseRank int64 `json:"defenseRank"`
 }
 
 func (PlanetComponent) Name() string {
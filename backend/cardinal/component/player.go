@@ -8,12 +8,22 @@ import (
 type PlayerComponent struct {
 	PersonaTag            string `json:"personaTag"`
 	HaveClaimedHomePlanet bool   `json:"haveClaimedHomePlanet"`
+	// Number of planets conquered, the score of the player on the conquests leaderboard
+	Conquests int `json:"conquests"`
+	// Energy of the ships sent, the score of the player on the energy-sent leaderboard
+	EnergySent int `json:"energySent"`
 }
 
 func (PlayerComponent) Name() string {
 	return "PlayerComponent"
 }
 
+type PlayerEntity struct {
+	Component PlayerComponent
+	EntityId  cardinal.EntityID
+}
+
+// PlayerIndex maps the persona tag of a player to its PlayerEntity
 var PlayerIndex sync.Map
 
 func (player PlayerComponent) Set(wCtx cardinal.WorldContext, id cardinal.EntityID) error {
@@ -23,22 +33,30 @@ func (player PlayerComponent) Set(wCtx cardinal.WorldContext, id cardinal.Entity
 		return err
 	}
 
-	PlayerIndex.Store(player.PersonaTag, player)
+	PlayerIndex.Store(player.PersonaTag, PlayerEntity{
+		Component: player,
+		EntityId:  id,
+	})
 	return nil
 }
 
 func LoadPlayerComponent(key string) (PlayerComponent, bool) {
+	playerEntity, ok := LoadPlayerEntity(key)
+	return playerEntity.Component, ok
+}
+
+func LoadPlayerEntity(key string) (PlayerEntity, bool) {
 	value, ok := PlayerIndex.Load(key)
 	if !ok {
-		return PlayerComponent{}, false
+		return PlayerEntity{}, false
 	}
 
-	player, ok := value.(PlayerComponent)
+	playerEntity, ok := value.(PlayerEntity)
 	if !ok {
-		return PlayerComponent{}, false
+		return PlayerEntity{}, false
 	}
 
-	return player, true
+	return playerEntity, true
 }
 
 func RebuildPlayerIndex(wCtx cardinal.WorldContext) error {
@@ -52,7 +70,10 @@ func RebuildPlayerIndex(wCtx cardinal.WorldContext) error {
 		if err != nil {
 			return true
 		}
-		PlayerIndex.Store(player.PersonaTag, *player)
+		PlayerIndex.Store(player.PersonaTag, PlayerEntity{
+			Component: *player,
+			EntityId:  id,
+		})
 		return true
 	})
 	return nil
@@ -0,0 +1,60 @@
+package component
+
+import (
+	"pkg.world.dev/world-engine/cardinal"
+	"sync"
+)
+
+// ProofNullifierComponent marks a proof as used, Digest is tx.ProofDigest of the accepted message
+type ProofNullifierComponent struct {
+	Digest string `json:"digest"`
+	Tick   uint64 `json:"tick"`
+}
+
+func (ProofNullifierComponent) Name() string {
+	return "ProofNullifierComponent"
+}
+
+var ProofNullifierIndex sync.Map
+
+func (nullifier ProofNullifierComponent) Set(wCtx cardinal.WorldContext, id cardinal.EntityID) error {
+	err := cardinal.SetComponent[ProofNullifierComponent](wCtx, id, &nullifier)
+	if err != nil {
+		wCtx.Logger().Error().Err(err).Msg("Failed to set proof nullifier component")
+		return err
+	}
+
+	ProofNullifierIndex.Store(nullifier.Digest, nullifier)
+	return nil
+}
+
+func LoadProofNullifierComponent(digest string) (ProofNullifierComponent, bool) {
+	value, ok := ProofNullifierIndex.Load(digest)
+	if !ok {
+		return ProofNullifierComponent{}, false
+	}
+
+	nullifier, ok := value.(ProofNullifierComponent)
+	if !ok {
+		return ProofNullifierComponent{}, false
+	}
+
+	return nullifier, true
+}
+
+func RebuildProofNullifierIndex(wCtx cardinal.WorldContext) error {
+	search, err := wCtx.NewSearch(cardinal.Exact(ProofNullifierComponent{}))
+	if err != nil {
+		wCtx.Logger().Error().Err(err).Msg("Error performing search for proof nullifier component in RebuildProofNullifierIndex()")
+		return err
+	}
+	search.Each(wCtx, func(id cardinal.EntityID) bool {
+		nullifier, err := cardinal.GetComponent[ProofNullifierComponent](wCtx, id)
+		if err != nil {
+			return true
+		}
+		ProofNullifierIndex.Store(nullifier.Digest, *nullifier)
+		return true
+	})
+	return nil
+}
@@ -13,6 +13,8 @@ type ShipComponent struct {
 	TickStart        int64
 	TickArrive       int64
 	EnergyOnEmbark   *decimal.Big
+	// Set once the ship turned around, it then travels from its destination back to its origin
+	Recalled bool
 }
 
 func (ShipComponent) Name() string {
@@ -32,6 +34,20 @@ func (ship ShipComponent) Set(wCtx cardinal.WorldContext, id cardinal.EntityID)
 	return nil
 }
 
+func LoadShipComponent(id cardinal.EntityID) (ShipComponent, bool) {
+	value, ok := ShipIndex.Load(id)
+	if !ok {
+		return ShipComponent{}, false
+	}
+
+	ship, ok := value.(ShipComponent)
+	if !ok {
+		return ShipComponent{}, false
+	}
+
+	return ship, true
+}
+
 func (ship ShipComponent) Remove(wCtx cardinal.WorldContext, id cardinal.EntityID) error {
 	err := cardinal.Remove(wCtx, id)
 	if err != nil {
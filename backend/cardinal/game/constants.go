@@ -1,24 +1,77 @@
 package game
 
+import (
+	"fmt"
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+)
+
 type Constant struct {
 	Label string
 	Value any
 }
 
 type WorldConstant struct {
-	MiMCSeedWord          string
-	PerlinSeedWord        string
-	MiMCNumRounds         int
-	PerlinNumRounds       int
-	XMirror               int
-	YMirror               int
-	Scale                 int
-	CircuitArtifactUUID   string
-	RadiusMax             int64
+	MiMCSeedWord    string
+	PerlinSeedWord  string
+	MiMCNumRounds   int
+	PerlinNumRounds int
+	// Octave weights and output buckets of the perlin noise. Like the seeds and numbers of
+	// rounds they are compiled into the circuits, see circuit/cmd/build-artifacts. None of them
+	// can be set at runtime, cardinal checks them against the circuit artifacts when it starts.
+	PerlinOctaveWeights []int64
+	PerlinBuckets       int64
+	XMirror             int
+	YMirror             int
+	Scale               int
+	CircuitArtifactUUID string
+	// Older artifact versions whose proofs are still accepted, CircuitArtifactUUID always is
+	AcceptedCircuitArtifactUUIDs []string
+	RadiusMax                    int64
+	// Perlin values below the first threshold are nebula, below the second safe space and deep
+	// space otherwise, see CheckSpacePerlinThresholds
 	SpacePerlinThresholds []int64
 	InstanceName          string
 	InstanceTimer         int
 	TickRate              int
+	// Home planets must be claimed with the rim-spawn init circuit, in the outer rim of RadiusMax
+	RimSpawn bool
+	// Fraction of its energy a ship loses when it is recalled, decimal
+	RecallPenalty string
+	// Fraction of its energy a planet loses when it is abandoned, decimal
+	AbandonPenalty string
+	// Ticks between recomputing the scores from the owned planets, 0 only recomputes them on startup
+	ScoreReconcileInterval int
+	// Overwrite leaderboard scores that disagree with the owned planets, otherwise only report them
+	ScoreReconcileFix bool
+	// Ticks between snapshots of the leaderboard, 0 only snapshots it at the end of the game
+	LeaderboardSnapshotInterval int
+}
+
+// PerlinProfile the circuits are compiled with
+func (c WorldConstant) PerlinProfile() perlin.Profile {
+	return perlin.Profile{
+		Weights:   c.PerlinOctaveWeights,
+		Buckets:   c.PerlinBuckets,
+		NumRounds: c.PerlinNumRounds,
+	}
+}
+
+// CheckSpacePerlinThresholds checks that SpacePerlinThresholds split the perlin values
+// [0, 2*PerlinBuckets] into a non-empty range per space area
+func (c WorldConstant) CheckSpacePerlinThresholds() error {
+	thresholds := c.SpacePerlinThresholds
+	maxPerlin := 2 * c.PerlinProfile().OrDefault().Buckets
+	if len(thresholds) != len(SpaceConstants)-1 {
+		return fmt.Errorf("SpacePerlinThresholds must have %d values, got %v", len(SpaceConstants)-1, thresholds)
+	}
+	previous := int64(0)
+	for _, threshold := range thresholds {
+		if threshold <= previous || threshold > maxPerlin {
+			return fmt.Errorf("SpacePerlinThresholds must be ascending perlin values in [1, %d], got %v", maxPerlin, thresholds)
+		}
+		previous = threshold
+	}
+	return nil
 }
 
 type PlanetLevelStats struct {
@@ -41,6 +94,16 @@ type SpaceConstant struct {
 	ScoreMultiplier         string     // decimal
 }
 
+// PlanetUpgradeConstant prices the upgrade-planet message, each stat branch has its own rank.
+// It is read-only: the stats of upgraded planets are computed with it when they are upgraded,
+// so changing it at runtime would leave them behind. Unlike the other constants it is neither
+// settable with set-constant nor saved in the DefaultsComponent.
+type PlanetUpgradeConstant struct {
+	MaxRank             [11]int64 // highest rank of a branch, per planet level
+	StatIncreasePerRank string    // decimal, fraction of the level and space area adjusted stat
+	EnergyCost          string    // decimal, fraction of the planet's max energy spent per upgrade
+}
+
 var (
 	AllConstantsLabel = "all"
 	// ExposedConstants If you want the constant to be queryable through `query_constant`,
@@ -60,22 +123,42 @@ var (
 			Label: "base_planet_level_stats",
 			Value: &BasePlanetLevelStats,
 		},
+		{
+			// Exposed as a copy, the planet upgrade constants are read-only
+			Label: "planet_upgrades",
+			Value: PlanetUpgradeConstants,
+		},
 	}
 
 	WorldConstants = WorldConstant{
-		MiMCSeedWord:          "1",
-		PerlinSeedWord:        "1",
-		MiMCNumRounds:         110,
-		PerlinNumRounds:       4,
-		XMirror:               0,
-		YMirror:               0,
-		Scale:                 256,
-		CircuitArtifactUUID:   "084d8871-4cdb-46ab-b541-298cde6f9236",
-		RadiusMax:             0, // Set in SetConstantsFromEnv()
-		SpacePerlinThresholds: []int64{15, 17},
-		InstanceName:          "", // Set in SetConstantsFromEnv()
-		InstanceTimer:         0,  // Set in SetConstantsFromEnv()
-		TickRate:              2,  // Ticks per second
+		MiMCSeedWord:                 "1",
+		PerlinSeedWord:               "1",
+		MiMCNumRounds:                110,
+		PerlinNumRounds:              4,
+		PerlinOctaveWeights:          []int64{2, 1, 1},
+		PerlinBuckets:                16,
+		XMirror:                      0,
+		YMirror:                      0,
+		Scale:                        256,
+		CircuitArtifactUUID:          "376f02d4-1c5c-44f6-b2b5-86657dd15e0e",
+		AcceptedCircuitArtifactUUIDs: []string{},
+		RadiusMax:                    0, // Set in SetConstantsFromEnv()
+		SpacePerlinThresholds:        []int64{15, 17},
+		InstanceName:                 "", // Set in SetConstantsFromEnv()
+		InstanceTimer:                0,  // Set in SetConstantsFromEnv()
+		TickRate:                     2,  // Ticks per second
+		RimSpawn:                     false,
+		RecallPenalty:                "0.2",
+		AbandonPenalty:               "0",
+		ScoreReconcileInterval:       600, // 5 minutes
+		ScoreReconcileFix:            true,
+		LeaderboardSnapshotInterval:  120, // 1 minute
+	}
+
+	PlanetUpgradeConstants = PlanetUpgradeConstant{
+		MaxRank:             [11]int64{0, 1, 1, 2, 2, 3, 3, 4, 4, 5, 5},
+		StatIncreasePerRank: "0.2",
+		EnergyCost:          "0.5",
 	}
 
 	SpaceConstants = [3]*SpaceConstant{
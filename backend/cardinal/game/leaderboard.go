@@ -2,6 +2,7 @@ package game
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/redis/go-redis/v9"
 )
@@ -16,62 +17,130 @@ type RankedPlayer struct {
 	Rank int `json:"rank"`
 }
 
-const leaderboardKey = "leaderboardKey"
+// ErrPlayerNotFound is returned when a persona has no score on the leaderboard
+var ErrPlayerNotFound = errors.New("not found in leaderboard")
 
-var LeaderboardClient *redis.Client
+type ScoreOp int
 
-func AddPlayerToLeaderboard(ctx context.Context, player Player) error {
+const (
+	// ScoreOpAdd adds the amount to the score, a missing member starts at 0
+	ScoreOpAdd ScoreOp = iota
+	// ScoreOpSet replaces the score with the amount
+	ScoreOpSet
+	// ScoreOpRemove removes the member from the board
+	ScoreOpRemove
+)
+
+// ScoreMutation is a change to the score of a member of a board, see LeaderboardNames
+type ScoreMutation struct {
+	Board string
+	// Persona tag of a player, or the name of an alliance on BoardAlliances
+	Member string
+	Op     ScoreOp
+	Amount int
+}
+
+func ScoreIncrement(board, member string, amount int) ScoreMutation {
+	return ScoreMutation{Board: board, Member: member, Op: ScoreOpAdd, Amount: amount}
+}
+
+func ScoreDecrement(board, member string, amount int) ScoreMutation {
+	return ScoreMutation{Board: board, Member: member, Op: ScoreOpAdd, Amount: -amount}
+}
+
+func ScoreSet(board, member string, score int) ScoreMutation {
+	return ScoreMutation{Board: board, Member: member, Op: ScoreOpSet, Amount: score}
+}
+
+func ScoreRemove(board, member string) ScoreMutation {
+	return ScoreMutation{Board: board, Member: member, Op: ScoreOpRemove}
+}
+
+// ScoreBatch is the score mutations of one tick, in the order they were made
+type ScoreBatch struct {
+	// Game the tick belongs to, see component.DefaultsComponent. Every game counts its ticks from 0,
+	// so the committed tick is kept per game. Empty for worlds created before games had an ID.
+	Game      string
+	Tick      uint64
+	Mutations []ScoreMutation
+}
+
+// Leaderboard ranks players by their score, highest first. Players with the same score are
+// ranked by persona tag in reverse lexicographic order, like a Redis sorted set.
+type Leaderboard interface {
+	AddPlayer(ctx context.Context, player Player) error
+	IncrementScore(ctx context.Context, personaTag string, amount int) error
+	DecrementScore(ctx context.Context, personaTag string, amount int) error
+	SetScore(ctx context.Context, personaTag string, newScore int) error
+	// GetPlayerRankAndScore returns the 1-based rank of the player and its score
+	GetPlayerRankAndScore(ctx context.Context, personaTag string) (int64, float64, error)
+	// GetPlayersInRankRange returns the players between the 0-based ranks, both included.
+	// Negative ranks count from the lowest ranked player, -1 being the last one.
+	GetPlayersInRankRange(ctx context.Context, startRank, endRank int64) ([]RankedPlayer, error)
+}
+
+// RedisLeaderboard keeps the leaderboard in a Redis sorted set
+type RedisLeaderboard struct {
+	client *redis.Client
+	key    string
+}
+
+func NewRedisLeaderboard(client *redis.Client, key string) *RedisLeaderboard {
+	return &RedisLeaderboard{client: client, key: key}
+}
+
+func (lb *RedisLeaderboard) AddPlayer(ctx context.Context, player Player) error {
 	z := &redis.Z{
 		Score:  float64(player.Score),
 		Member: player.PersonaTag,
 	}
 
-	_, err := LeaderboardClient.ZAdd(ctx, leaderboardKey, *z).Result()
+	_, err := lb.client.ZAdd(ctx, lb.key, *z).Result()
 	return err
 }
 
-func IncrementScore(ctx context.Context, personaTag string, amount int) error {
-	_, err := LeaderboardClient.ZIncrBy(ctx, leaderboardKey, float64(amount), personaTag).Result()
+func (lb *RedisLeaderboard) IncrementScore(ctx context.Context, personaTag string, amount int) error {
+	_, err := lb.client.ZIncrBy(ctx, lb.key, float64(amount), personaTag).Result()
 	return err
 }
 
-func DecrementScore(ctx context.Context, personaTag string, amount int) error {
+func (lb *RedisLeaderboard) DecrementScore(ctx context.Context, personaTag string, amount int) error {
 	// Use a negative amount to decrement the score
-	_, err := LeaderboardClient.ZIncrBy(ctx, leaderboardKey, float64(-amount), personaTag).Result()
+	_, err := lb.client.ZIncrBy(ctx, lb.key, float64(-amount), personaTag).Result()
 	return err
 }
 
-func SetScore(ctx context.Context, personaTag string, newScore int) error {
+func (lb *RedisLeaderboard) SetScore(ctx context.Context, personaTag string, newScore int) error {
 	// Get the current score
-	currentScore, err := LeaderboardClient.ZScore(ctx, leaderboardKey, personaTag).Result()
+	currentScore, err := lb.client.ZScore(ctx, lb.key, personaTag).Result()
 	if err != nil {
-		return err
+		return redisPlayerError(personaTag, err)
 	}
 
 	// Calculate the difference between the new score and the current score
 	scoreDifference := float64(newScore) - currentScore
 
 	// Increment the member's score to reach the desired value
-	_, err = LeaderboardClient.ZIncrBy(ctx, leaderboardKey, scoreDifference, personaTag).Result()
+	_, err = lb.client.ZIncrBy(ctx, lb.key, scoreDifference, personaTag).Result()
 	return err
 }
 
-func GetPlayerRankAndScore(ctx context.Context, personaTag string) (int64, float64, error) {
-	rank, err := LeaderboardClient.ZRevRank(ctx, leaderboardKey, personaTag).Result()
+func (lb *RedisLeaderboard) GetPlayerRankAndScore(ctx context.Context, personaTag string) (int64, float64, error) {
+	rank, err := lb.client.ZRevRank(ctx, lb.key, personaTag).Result()
 	if err != nil {
-		return -1, 0, fmt.Errorf("player %s not found in leaderboard err: %v", personaTag, err)
+		return -1, 0, redisPlayerError(personaTag, err)
 	}
 
-	score, err := LeaderboardClient.ZScore(ctx, leaderboardKey, personaTag).Result()
+	score, err := lb.client.ZScore(ctx, lb.key, personaTag).Result()
 	if err != nil {
-		return -1, 0, err
+		return -1, 0, redisPlayerError(personaTag, err)
 	}
 
 	return rank + 1, score, nil // Adding 1 to the rank since it's 0-based
 }
 
-func GetPlayersInRankRange(ctx context.Context, startRank, endRank int64) ([]RankedPlayer, error) {
-	leaderboard, err := LeaderboardClient.ZRevRangeWithScores(ctx, leaderboardKey, startRank, endRank).Result()
+func (lb *RedisLeaderboard) GetPlayersInRankRange(ctx context.Context, startRank, endRank int64) ([]RankedPlayer, error) {
+	leaderboard, err := lb.client.ZRevRangeWithScores(ctx, lb.key, startRank, endRank).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -89,3 +158,11 @@ func GetPlayersInRankRange(ctx context.Context, startRank, endRank int64) ([]Ran
 
 	return players, nil
 }
+
+// redisPlayerError wraps ErrPlayerNotFound if Redis has no score for the persona
+func redisPlayerError(personaTag string, err error) error {
+	if errors.Is(err, redis.Nil) {
+		return fmt.Errorf("player %s %w", personaTag, ErrPlayerNotFound)
+	}
+	return fmt.Errorf("failed to read player %s from leaderboard: %w", personaTag, err)
+}
@@ -0,0 +1,257 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ErrSnapshotNotFound is returned when no leaderboard snapshot was taken at a tick
+var ErrSnapshotNotFound = errors.New("leaderboard snapshot not found")
+
+// TimelinePoint is the rank and score of a persona in the snapshot taken at Tick
+type TimelinePoint struct {
+	Tick  uint64 `json:"tick"`
+	Rank  int64  `json:"rank"`
+	Score int    `json:"score"`
+}
+
+// LeaderboardHistory stores snapshots of the BoardScore leaderboard, ranked the same way as the leaderboard.
+// Snapshots are kept per game, see ScoreBatch.Game, so a reset world that counts its ticks from 0 again
+// doesn't overwrite the snapshots of the previous game.
+type LeaderboardHistory interface {
+	// SaveSnapshot stores the players as the ranking at the tick of the game, saving a tick again replaces it
+	SaveSnapshot(ctx context.Context, game string, tick uint64, players []Player) error
+	// SnapshotTicks returns the ticks of all snapshots of the game in ascending order
+	SnapshotTicks(ctx context.Context, game string) ([]uint64, error)
+	// GetSnapshotTop returns the n highest ranked players of the snapshot taken at the tick of the game
+	GetSnapshotTop(ctx context.Context, game string, tick uint64, n int64) ([]RankedPlayer, error)
+	// GetPlayerTimeline returns the rank and score of the persona in every snapshot of the game it
+	// is part of, in tick order
+	GetPlayerTimeline(ctx context.Context, game string, personaTag string) ([]TimelinePoint, error)
+}
+
+// RedisLeaderboardHistory keeps every snapshot in a Redis sorted set of its own, its keys are
+// namespaced like the ones of RedisLeaderboardRegistry and then by game
+type RedisLeaderboardHistory struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisLeaderboardHistory(client *redis.Client, instanceName string) *RedisLeaderboardHistory {
+	return &RedisLeaderboardHistory{client: client, prefix: LeaderboardKeyPrefix(instanceName)}
+}
+
+// gamePrefix namespaces the keys of the game, games without an ID use the keys of worlds created
+// before games had one
+func (h *RedisLeaderboardHistory) gamePrefix(game string) string {
+	if game == "" {
+		return h.prefix
+	}
+	return h.prefix + ":" + game
+}
+
+// snapshotTicksKey is the sorted set of the ticks of all snapshots of the game, scored by tick
+func (h *RedisLeaderboardHistory) snapshotTicksKey(game string) string {
+	return h.gamePrefix(game) + ":snapshots"
+}
+
+// snapshotKey is the sorted set holding the snapshot taken at the tick of the game
+func (h *RedisLeaderboardHistory) snapshotKey(game string, tick uint64) string {
+	return fmt.Sprintf("%s:snapshot:%d", h.gamePrefix(game), tick)
+}
+
+func (h *RedisLeaderboardHistory) SaveSnapshot(ctx context.Context, game string, tick uint64, players []Player) error {
+	key := h.snapshotKey(game, tick)
+	_, err := h.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(players) > 0 {
+			members := make([]redis.Z, len(players))
+			for i, player := range players {
+				members[i] = redis.Z{Score: float64(player.Score), Member: player.PersonaTag}
+			}
+			pipe.ZAdd(ctx, key, members...)
+		}
+		pipe.ZAdd(ctx, h.snapshotTicksKey(game), redis.Z{Score: float64(tick), Member: strconv.FormatUint(tick, 10)})
+		return nil
+	})
+	return err
+}
+
+func (h *RedisLeaderboardHistory) SnapshotTicks(ctx context.Context, game string) ([]uint64, error) {
+	members, err := h.client.ZRange(ctx, h.snapshotTicksKey(game), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	ticks := make([]uint64, len(members))
+	for i, member := range members {
+		ticks[i], err = strconv.ParseUint(member, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse snapshot tick %s: %w", member, err)
+		}
+	}
+	return ticks, nil
+}
+
+func (h *RedisLeaderboardHistory) GetSnapshotTop(ctx context.Context, game string, tick uint64, n int64) ([]RankedPlayer, error) {
+	_, err := h.client.ZScore(ctx, h.snapshotTicksKey(game), strconv.FormatUint(tick, 10)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("%w at tick %d", ErrSnapshotNotFound, tick)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return []RankedPlayer{}, nil
+	}
+
+	snapshot, err := h.client.ZRevRangeWithScores(ctx, h.snapshotKey(game, tick), 0, n-1).Result()
+	if err != nil {
+		return nil, err
+	}
+	players := make([]RankedPlayer, len(snapshot))
+	for i, z := range snapshot {
+		players[i] = RankedPlayer{
+			Player: Player{
+				PersonaTag: z.Member.(string),
+				Score:      int(z.Score),
+			},
+			Rank: i + 1,
+		}
+	}
+	return players, nil
+}
+
+func (h *RedisLeaderboardHistory) GetPlayerTimeline(ctx context.Context, game string, personaTag string) ([]TimelinePoint, error) {
+	ticks, err := h.SnapshotTicks(ctx, game)
+	if err != nil {
+		return nil, err
+	}
+
+	// Read the rank and score of every snapshot in one round trip
+	ranks := make([]*redis.IntCmd, len(ticks))
+	scores := make([]*redis.FloatCmd, len(ticks))
+	_, err = h.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, tick := range ticks {
+			ranks[i] = pipe.ZRevRank(ctx, h.snapshotKey(game, tick), personaTag)
+			scores[i] = pipe.ZScore(ctx, h.snapshotKey(game, tick), personaTag)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	timeline := make([]TimelinePoint, 0, len(ticks))
+	for i, tick := range ticks {
+		rank, err := ranks[i].Result()
+		if errors.Is(err, redis.Nil) {
+			// The persona wasn't on the leaderboard yet
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		score, err := scores[i].Result()
+		if err != nil {
+			return nil, err
+		}
+		timeline = append(timeline, TimelinePoint{Tick: tick, Rank: rank + 1, Score: int(score)})
+	}
+	return timeline, nil
+}
+
+// MemoryLeaderboardHistory keeps the snapshots in process memory, see MemoryLeaderboard
+type MemoryLeaderboardHistory struct {
+	mu sync.RWMutex
+	// Snapshots by game and tick
+	snapshots map[string]map[uint64][]RankedPlayer
+}
+
+func NewMemoryLeaderboardHistory() *MemoryLeaderboardHistory {
+	return &MemoryLeaderboardHistory{snapshots: make(map[string]map[uint64][]RankedPlayer)}
+}
+
+func (h *MemoryLeaderboardHistory) SaveSnapshot(_ context.Context, game string, tick uint64, players []Player) error {
+	// Rank the players the way the leaderboard does
+	ranked := NewMemoryLeaderboard()
+	for _, player := range players {
+		ranked.set(player.PersonaTag, float64(player.Score))
+	}
+	snapshot := make([]RankedPlayer, len(ranked.ranked))
+	for i, entry := range ranked.ranked {
+		snapshot[i] = RankedPlayer{
+			Player: Player{
+				PersonaTag: entry.personaTag,
+				Score:      int(entry.score),
+			},
+			Rank: i + 1,
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.snapshots[game] == nil {
+		h.snapshots[game] = make(map[uint64][]RankedPlayer)
+	}
+	h.snapshots[game][tick] = snapshot
+	return nil
+}
+
+func (h *MemoryLeaderboardHistory) SnapshotTicks(_ context.Context, game string) ([]uint64, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.ticks(game), nil
+}
+
+func (h *MemoryLeaderboardHistory) GetSnapshotTop(_ context.Context, game string, tick uint64, n int64) ([]RankedPlayer, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	snapshot, ok := h.snapshots[game][tick]
+	if !ok {
+		return nil, fmt.Errorf("%w at tick %d", ErrSnapshotNotFound, tick)
+	}
+	if n <= 0 {
+		return []RankedPlayer{}, nil
+	}
+	if n > int64(len(snapshot)) {
+		n = int64(len(snapshot))
+	}
+	return append([]RankedPlayer{}, snapshot[:n]...), nil
+}
+
+func (h *MemoryLeaderboardHistory) GetPlayerTimeline(_ context.Context, game string, personaTag string) ([]TimelinePoint, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ticks := h.ticks(game)
+	timeline := make([]TimelinePoint, 0, len(ticks))
+	for _, tick := range ticks {
+		for _, player := range h.snapshots[game][tick] {
+			if player.PersonaTag == personaTag {
+				timeline = append(timeline, TimelinePoint{Tick: tick, Rank: int64(player.Rank), Score: player.Score})
+				break
+			}
+		}
+	}
+	return timeline, nil
+}
+
+// ticks returns the ticks of the snapshots of the game in ascending order, callers must hold the lock
+func (h *MemoryLeaderboardHistory) ticks(game string) []uint64 {
+	ticks := make([]uint64, 0, len(h.snapshots[game]))
+	for tick := range h.snapshots[game] {
+		ticks = append(ticks, tick)
+	}
+	sort.Slice(ticks, func(i, j int) bool {
+		return ticks[i] < ticks[j]
+	})
+	return ticks
+}
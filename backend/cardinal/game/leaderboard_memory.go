@@ -0,0 +1,139 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type memoryEntry struct {
+	personaTag string
+	score      float64
+}
+
+// MemoryLeaderboard keeps the leaderboard in process memory, for tests and local sandboxes
+// that run without Redis. Entries are kept in rank order, so reads don't sort.
+type MemoryLeaderboard struct {
+	mu     sync.RWMutex
+	ranked []memoryEntry
+	byTag  map[string]float64
+}
+
+func NewMemoryLeaderboard() *MemoryLeaderboard {
+	return &MemoryLeaderboard{byTag: make(map[string]float64)}
+}
+
+func (lb *MemoryLeaderboard) AddPlayer(_ context.Context, player Player) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	lb.set(player.PersonaTag, float64(player.Score))
+	return nil
+}
+
+func (lb *MemoryLeaderboard) IncrementScore(_ context.Context, personaTag string, amount int) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	// Like ZINCRBY, a missing player starts at 0
+	lb.set(personaTag, lb.byTag[personaTag]+float64(amount))
+	return nil
+}
+
+func (lb *MemoryLeaderboard) DecrementScore(ctx context.Context, personaTag string, amount int) error {
+	return lb.IncrementScore(ctx, personaTag, -amount)
+}
+
+func (lb *MemoryLeaderboard) SetScore(_ context.Context, personaTag string, newScore int) error {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+
+	if _, ok := lb.byTag[personaTag]; !ok {
+		return fmt.Errorf("player %s %w", personaTag, ErrPlayerNotFound)
+	}
+	lb.set(personaTag, float64(newScore))
+	return nil
+}
+
+func (lb *MemoryLeaderboard) GetPlayerRankAndScore(_ context.Context, personaTag string) (int64, float64, error) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	score, ok := lb.byTag[personaTag]
+	if !ok {
+		return -1, 0, fmt.Errorf("player %s %w", personaTag, ErrPlayerNotFound)
+	}
+	return int64(lb.search(personaTag, score)) + 1, score, nil
+}
+
+func (lb *MemoryLeaderboard) GetPlayersInRankRange(_ context.Context, startRank, endRank int64) ([]RankedPlayer, error) {
+	lb.mu.RLock()
+	defer lb.mu.RUnlock()
+
+	// Normalize the range the way ZREVRANGE does
+	n := int64(len(lb.ranked))
+	if startRank < 0 {
+		startRank += n
+	}
+	if endRank < 0 {
+		endRank += n
+	}
+	if startRank < 0 {
+		startRank = 0
+	}
+	if endRank >= n {
+		endRank = n - 1
+	}
+	if startRank > endRank {
+		return []RankedPlayer{}, nil
+	}
+
+	players := make([]RankedPlayer, 0, endRank-startRank+1)
+	for i, entry := range lb.ranked[startRank : endRank+1] {
+		players = append(players, RankedPlayer{
+			Player: Player{
+				PersonaTag: entry.personaTag,
+				Score:      int(entry.score),
+			},
+			Rank: i + 1,
+		})
+	}
+	return players, nil
+}
+
+// remove takes the persona off the leaderboard, callers must hold the write lock
+func (lb *MemoryLeaderboard) remove(personaTag string) {
+	oldScore, ok := lb.byTag[personaTag]
+	if !ok {
+		return
+	}
+	i := lb.search(personaTag, oldScore)
+	lb.ranked = append(lb.ranked[:i], lb.ranked[i+1:]...)
+	delete(lb.byTag, personaTag)
+}
+
+// set moves the persona to the position of its new score, callers must hold the write lock
+func (lb *MemoryLeaderboard) set(personaTag string, score float64) {
+	if oldScore, ok := lb.byTag[personaTag]; ok {
+		i := lb.search(personaTag, oldScore)
+		lb.ranked = append(lb.ranked[:i], lb.ranked[i+1:]...)
+	}
+
+	i := lb.search(personaTag, score)
+	lb.ranked = append(lb.ranked, memoryEntry{})
+	copy(lb.ranked[i+1:], lb.ranked[i:])
+	lb.ranked[i] = memoryEntry{personaTag: personaTag, score: score}
+	lb.byTag[personaTag] = score
+}
+
+// search returns the index of the first entry that doesn't rank above the persona with the score
+func (lb *MemoryLeaderboard) search(personaTag string, score float64) int {
+	return sort.Search(len(lb.ranked), func(i int) bool {
+		entry := lb.ranked[i]
+		if entry.score != score {
+			return entry.score < score
+		}
+		return entry.personaTag <= personaTag
+	})
+}
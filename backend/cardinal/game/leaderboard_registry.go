@@ -0,0 +1,230 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"sync"
+)
+
+// Names of the leaderboards
+const (
+	// BoardScore ranks players by the score of the planets they own
+	BoardScore = "score"
+	// BoardScoreNebula, BoardScoreSafeSpace and BoardScoreDeepSpace rank players by the score of
+	// the planets they own in the space area, see SpaceAreaScoreBoards
+	BoardScoreNebula    = "score-nebula"
+	BoardScoreSafeSpace = "score-safe-space"
+	BoardScoreDeepSpace = "score-deep-space"
+	// BoardConquests ranks players by the number of planets they conquered
+	BoardConquests = "conquests"
+	// BoardEnergySent ranks players by the energy of the ships they sent
+	BoardEnergySent = "energy-sent"
+	// BoardAlliances ranks alliances by the score of the planets of their members
+	BoardAlliances = "alliances"
+)
+
+// LeaderboardNames are the names of all leaderboards of a registry
+var LeaderboardNames = []string{
+	BoardScore,
+	BoardScoreNebula,
+	BoardScoreSafeSpace,
+	BoardScoreDeepSpace,
+	BoardConquests,
+	BoardEnergySent,
+	BoardAlliances,
+}
+
+// SpaceAreaScoreBoards are the score boards of the space areas, in the order of SpaceConstants
+var SpaceAreaScoreBoards = [3]string{BoardScoreNebula, BoardScoreSafeSpace, BoardScoreDeepSpace}
+
+// ErrBoardNotFound is returned for a leaderboard name that isn't in LeaderboardNames
+var ErrBoardNotFound = errors.New("leaderboard not found")
+
+// ErrTickAlreadyCommitted is returned for a batch of a tick that is older than the last committed
+// tick of its game, which only happens if the world went back to an older tick of the game
+var ErrTickAlreadyCommitted = errors.New("a later tick of the game was already committed")
+
+// LeaderboardRegistry holds the leaderboards of a world by name, see LeaderboardNames
+type LeaderboardRegistry interface {
+	Board(name string) (Leaderboard, error)
+	// Commit applies all mutations of the batch to their boards or none of them. Batches of a game
+	// must be committed in tick order. Committing the batch of the last committed tick again does
+	// nothing, so a failed commit can be retried without applying the mutations twice, while the
+	// batch of an older tick is rejected with ErrTickAlreadyCommitted.
+	Commit(ctx context.Context, batch ScoreBatch) error
+}
+
+// LeaderboardKeyPrefix is the prefix of the Redis keys of the leaderboards of the instance, so
+// that instances sharing a Redis keep their own leaderboards
+func LeaderboardKeyPrefix(instanceName string) string {
+	if instanceName == "" {
+		return "leaderboard"
+	}
+	return instanceName + ":leaderboard"
+}
+
+// LegacyLeaderboardKey is the sorted set the score leaderboard was kept in before leaderboards
+// were namespaced by the instance name
+const LegacyLeaderboardKey = "leaderboardKey"
+
+// MigrateLegacyLeaderboard moves the score leaderboard from LegacyLeaderboardKey to the score
+// board of the instance and reports whether it did. The legacy leaderboard is left in place if
+// the instance already has a score board.
+func MigrateLegacyLeaderboard(ctx context.Context, client *redis.Client, instanceName string) (bool, error) {
+	exists, err := client.Exists(ctx, LegacyLeaderboardKey).Result()
+	if err != nil || exists == 0 {
+		return false, err
+	}
+	return client.RenameNX(ctx, LegacyLeaderboardKey, LeaderboardKeyPrefix(instanceName)+":"+BoardScore).Result()
+}
+
+// RedisLeaderboardRegistry keeps every leaderboard in a Redis sorted set of its own, its keys
+// are namespaced by the instance name the registry was created with
+type RedisLeaderboardRegistry struct {
+	client *redis.Client
+	prefix string
+	keys   map[string]string
+	boards map[string]*RedisLeaderboard
+}
+
+func NewRedisLeaderboardRegistry(client *redis.Client, instanceName string) *RedisLeaderboardRegistry {
+	prefix := LeaderboardKeyPrefix(instanceName)
+	r := &RedisLeaderboardRegistry{
+		client: client,
+		prefix: prefix,
+		keys:   make(map[string]string, len(LeaderboardNames)),
+		boards: make(map[string]*RedisLeaderboard, len(LeaderboardNames)),
+	}
+	for _, name := range LeaderboardNames {
+		key := prefix + ":" + name
+		r.keys[name] = key
+		r.boards[name] = NewRedisLeaderboard(client, key)
+	}
+	return r
+}
+
+func (r *RedisLeaderboardRegistry) Board(name string) (Leaderboard, error) {
+	board, ok := r.boards[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBoardNotFound, name)
+	}
+	return board, nil
+}
+
+// committedTickKey is the key holding the tick of the last committed batch of the game
+func (r *RedisLeaderboardRegistry) committedTickKey(game string) string {
+	if game == "" {
+		return r.prefix + ":committedTick"
+	}
+	return r.prefix + ":" + game + ":committedTick"
+}
+
+// Commit applies the batch in a MULTI transaction together with its tick, which is watched so
+// that concurrent commits of the same tick can't both apply
+func (r *RedisLeaderboardRegistry) Commit(ctx context.Context, batch ScoreBatch) error {
+	for _, mutation := range batch.Mutations {
+		if _, ok := r.keys[mutation.Board]; !ok {
+			return fmt.Errorf("%w: %s", ErrBoardNotFound, mutation.Board)
+		}
+	}
+
+	committedTickKey := r.committedTickKey(batch.Game)
+	return r.client.Watch(ctx, func(tx *redis.Tx) error {
+		committedTick, err := tx.Get(ctx, committedTickKey).Uint64()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return fmt.Errorf("failed to read the committed tick of the leaderboards: %w", err)
+		}
+		if err == nil {
+			if err = checkCommittedTick(batch, committedTick); err != nil || committedTick == batch.Tick {
+				return err
+			}
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, mutation := range batch.Mutations {
+				key := r.keys[mutation.Board]
+				switch mutation.Op {
+				case ScoreOpSet:
+					pipe.ZAdd(ctx, key, redis.Z{Score: float64(mutation.Amount), Member: mutation.Member})
+				case ScoreOpRemove:
+					pipe.ZRem(ctx, key, mutation.Member)
+				default:
+					pipe.ZIncrBy(ctx, key, float64(mutation.Amount), mutation.Member)
+				}
+			}
+			pipe.Set(ctx, committedTickKey, batch.Tick, 0)
+			return nil
+		})
+		return err
+	}, committedTickKey)
+}
+
+// checkCommittedTick rejects a batch of a tick older than committedTick, the last committed tick of its game
+func checkCommittedTick(batch ScoreBatch, committedTick uint64) error {
+	if committedTick > batch.Tick {
+		return fmt.Errorf("%w: batch of tick %d of game %q, committed up to tick %d",
+			ErrTickAlreadyCommitted, batch.Tick, batch.Game, committedTick)
+	}
+	return nil
+}
+
+// MemoryLeaderboardRegistry keeps the leaderboards in process memory, see MemoryLeaderboard
+type MemoryLeaderboardRegistry struct {
+	// Serializes commits, each board locks itself
+	mu     sync.Mutex
+	boards map[string]*MemoryLeaderboard
+	// Tick of the last committed batch by game, games without a committed batch are missing
+	committedTicks map[string]uint64
+}
+
+func NewMemoryLeaderboardRegistry() *MemoryLeaderboardRegistry {
+	r := &MemoryLeaderboardRegistry{
+		boards:         make(map[string]*MemoryLeaderboard, len(LeaderboardNames)),
+		committedTicks: make(map[string]uint64),
+	}
+	for _, name := range LeaderboardNames {
+		r.boards[name] = NewMemoryLeaderboard()
+	}
+	return r
+}
+
+func (r *MemoryLeaderboardRegistry) Board(name string) (Leaderboard, error) {
+	board, ok := r.boards[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBoardNotFound, name)
+	}
+	return board, nil
+}
+
+func (r *MemoryLeaderboardRegistry) Commit(_ context.Context, batch ScoreBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if committedTick, ok := r.committedTicks[batch.Game]; ok {
+		if err := checkCommittedTick(batch, committedTick); err != nil || committedTick == batch.Tick {
+			return err
+		}
+	}
+	for _, mutation := range batch.Mutations {
+		if _, ok := r.boards[mutation.Board]; !ok {
+			return fmt.Errorf("%w: %s", ErrBoardNotFound, mutation.Board)
+		}
+	}
+	for _, mutation := range batch.Mutations {
+		board := r.boards[mutation.Board]
+		board.mu.Lock()
+		switch mutation.Op {
+		case ScoreOpSet:
+			board.set(mutation.Member, float64(mutation.Amount))
+		case ScoreOpRemove:
+			board.remove(mutation.Member)
+		default:
+			board.set(mutation.Member, board.byTag[mutation.Member]+float64(mutation.Amount))
+		}
+		board.mu.Unlock()
+	}
+	r.committedTicks[batch.Game] = batch.Tick
+	return nil
+}
@@ -17,216 +17,492 @@ func setupMockRedis() (*miniredis.Miniredis, *redis.Client) {
 	return mr, client
 }
 
-func TestAddPlayerToLeaderboard(t *testing.T) {
-	ctx := context.TODO()
+// forEachLeaderboard runs the test against every Leaderboard implementation, each starting empty
+func forEachLeaderboard(t *testing.T, test func(t *testing.T, lb Leaderboard)) {
+	t.Run("redis", func(t *testing.T) {
+		mr, client := setupMockRedis()
+		defer mr.Close()
+		test(t, NewRedisLeaderboard(client, LeaderboardKeyPrefix("test")+":"+BoardScore))
+	})
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryLeaderboard())
+	})
+}
 
-	mr, client := setupMockRedis()
-	defer mr.Close()
-	LeaderboardClient = client
+func TestAddPlayerToLeaderboard(t *testing.T) {
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
 
-	player := Player{PersonaTag: "Alice", Score: 1000}
-	err := AddPlayerToLeaderboard(ctx, player)
+		player := Player{PersonaTag: "Alice", Score: 1000}
+		err := lb.AddPlayer(ctx, player)
 
-	assert.Nil(t, err, "Error adding player to leaderboard")
+		assert.Nil(t, err, "Error adding player to leaderboard")
 
-	rank, score, err := GetPlayerRankAndScore(ctx, "Alice")
-	assert.Nil(t, err, "Error getting player rank and score")
-	assert.Equal(t, int64(1), rank, "Rank mismatch")
-	assert.Equal(t, 1000.0, score, "Score mismatch")
+		rank, score, err := lb.GetPlayerRankAndScore(ctx, "Alice")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, int64(1), rank, "Rank mismatch")
+		assert.Equal(t, 1000.0, score, "Score mismatch")
+	})
 }
-func TestSetPlayerScore(t *testing.T) {
-	ctx := context.TODO()
-
-	mr, client := setupMockRedis()
-	defer mr.Close()
-	LeaderboardClient = client
-
-	// Adding "Alice" to the leaderboardKey
-	player := Player{PersonaTag: "Alice", Score: 1000}
-	err := AddPlayerToLeaderboard(ctx, player)
-	assert.Nil(t, err, "Error adding player to leaderboard")
 
-	// Update "Alice"'s score
-	err = SetScore(ctx, "Alice", 1200)
-	assert.Nil(t, err, "Error updating player score")
-
-	// Get "Alice"'s rank and score
-	rank, score, err := GetPlayerRankAndScore(ctx, "Alice")
-	assert.Nil(t, err, "Error getting player rank and score")
-	assert.Equal(t, int64(1), rank, "Rank mismatch")
-	assert.Equal(t, 1200.0, score, "Score mismatch")
+func TestSetPlayerScore(t *testing.T) {
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
+
+		// Adding "Alice" to the leaderboardKey
+		player := Player{PersonaTag: "Alice", Score: 1000}
+		err := lb.AddPlayer(ctx, player)
+		assert.Nil(t, err, "Error adding player to leaderboard")
+
+		// Update "Alice"'s score
+		err = lb.SetScore(ctx, "Alice", 1200)
+		assert.Nil(t, err, "Error updating player score")
+
+		// Get "Alice"'s rank and score
+		rank, score, err := lb.GetPlayerRankAndScore(ctx, "Alice")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, int64(1), rank, "Rank mismatch")
+		assert.Equal(t, 1200.0, score, "Score mismatch")
+	})
 }
 
 func TestGetPlayersInRankRange(t *testing.T) {
-	ctx := context.TODO()
-
-	mr, client := setupMockRedis()
-	defer mr.Close()
-	LeaderboardClient = client
-
-	// Adding players to the leaderboard
-	player1 := Player{PersonaTag: "Alice", Score: 1000}
-	err := AddPlayerToLeaderboard(ctx, player1)
-	assert.Nil(t, err, "Error adding player to leaderboard")
-
-	player2 := Player{PersonaTag: "Bob", Score: 750}
-	err = AddPlayerToLeaderboard(ctx, player2)
-	assert.Nil(t, err, "Error adding player to leaderboard")
-
-	player3 := Player{PersonaTag: "Charlie", Score: 1200}
-	err = AddPlayerToLeaderboard(ctx, player3)
-	assert.Nil(t, err, "Error adding player to leaderboard")
-
-	// Get players in rank range
-	players, err := GetPlayersInRankRange(ctx, 0, 2)
-	assert.Nil(t, err, "Error getting players in rank range")
-
-	expected := []RankedPlayer{
-		{
-			Player: Player{
-				PersonaTag: "Charlie",
-				Score:      1200,
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
+
+		// Adding players to the leaderboard
+		player1 := Player{PersonaTag: "Alice", Score: 1000}
+		err := lb.AddPlayer(ctx, player1)
+		assert.Nil(t, err, "Error adding player to leaderboard")
+
+		player2 := Player{PersonaTag: "Bob", Score: 750}
+		err = lb.AddPlayer(ctx, player2)
+		assert.Nil(t, err, "Error adding player to leaderboard")
+
+		player3 := Player{PersonaTag: "Charlie", Score: 1200}
+		err = lb.AddPlayer(ctx, player3)
+		assert.Nil(t, err, "Error adding player to leaderboard")
+
+		// Get players in rank range
+		players, err := lb.GetPlayersInRankRange(ctx, 0, 2)
+		assert.Nil(t, err, "Error getting players in rank range")
+
+		expected := []RankedPlayer{
+			{
+				Player: Player{
+					PersonaTag: "Charlie",
+					Score:      1200,
+				},
+				Rank: 1,
 			},
-			Rank: 1,
-		},
-		{
-			Player: Player{
-				PersonaTag: "Alice",
-				Score:      1000,
+			{
+				Player: Player{
+					PersonaTag: "Alice",
+					Score:      1000,
+				},
+				Rank: 2,
 			},
-			Rank: 2,
-		},
-		{
-			Player: Player{
-				PersonaTag: "Bob",
-				Score:      750,
+			{
+				Player: Player{
+					PersonaTag: "Bob",
+					Score:      750,
+				},
+				Rank: 3,
 			},
-			Rank: 3,
-		},
-	}
+		}
 
-	assert.ElementsMatch(t, expected, players, "Leaderboard mismatch")
+		assert.ElementsMatch(t, expected, players, "Leaderboard mismatch")
+	})
 }
 
 func TestIncrementScore(t *testing.T) {
-	ctx := context.TODO()
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
 
-	mr, client := setupMockRedis()
-	defer mr.Close()
-	LeaderboardClient = client
+		player := Player{PersonaTag: "Alice", Score: 1000}
+		err := lb.AddPlayer(ctx, player)
+		assert.Nil(t, err, "Error adding player to leaderboard")
 
-	player := Player{PersonaTag: "Alice", Score: 1000}
-	err := AddPlayerToLeaderboard(ctx, player)
-	assert.Nil(t, err, "Error adding player to leaderboard")
+		err = lb.IncrementScore(ctx, "Alice", 500)
+		assert.Nil(t, err, "Error incrementing player's score")
 
-	err = IncrementScore(ctx, "Alice", 500)
-	assert.Nil(t, err, "Error incrementing player's score")
-
-	rank, score, err := GetPlayerRankAndScore(ctx, "Alice")
-	assert.Nil(t, err, "Error getting player rank and score")
-	assert.Equal(t, int64(1), rank, "Rank mismatch")
-	assert.Equal(t, 1500.0, score, "Score mismatch")
+		rank, score, err := lb.GetPlayerRankAndScore(ctx, "Alice")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, int64(1), rank, "Rank mismatch")
+		assert.Equal(t, 1500.0, score, "Score mismatch")
+	})
 }
 
 func TestDecrementScore(t *testing.T) {
-	ctx := context.TODO()
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
 
-	mr, client := setupMockRedis()
-	defer mr.Close()
-	LeaderboardClient = client
+		player := Player{PersonaTag: "Alice", Score: 1000}
+		err := lb.AddPlayer(ctx, player)
+		assert.Nil(t, err, "Error adding player to leaderboard")
 
-	player := Player{PersonaTag: "Alice", Score: 1000}
-	err := AddPlayerToLeaderboard(ctx, player)
-	assert.Nil(t, err, "Error adding player to leaderboard")
+		err = lb.DecrementScore(ctx, "Alice", 500)
+		assert.Nil(t, err, "Error decrementing player's score")
 
-	err = DecrementScore(ctx, "Alice", 500)
-	assert.Nil(t, err, "Error decrementing player's score")
-
-	rank, score, err := GetPlayerRankAndScore(ctx, "Alice")
-	assert.Nil(t, err, "Error getting player rank and score")
-	assert.Equal(t, int64(1), rank, "Rank mismatch")
-	assert.Equal(t, 500.0, score, "Score mismatch")
+		rank, score, err := lb.GetPlayerRankAndScore(ctx, "Alice")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, int64(1), rank, "Rank mismatch")
+		assert.Equal(t, 500.0, score, "Score mismatch")
+	})
 }
 
 func TestGetPlayerRankAndScoreNotFound(t *testing.T) {
-	ctx := context.TODO()
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
 
-	mr, client := setupMockRedis()
-	defer mr.Close()
-	LeaderboardClient = client
-
-	_, _, err := GetPlayerRankAndScore(ctx, "NonExistentPlayer")
-	assert.Error(t, err, "Expected error for player not found")
-	assert.Contains(t, err.Error(), "not found in leaderboard", "Error message mismatch")
+		_, _, err := lb.GetPlayerRankAndScore(ctx, "NonExistentPlayer")
+		assert.Error(t, err, "Expected error for player not found")
+		assert.Contains(t, err.Error(), "not found in leaderboard", "Error message mismatch")
+	})
 }
 
 func TestSetScoreWithNegativeScore(t *testing.T) {
-	ctx := context.TODO()
-
-	mr, client := setupMockRedis()
-	defer mr.Close()
-	LeaderboardClient = client
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
 
-	player := Player{PersonaTag: "Alice", Score: 1000}
-	err := AddPlayerToLeaderboard(ctx, player)
-	assert.Nil(t, err, "Error adding player to leaderboard")
+		player := Player{PersonaTag: "Alice", Score: 1000}
+		err := lb.AddPlayer(ctx, player)
+		assert.Nil(t, err, "Error adding player to leaderboard")
 
-	err = SetScore(ctx, "Alice", -500)
-	assert.Nil(t, err, "Error setting negative score")
+		err = lb.SetScore(ctx, "Alice", -500)
+		assert.Nil(t, err, "Error setting negative score")
 
-	rank, score, err := GetPlayerRankAndScore(ctx, "Alice")
-	assert.Nil(t, err, "Error getting player rank and score")
-	assert.Equal(t, int64(1), rank, "Rank mismatch")
-	assert.Equal(t, -500.0, score, "Score mismatch")
+		rank, score, err := lb.GetPlayerRankAndScore(ctx, "Alice")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, int64(1), rank, "Rank mismatch")
+		assert.Equal(t, -500.0, score, "Score mismatch")
+	})
 }
 
 func TestGetPlayersInRankRangeWithOverlap(t *testing.T) {
-	ctx := context.TODO()
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
+
+		player1 := Player{PersonaTag: "Alice", Score: 1000}
+		err := lb.AddPlayer(ctx, player1)
+		assert.Nil(t, err, "Error adding player to leaderboard")
+
+		player2 := Player{PersonaTag: "Bob", Score: 1000}
+		err = lb.AddPlayer(ctx, player2)
+		assert.Nil(t, err, "Error adding player to leaderboard")
+
+		player3 := Player{PersonaTag: "Charlie", Score: 1200}
+		err = lb.AddPlayer(ctx, player3)
+		assert.Nil(t, err, "Error adding player to leaderboard")
+
+		players, err := lb.GetPlayersInRankRange(ctx, 0, 2)
+		assert.Nil(t, err, "Error getting players in rank range")
+
+		// Note: If two players are tied, the player with the alphabetically
+		// secondary PersonaTag will have the higher rank.
+		expected := []RankedPlayer{
+			{
+				Player: Player{
+					PersonaTag: "Charlie",
+					Score:      1200,
+				},
+				Rank: 1,
+			},
+			{
+				Player: Player{
+					PersonaTag: "Bob",
+					Score:      1000,
+				},
+				Rank: 2,
+			},
+			{
+				Player: Player{
+					PersonaTag: "Alice",
+					Score:      1000,
+				},
+				Rank: 3,
+			},
+		}
+
+		assert.ElementsMatch(t, expected, players, "Leaderboard mismatch")
+	})
+}
+
+func TestLeaderboardsRankAlike(t *testing.T) {
+	forEachLeaderboard(t, func(t *testing.T, lb Leaderboard) {
+		ctx := context.TODO()
+
+		for _, player := range []Player{{"Alice", 1000}, {"Bob", 1000}, {"Charlie", 1200}, {"Dave", 500}} {
+			err := lb.AddPlayer(ctx, player)
+			assert.Nil(t, err, "Error adding player to leaderboard")
+		}
+		err := lb.IncrementScore(ctx, "Dave", 600)
+		assert.Nil(t, err, "Error incrementing player's score")
+
+		// Ties are ranked by persona tag in reverse order, negative ranks count from the end
+		players, err := lb.GetPlayersInRankRange(ctx, -3, -1)
+		assert.Nil(t, err, "Error getting players in rank range")
+		tags := make([]string, 0, len(players))
+		for _, player := range players {
+			tags = append(tags, player.PersonaTag)
+		}
+		assert.Equal(t, []string{"Dave", "Bob", "Alice"}, tags)
+
+		players, err = lb.GetPlayersInRankRange(ctx, 3, 10)
+		assert.Nil(t, err, "Error getting players in rank range")
+		assert.Equal(t, 1, len(players))
+
+		rank, _, err := lb.GetPlayerRankAndScore(ctx, "Bob")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, int64(3), rank, "Rank mismatch")
+
+		_, _, err = lb.GetPlayerRankAndScore(ctx, "Eve")
+		assert.ErrorIs(t, err, ErrPlayerNotFound)
+		err = lb.SetScore(ctx, "Eve", 100)
+		assert.ErrorIs(t, err, ErrPlayerNotFound)
+	})
+}
+
+// forEachLeaderboardRegistry runs the test against every LeaderboardRegistry implementation, each starting empty
+func forEachLeaderboardRegistry(t *testing.T, test func(t *testing.T, r LeaderboardRegistry)) {
+	t.Run("redis", func(t *testing.T) {
+		mr, client := setupMockRedis()
+		defer mr.Close()
+		test(t, NewRedisLeaderboardRegistry(client, "test"))
+	})
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryLeaderboardRegistry())
+	})
+}
+
+func TestCommitIsIdempotent(t *testing.T) {
+	forEachLeaderboardRegistry(t, func(t *testing.T, r LeaderboardRegistry) {
+		ctx := context.TODO()
+		score, err := r.Board(BoardScore)
+		assert.Nil(t, err, "Error getting board")
+		alliances, err := r.Board(BoardAlliances)
+		assert.Nil(t, err, "Error getting board")
+
+		batch := ScoreBatch{Tick: 7, Mutations: []ScoreMutation{
+			ScoreSet(BoardScore, "Alice", 1000),
+			ScoreIncrement(BoardScore, "Alice", 200),
+			ScoreDecrement(BoardScore, "Bob", 50),
+			ScoreSet(BoardAlliances, "Andromeda", 1200),
+			ScoreSet(BoardAlliances, "Orion", 10),
+		}}
+		err = r.Commit(ctx, batch)
+		assert.Nil(t, err, "Error committing batch")
+
+		// Retrying the batch changes nothing, committing an older one fails
+		err = r.Commit(ctx, batch)
+		assert.Nil(t, err, "Error committing batch again")
+		err = r.Commit(ctx, ScoreBatch{Tick: 6, Mutations: []ScoreMutation{ScoreIncrement(BoardScore, "Alice", 1)}})
+		assert.ErrorIs(t, err, ErrTickAlreadyCommitted)
+
+		_, value, err := score.GetPlayerRankAndScore(ctx, "Alice")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, 1200.0, value, "Score mismatch")
+		_, value, err = score.GetPlayerRankAndScore(ctx, "Bob")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, -50.0, value, "Score mismatch")
+		_, value, err = alliances.GetPlayerRankAndScore(ctx, "Andromeda")
+		assert.Nil(t, err, "Error getting alliance rank and score")
+		assert.Equal(t, 1200.0, value, "Score mismatch")
+
+		err = r.Commit(ctx, ScoreBatch{Tick: 8, Mutations: []ScoreMutation{
+			ScoreIncrement(BoardScore, "Bob", 100),
+			ScoreRemove(BoardAlliances, "Orion"),
+		}})
+		assert.Nil(t, err, "Error committing the next batch")
+		_, value, err = score.GetPlayerRankAndScore(ctx, "Bob")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, 50.0, value, "Score mismatch")
+		_, _, err = alliances.GetPlayerRankAndScore(ctx, "Orion")
+		assert.ErrorIs(t, err, ErrPlayerNotFound)
+
+		// A batch with an unknown board isn't applied at all
+		err = r.Commit(ctx, ScoreBatch{Tick: 9, Mutations: []ScoreMutation{
+			ScoreIncrement(BoardScore, "Bob", 100),
+			ScoreIncrement("unknown", "Bob", 100),
+		}})
+		assert.ErrorIs(t, err, ErrBoardNotFound)
+		_, value, err = score.GetPlayerRankAndScore(ctx, "Bob")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, 50.0, value, "Score mismatch")
+		_, err = r.Board("unknown")
+		assert.ErrorIs(t, err, ErrBoardNotFound)
+	})
+}
 
+func TestCommitTicksAreKeptPerGame(t *testing.T) {
+	forEachLeaderboardRegistry(t, func(t *testing.T, r LeaderboardRegistry) {
+		ctx := context.TODO()
+		score, err := r.Board(BoardScore)
+		assert.Nil(t, err, "Error getting board")
+
+		// A world created before games had an ID committed up to tick 10
+		err = r.Commit(ctx, ScoreBatch{Tick: 10, Mutations: []ScoreMutation{ScoreSet(BoardScore, "Alice", 100)}})
+		assert.Nil(t, err, "Error committing batch")
+
+		// A lower tick of the same game is rejected instead of silently dropped
+		err = r.Commit(ctx, ScoreBatch{Tick: 3, Mutations: []ScoreMutation{ScoreIncrement(BoardScore, "Alice", 1)}})
+		assert.ErrorIs(t, err, ErrTickAlreadyCommitted)
+
+		// The world was reset and counts its ticks from 0 again as a new game
+		err = r.Commit(ctx, ScoreBatch{Game: "next", Tick: 3, Mutations: []ScoreMutation{ScoreIncrement(BoardScore, "Alice", 50)}})
+		assert.Nil(t, err, "Error committing the batch of the new game")
+		err = r.Commit(ctx, ScoreBatch{Game: "next", Tick: 4, Mutations: []ScoreMutation{ScoreIncrement(BoardConquests, "Alice", 1)}})
+		assert.Nil(t, err, "Error committing the batch of the new game")
+
+		_, value, err := score.GetPlayerRankAndScore(ctx, "Alice")
+		assert.Nil(t, err, "Error getting player rank and score")
+		assert.Equal(t, 150.0, value, "Score mismatch")
+	})
+}
+
+func TestRedisLeaderboardsAreNamespaced(t *testing.T) {
 	mr, client := setupMockRedis()
 	defer mr.Close()
-	LeaderboardClient = client
+	ctx := context.TODO()
 
-	player1 := Player{PersonaTag: "Alice", Score: 1000}
-	err := AddPlayerToLeaderboard(ctx, player1)
-	assert.Nil(t, err, "Error adding player to leaderboard")
+	// Two instances share the Redis, each committing its own ticks
+	first := NewRedisLeaderboardRegistry(client, "first")
+	second := NewRedisLeaderboardRegistry(client, "second")
+	err := first.Commit(ctx, ScoreBatch{Tick: 5, Mutations: []ScoreMutation{ScoreSet(BoardScore, "Alice", 100)}})
+	assert.Nil(t, err, "Error committing batch")
+	err = second.Commit(ctx, ScoreBatch{Tick: 3, Mutations: []ScoreMutation{ScoreSet(BoardScore, "Bob", 200)}})
+	assert.Nil(t, err, "Error committing batch")
+
+	board, err := first.Board(BoardScore)
+	assert.Nil(t, err, "Error getting board")
+	players, err := board.GetPlayersInRankRange(ctx, 0, -1)
+	assert.Nil(t, err, "Error getting players in rank range")
+	assert.Equal(t, []RankedPlayer{{Player: Player{PersonaTag: "Alice", Score: 100}, Rank: 1}}, players)
 
-	player2 := Player{PersonaTag: "Bob", Score: 1000}
-	err = AddPlayerToLeaderboard(ctx, player2)
-	assert.Nil(t, err, "Error adding player to leaderboard")
+	board, err = second.Board(BoardScore)
+	assert.Nil(t, err, "Error getting board")
+	players, err = board.GetPlayersInRankRange(ctx, 0, -1)
+	assert.Nil(t, err, "Error getting players in rank range")
+	assert.Equal(t, []RankedPlayer{{Player: Player{PersonaTag: "Bob", Score: 200}, Rank: 1}}, players)
+	assert.True(t, mr.Exists("first:leaderboard:score"))
+}
 
-	player3 := Player{PersonaTag: "Charlie", Score: 1200}
-	err = AddPlayerToLeaderboard(ctx, player3)
-	assert.Nil(t, err, "Error adding player to leaderboard")
+func TestMigrateLegacyLeaderboard(t *testing.T) {
+	mr, client := setupMockRedis()
+	defer mr.Close()
+	ctx := context.TODO()
 
-	players, err := GetPlayersInRankRange(ctx, 0, 2)
-	assert.Nil(t, err, "Error getting players in rank range")
+	// Nothing to migrate
+	migrated, err := MigrateLegacyLeaderboard(ctx, client, "test")
+	assert.Nil(t, err, "Error migrating the legacy leaderboard")
+	assert.False(t, migrated)
+
+	err = client.ZAdd(ctx, LegacyLeaderboardKey, redis.Z{Score: 100, Member: "Alice"}).Err()
+	assert.Nil(t, err, "Error adding player to the legacy leaderboard")
+	migrated, err = MigrateLegacyLeaderboard(ctx, client, "test")
+	assert.Nil(t, err, "Error migrating the legacy leaderboard")
+	assert.True(t, migrated)
+	assert.False(t, mr.Exists(LegacyLeaderboardKey))
+
+	board, err := NewRedisLeaderboardRegistry(client, "test").Board(BoardScore)
+	assert.Nil(t, err, "Error getting board")
+	_, value, err := board.GetPlayerRankAndScore(ctx, "Alice")
+	assert.Nil(t, err, "Error getting player rank and score")
+	assert.Equal(t, 100.0, value, "Score mismatch")
+
+	// An instance that has a score board of its own keeps it
+	err = client.ZAdd(ctx, LegacyLeaderboardKey, redis.Z{Score: 5, Member: "Bob"}).Err()
+	assert.Nil(t, err, "Error adding player to the legacy leaderboard")
+	migrated, err = MigrateLegacyLeaderboard(ctx, client, "test")
+	assert.Nil(t, err, "Error migrating the legacy leaderboard")
+	assert.False(t, migrated)
+	assert.True(t, mr.Exists(LegacyLeaderboardKey))
+}
 
-	// Note: If two players are tied, the player with the alphabetically
-	// secondary PersonaTag will have the higher rank.
-	expected := []RankedPlayer{
-		{
-			Player: Player{
-				PersonaTag: "Charlie",
-				Score:      1200,
-			},
-			Rank: 1,
-		},
-		{
-			Player: Player{
-				PersonaTag: "Bob",
-				Score:      1000,
-			},
-			Rank: 2,
-		},
-		{
-			Player: Player{
-				PersonaTag: "Alice",
-				Score:      1000,
-			},
-			Rank: 3,
-		},
-	}
+// forEachLeaderboardHistory runs the test against every LeaderboardHistory implementation, each starting empty
+func forEachLeaderboardHistory(t *testing.T, test func(t *testing.T, h LeaderboardHistory)) {
+	t.Run("redis", func(t *testing.T) {
+		mr, client := setupMockRedis()
+		defer mr.Close()
+		test(t, NewRedisLeaderboardHistory(client, "test"))
+	})
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryLeaderboardHistory())
+	})
+}
+
+func TestLeaderboardSnapshots(t *testing.T) {
+	forEachLeaderboardHistory(t, func(t *testing.T, h LeaderboardHistory) {
+		ctx := context.TODO()
+
+		err := h.SaveSnapshot(ctx, "game", 20, []Player{{PersonaTag: "Alice", Score: 300}, {PersonaTag: "Bob", Score: 500}, {PersonaTag: "Carol", Score: 500}})
+		assert.Nil(t, err, "Error saving snapshot")
+		err = h.SaveSnapshot(ctx, "game", 10, []Player{{PersonaTag: "Alice", Score: 100}})
+		assert.Nil(t, err, "Error saving snapshot")
+
+		ticks, err := h.SnapshotTicks(ctx, "game")
+		assert.Nil(t, err, "Error reading snapshot ticks")
+		assert.Equal(t, []uint64{10, 20}, ticks)
+
+		// Ties are ranked like the leaderboard, by persona tag in reverse order
+		top, err := h.GetSnapshotTop(ctx, "game", 20, 2)
+		assert.Nil(t, err, "Error reading snapshot")
+		assert.Equal(t, []RankedPlayer{
+			{Player: Player{PersonaTag: "Carol", Score: 500}, Rank: 1},
+			{Player: Player{PersonaTag: "Bob", Score: 500}, Rank: 2},
+		}, top)
+
+		timeline, err := h.GetPlayerTimeline(ctx, "game", "Alice")
+		assert.Nil(t, err, "Error reading timeline")
+		assert.Equal(t, []TimelinePoint{{Tick: 10, Rank: 1, Score: 100}, {Tick: 20, Rank: 3, Score: 300}}, timeline)
+		timeline, err = h.GetPlayerTimeline(ctx, "game", "Bob")
+		assert.Nil(t, err, "Error reading timeline")
+		assert.Equal(t, []TimelinePoint{{Tick: 20, Rank: 2, Score: 500}}, timeline)
+
+		// Saving a tick again replaces its snapshot
+		err = h.SaveSnapshot(ctx, "game", 20, []Player{{PersonaTag: "Alice", Score: 700}})
+		assert.Nil(t, err, "Error saving snapshot")
+		top, err = h.GetSnapshotTop(ctx, "game", 20, 10)
+		assert.Nil(t, err, "Error reading snapshot")
+		assert.Equal(t, []RankedPlayer{{Player: Player{PersonaTag: "Alice", Score: 700}, Rank: 1}}, top)
+
+		_, err = h.GetSnapshotTop(ctx, "game", 30, 10)
+		assert.ErrorIs(t, err, ErrSnapshotNotFound)
+	})
+}
 
-	assert.ElementsMatch(t, expected, players, "Leaderboard mismatch")
+func TestLeaderboardSnapshotsAreKeptPerGame(t *testing.T) {
+	forEachLeaderboardHistory(t, func(t *testing.T, h LeaderboardHistory) {
+		ctx := context.TODO()
+
+		err := h.SaveSnapshot(ctx, "", 10, []Player{{PersonaTag: "Alice", Score: 100}})
+		assert.Nil(t, err, "Error saving snapshot")
+		err = h.SaveSnapshot(ctx, "previous", 10, []Player{{PersonaTag: "Alice", Score: 200}})
+		assert.Nil(t, err, "Error saving snapshot")
+		err = h.SaveSnapshot(ctx, "previous", 20, []Player{{PersonaTag: "Alice", Score: 300}})
+		assert.Nil(t, err, "Error saving snapshot")
+
+		// The next game counts its ticks from 0 again without replacing the snapshots of the others
+		err = h.SaveSnapshot(ctx, "next", 10, []Player{{PersonaTag: "Bob", Score: 50}})
+		assert.Nil(t, err, "Error saving snapshot")
+
+		ticks, err := h.SnapshotTicks(ctx, "next")
+		assert.Nil(t, err, "Error reading snapshot ticks")
+		assert.Equal(t, []uint64{10}, ticks)
+		top, err := h.GetSnapshotTop(ctx, "next", 10, 10)
+		assert.Nil(t, err, "Error reading snapshot")
+		assert.Equal(t, []RankedPlayer{{Player: Player{PersonaTag: "Bob", Score: 50}, Rank: 1}}, top)
+		_, err = h.GetSnapshotTop(ctx, "next", 20, 10)
+		assert.ErrorIs(t, err, ErrSnapshotNotFound)
+
+		timeline, err := h.GetPlayerTimeline(ctx, "previous", "Alice")
+		assert.Nil(t, err, "Error reading timeline")
+		assert.Equal(t, []TimelinePoint{{Tick: 10, Rank: 1, Score: 200}, {Tick: 20, Rank: 1, Score: 300}}, timeline)
+		timeline, err = h.GetPlayerTimeline(ctx, "", "Alice")
+		assert.Nil(t, err, "Error reading timeline")
+		assert.Equal(t, []TimelinePoint{{Tick: 10, Rank: 1, Score: 100}}, timeline)
+		timeline, err = h.GetPlayerTimeline(ctx, "next", "Alice")
+		assert.Nil(t, err, "Error reading timeline")
+		assert.Empty(t, timeline)
+	})
 }
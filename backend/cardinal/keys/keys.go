@@ -2,25 +2,195 @@ package keys
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
 	"github.com/argus-labs/darkfrontier-backend/circuit/artifacts"
-	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 )
 
-var (
-	InitVerifyingKey = groth16.NewVerifyingKey(ecc.BN254)
-	MoveVerifyingKey = groth16.NewVerifyingKey(ecc.BN254)
-)
+// VerifyingKeys of one version of the circuit artifacts
+type VerifyingKeys struct {
+	ProofSystem proofsystem.ProofSystem
+	Init        proofsystem.VerifyingKey
+	Move        proofsystem.VerifyingKey
+	// nil for versions built before the reveal and rim-spawn init circuits existed
+	Reveal  proofsystem.VerifyingKey
+	InitRim proofsystem.VerifyingKey
+	// Seeds, MiMC rounds and perlin profile the circuits were compiled with
+	CircuitKeys prover.Keys
+}
+
+// registry maps artifact UUIDs to their VerifyingKeys
+var registry sync.Map
+
+var initVerifyingKeyFile = regexp.MustCompile(`^init-([0-9a-f-]{36})-vk$`)
 
 func init() {
-	initVkBytes := artifacts.InitVerifyingKey
-	_, err := InitVerifyingKey.ReadFrom(bytes.NewReader(initVkBytes))
+	ps, err := proofsystem.Get(artifacts.ProofSystem)
 	if err != nil {
 		panic(err)
 	}
-	moveVkBytes := artifacts.MoveVerifyingKey
-	_, err = MoveVerifyingKey.ReadFrom(bytes.NewReader(moveVkBytes))
+	vks, err := ReadVerifyingKeys(
+		ps,
+		artifacts.InitVerifyingKey,
+		artifacts.MoveVerifyingKey,
+		artifacts.RevealVerifyingKey,
+		artifacts.InitRimVerifyingKey,
+	)
 	if err != nil {
 		panic(err)
 	}
+	vks.CircuitKeys = artifacts.Keys
+	Register(artifacts.UUID, vks)
+}
+
+// Register adds or replaces the verifying keys of the artifact version uuid
+func Register(uuid string, vks VerifyingKeys) {
+	registry.Store(uuid, vks)
+}
+
+// Load returns the verifying keys of the artifact version uuid
+func Load(uuid string) (VerifyingKeys, bool) {
+	value, ok := registry.Load(uuid)
+	if !ok {
+		return VerifyingKeys{}, false
+	}
+
+	vks, ok := value.(VerifyingKeys)
+	if !ok {
+		return VerifyingKeys{}, false
+	}
+
+	return vks, true
+}
+
+// ReadVerifyingKeys parses serialized init, move, reveal and rim-spawn init verifying keys generated
+// for ps. revealVK and initRimVK may be empty, the version then can't verify those proofs.
+func ReadVerifyingKeys(ps proofsystem.ProofSystem, initVK, moveVK, revealVK, initRimVK []byte) (VerifyingKeys, error) {
+	vks := VerifyingKeys{
+		ProofSystem: ps,
+		Init:        ps.NewVerifyingKey(),
+		Move:        ps.NewVerifyingKey(),
+	}
+	if _, err := vks.Init.ReadFrom(bytes.NewReader(initVK)); err != nil {
+		return VerifyingKeys{}, fmt.Errorf("failed to read init verifying key: %w", err)
+	}
+	if _, err := vks.Move.ReadFrom(bytes.NewReader(moveVK)); err != nil {
+		return VerifyingKeys{}, fmt.Errorf("failed to read move verifying key: %w", err)
+	}
+	if len(revealVK) > 0 {
+		vks.Reveal = ps.NewVerifyingKey()
+		if _, err := vks.Reveal.ReadFrom(bytes.NewReader(revealVK)); err != nil {
+			return VerifyingKeys{}, fmt.Errorf("failed to read reveal verifying key: %w", err)
+		}
+	}
+	if len(initRimVK) > 0 {
+		vks.InitRim = ps.NewVerifyingKey()
+		if _, err := vks.InitRim.ReadFrom(bytes.NewReader(initRimVK)); err != nil {
+			return VerifyingKeys{}, fmt.Errorf("failed to read rim-spawn init verifying key: %w", err)
+		}
+	}
+	return vks, nil
+}
+
+// RegisterDir registers every init-<uuid>-vk/move-<uuid>-vk pair found in dir, as written
+// by circuit/cmd/build-artifacts, and returns the registered UUIDs. The proof system is read
+// from proofsystem-<uuid>, pairs without one are groth16. reveal-<uuid>-vk and init-rim-<uuid>-vk are
+// optional. The circuit keys are read from params-<uuid>, which is required.
+func RegisterDir(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var uuids []string
+	for _, entry := range entries {
+		match := initVerifyingKeyFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		uuid := match[1]
+
+		initVK, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		moveVK, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("move-%s-vk", uuid)))
+		if err != nil {
+			return nil, err
+		}
+		revealVK, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("reveal-%s-vk", uuid)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		initRimVK, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("init-rim-%s-vk", uuid)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		tag, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("proofsystem-%s", uuid)))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+		ps, err := proofsystem.Get(strings.TrimSpace(string(tag)))
+		if err != nil {
+			return nil, fmt.Errorf("artifact version %s: %w", uuid, err)
+		}
+		vks, err := ReadVerifyingKeys(ps, initVK, moveVK, revealVK, initRimVK)
+		if err != nil {
+			return nil, fmt.Errorf("artifact version %s: %w", uuid, err)
+		}
+		params, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("params-%s", uuid)))
+		if err != nil {
+			return nil, fmt.Errorf("artifact version %s: %w", uuid, err)
+		}
+		if err = json.Unmarshal(params, &vks.CircuitKeys); err != nil {
+			return nil, fmt.Errorf("artifact version %s: failed to read circuit keys: %w", uuid, err)
+		}
+
+		Register(uuid, vks)
+		uuids = append(uuids, uuid)
+	}
+	return uuids, nil
+}
+
+// WorldCircuitKeys are the circuit keys the world constants expect
+func WorldCircuitKeys(c game.WorldConstant) prover.Keys {
+	return prover.Keys{
+		PlanetHashKey: c.MiMCSeedWord,
+		SpaceTypeKey:  c.PerlinSeedWord,
+		MiMCNumRounds: c.MiMCNumRounds,
+		PerlinProfile: c.PerlinProfile(),
+	}
+}
+
+// CheckWorldConstants checks that the circuits of the version were compiled with the seeds, MiMC
+// rounds and perlin profile of the world constants
+func (vks VerifyingKeys) CheckWorldConstants(c game.WorldConstant) error {
+	if worldKeys := WorldCircuitKeys(c); !vks.CircuitKeys.Equal(worldKeys) {
+		return fmt.Errorf("circuits were compiled with %+v, the world constants are %+v", vks.CircuitKeys, worldKeys)
+	}
+	return nil
+}
+
+// CheckWorldConstants checks that the verifying keys of CircuitArtifactUUID and of every version in
+// AcceptedCircuitArtifactUUIDs are registered and were compiled for the world constants
+func CheckWorldConstants(c game.WorldConstant) error {
+	for _, uuid := range append([]string{c.CircuitArtifactUUID}, c.AcceptedCircuitArtifactUUIDs...) {
+		vks, ok := Load(uuid)
+		if !ok {
+			return fmt.Errorf("no verifying keys registered for circuit artifact version %s", uuid)
+		}
+		if err := vks.CheckWorldConstants(c); err != nil {
+			return fmt.Errorf("circuit artifact version %s: %w", uuid, err)
+		}
+	}
+	return nil
 }
@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"os"
 
 	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/keys"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/query"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/system"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
@@ -24,15 +26,67 @@ func main() {
 
 	utils.Must(utils.SetConstantsFromEnv())
 
+	// Register verifying keys of other circuit artifact versions, e.g. while rolling out new circuits
+	if dir := os.Getenv("CIRCUIT_ARTIFACTS_DIR"); dir != "" {
+		uuids, err := keys.RegisterDir(dir)
+		utils.Must(err)
+		log.Info().Msgf("Registered verifying keys for circuit artifact versions %v", uuids)
+	}
+
+	// The circuit keys and the terrain can't be changed at runtime, check them against the artifacts once
+	utils.Must(game.WorldConstants.CheckSpacePerlinThresholds())
+	utils.Must(keys.CheckWorldConstants(game.WorldConstants))
+
+	// The leaderboards and their history live in Redis, local sandboxes can keep them in memory with
+	// LEADERBOARD_BACKEND=memory. Their keys are namespaced by the instance name, so that
+	// instances can share a Redis.
+	var leaderboards game.LeaderboardRegistry
+	var history game.LeaderboardHistory
+	if mode != string(cardinal.RunModeProd) && os.Getenv("LEADERBOARD_BACKEND") == "memory" {
+		log.Warn().Msg("LEADERBOARD_BACKEND was set to memory, scores are lost when cardinal stops")
+		leaderboards = game.NewMemoryLeaderboardRegistry()
+		history = game.NewMemoryLeaderboardHistory()
+	} else {
+		client := redis.NewClient(&redis.Options{
+			Addr:     EnvRedisAddr,
+			Password: EnvRedisPassword,
+			DB:       0,
+		})
+		// Leaderboards of versions before the instance namespace were kept in a single sorted set
+		migrated, err := game.MigrateLegacyLeaderboard(context.Background(), client, game.WorldConstants.InstanceName)
+		utils.Must(err)
+		if migrated {
+			log.Info().Msgf("Migrated the legacy leaderboard %s to the instance %s", game.LegacyLeaderboardKey, game.WorldConstants.InstanceName)
+		}
+		leaderboards = game.NewRedisLeaderboardRegistry(client, game.WorldConstants.InstanceName)
+		history = game.NewRedisLeaderboardHistory(client, game.WorldConstants.InstanceName)
+	}
+	scoring := system.NewScoringSystems(leaderboards, history)
+	leaderboardQueries := query.NewLeaderboardQueries(leaderboards, history)
+
 	// Start world and register systems
 	var world *cardinal.World
 	if mode == string(cardinal.RunModeProd) {
 		world = utils.NewProdWorld(EnvRedisAddr, EnvRedisPassword)
 		utils.Must(cardinal.RegisterSystems(
 			world,
-			system.SendEnergySystem,
-			system.ClaimHomePlanetSystem,
-			system.ShipArriveSystem,
+			scoring.LeaderboardCommitSystem,
+			scoring.ScoreReconcileSystem,
+			scoring.LeaderboardSnapshotSystem,
+			system.VerifyProofsSystem,
+			scoring.SendEnergySystem,
+			scoring.ClaimHomePlanetSystem,
+			system.RevealLocationSystem,
+			system.RecallShipSystem,
+			system.UpgradePlanetSystem,
+			scoring.AbandonPlanetSystem,
+			scoring.TransferPlanetSystem,
+			scoring.CreateAllianceSystem,
+			system.InviteToAllianceSystem,
+			scoring.AcceptAllianceInviteSystem,
+			scoring.LeaveAllianceSystem,
+			scoring.KickFromAllianceSystem,
+			scoring.ShipArriveSystem,
 			system.SetConstantSystem,
 		))
 	} else {
@@ -40,10 +94,24 @@ func main() {
 		world = utils.NewDevWorld(EnvRedisAddr)
 		utils.Must(cardinal.RegisterSystems(
 			world,
-			system.SendEnergySystem,
-			system.ClaimHomePlanetSystem,
-			system.DebugClaimPlanetSystem,
-			system.ShipArriveSystem,
+			scoring.LeaderboardCommitSystem,
+			scoring.ScoreReconcileSystem,
+			scoring.LeaderboardSnapshotSystem,
+			system.VerifyProofsSystem,
+			scoring.SendEnergySystem,
+			scoring.ClaimHomePlanetSystem,
+			system.RevealLocationSystem,
+			system.RecallShipSystem,
+			system.UpgradePlanetSystem,
+			scoring.AbandonPlanetSystem,
+			scoring.TransferPlanetSystem,
+			scoring.CreateAllianceSystem,
+			system.InviteToAllianceSystem,
+			scoring.AcceptAllianceInviteSystem,
+			scoring.LeaveAllianceSystem,
+			scoring.KickFromAllianceSystem,
+			scoring.DebugClaimPlanetSystem,
+			scoring.ShipArriveSystem,
 			system.DebugEnergyBoostSystem,
 			system.SetConstantSystem,
 			system.MetricSystem,
@@ -57,6 +125,9 @@ func main() {
 	utils.Must(cardinal.RegisterComponent[component.PlanetComponent](world))
 	utils.Must(cardinal.RegisterComponent[component.ShipComponent](world))
 	utils.Must(cardinal.RegisterComponent[component.DefaultsComponent](world))
+	utils.Must(cardinal.RegisterComponent[component.ProofNullifierComponent](world))
+	utils.Must(cardinal.RegisterComponent[component.BattleReportComponent](world))
+	utils.Must(cardinal.RegisterComponent[component.AllianceComponent](world))
 
 	// Register transactions
 	// NOTE: You must register your transactions here,
@@ -65,6 +136,16 @@ func main() {
 		world,
 		tx.SendEnergy,
 		tx.ClaimHomePlanet,
+		tx.RevealLocation,
+		tx.RecallShip,
+		tx.UpgradePlanet,
+		tx.AbandonPlanet,
+		tx.TransferPlanet,
+		tx.CreateAlliance,
+		tx.InviteToAlliance,
+		tx.AcceptAllianceInvite,
+		tx.LeaveAlliance,
+		tx.KickFromAlliance,
 		tx.DebugClaimPlanet,
 		tx.DebugEnergyBoost,
 		tx.SetConstant,
@@ -73,16 +154,15 @@ func main() {
 	utils.Must(cardinal.RegisterQuery[query.ConstantMsg, query.ConstantReply](world, "constant", query.Constants))
 	utils.Must(cardinal.RegisterQuery[query.CurrentTickMsg, query.CurrentTickReply](world, "current-tick", query.CurrentTick))
 	utils.Must(cardinal.RegisterQuery[query.PlanetsMsg, query.PlanetsReply](world, "planets", query.Planets))
-	utils.Must(cardinal.RegisterQuery[query.PlayerRangeMsg, query.PlayerRangeReply](world, "player-range", query.PlayerRange))
-	utils.Must(cardinal.RegisterQuery[query.PlayerRankMsg, query.PlayerRankReply](world, "player-rank", query.PlayerRank))
-
-	options := &redis.Options{
-		Addr:     EnvRedisAddr,
-		Password: EnvRedisPassword,
-		DB:       0,
-	}
-
-	game.LeaderboardClient = redis.NewClient(options)
+	utils.Must(cardinal.RegisterQuery[query.PlayerRangeMsg, query.PlayerRangeReply](world, "player-range", leaderboardQueries.PlayerRange))
+	utils.Must(cardinal.RegisterQuery[query.PlayerRankMsg, query.PlayerRankReply](world, "player-rank", leaderboardQueries.PlayerRank))
+	utils.Must(cardinal.RegisterQuery[query.LeaderboardMsg, query.LeaderboardReply](world, "leaderboard", leaderboardQueries.Leaderboard))
+	utils.Must(cardinal.RegisterQuery[query.PlayerTimelineMsg, query.PlayerTimelineReply](world, "player-timeline", leaderboardQueries.PlayerTimeline))
+	utils.Must(cardinal.RegisterQuery[query.LeaderboardSnapshotMsg, query.LeaderboardSnapshotReply](world, "leaderboard-snapshot", leaderboardQueries.LeaderboardSnapshot))
+	utils.Must(cardinal.RegisterQuery[query.RevealedPlanetsMsg, query.RevealedPlanetsReply](world, "revealed-planets", query.RevealedPlanets))
+	utils.Must(cardinal.RegisterQuery[query.BattleReportsMsg, query.BattleReportsReply](world, "battle-reports", query.BattleReports))
+	utils.Must(cardinal.RegisterQuery[query.AllianceMsg, query.AllianceReply](world, "alliance", query.Alliance))
+	utils.Must(cardinal.RegisterQuery[query.AlliancePlanetsMsg, query.AlliancePlanetsReply](world, "alliance-planets", query.AlliancePlanets))
 
 	utils.Must(world.StartGame())
 }
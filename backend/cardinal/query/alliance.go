@@ -0,0 +1,87 @@
+package query
+
+import (
+	"fmt"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal"
+	"sort"
+)
+
+type AllianceMsg struct {
+	// Name of the alliance, if empty the alliance of PersonaTag is looked up
+	Name       string `json:"name"`
+	PersonaTag string `json:"personaTag"`
+}
+
+type AllianceReply struct {
+	Found    bool                        `json:"found"`
+	Alliance component.AllianceComponent `json:"alliance"`
+}
+
+// Alliance returns the roster of an alliance by its name or by one of its members
+func Alliance(_ cardinal.WorldContext, req *AllianceMsg) (*AllianceReply, error) {
+	allianceEntity, ok := loadAlliance(req.Name, req.PersonaTag)
+	if !ok {
+		return &AllianceReply{Found: false}, nil
+	}
+	return &AllianceReply{Found: true, Alliance: allianceEntity.Component}, nil
+}
+
+type AlliancePlanetsMsg struct {
+	// Name of the alliance, if empty the alliance of PersonaTag is looked up
+	Name       string `json:"name"`
+	PersonaTag string `json:"personaTag"`
+}
+
+type AlliancePlanetsReply struct {
+	Name    string       `json:"name"`
+	Planets []PlanetData `json:"planets"`
+}
+
+// AlliancePlanets returns the planets owned by the members of an alliance, ordered by location hash
+func AlliancePlanets(wCtx cardinal.WorldContext, req *AlliancePlanetsMsg) (*AlliancePlanetsReply, error) {
+	allianceEntity, ok := loadAlliance(req.Name, req.PersonaTag)
+	if !ok {
+		return nil, fmt.Errorf("no alliance found for name %q or persona %q", req.Name, req.PersonaTag)
+	}
+	alliance := allianceEntity.Component
+
+	members := make(map[string]bool, len(alliance.Members))
+	for _, member := range alliance.Members {
+		members[member] = true
+	}
+
+	planetsList := make([]string, 0)
+	component.PlanetIndex.Range(func(key, value interface{}) bool {
+		// Type assertion to get the actual types of key and value
+		_, ok1 := key.(string)
+		planetEntity, ok2 := value.(component.PlanetEntity)
+		if !ok1 || !ok2 {
+			wCtx.Logger().Info().Msg("Found incorrect type in key or value of PlanetIndex sync.Map")
+			return true
+		}
+		if members[planetEntity.Component.OwnerPersonaTag] {
+			planetsList = append(planetsList, planetEntity.Component.LocationHash)
+		}
+		return true
+	})
+
+	planets, err := Planets(wCtx, &PlanetsMsg{PlanetsList: planetsList})
+	if err != nil {
+		return nil, err
+	}
+
+	// sync.Map has no order, sort so that the reply is deterministic
+	sort.Slice(planets.Planets, func(i, j int) bool {
+		return planets.Planets[i].LocationHash < planets.Planets[j].LocationHash
+	})
+
+	return &AlliancePlanetsReply{Name: alliance.AllianceName, Planets: planets.Planets}, nil
+}
+
+func loadAlliance(name string, personaTag string) (component.AllianceEntity, bool) {
+	if name != "" {
+		return component.LoadAllianceComponent(name)
+	}
+	return component.LoadAllianceOf(personaTag)
+}
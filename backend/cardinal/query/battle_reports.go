@@ -0,0 +1,29 @@
+package query
+
+import (
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type BattleReportsMsg struct {
+	PlanetsList []string `json:"planetsList"`
+	// Only return battles fought at or after this tick
+	SinceTick int64 `json:"sinceTick"`
+}
+
+type BattleReportsReply struct {
+	Reports []component.BattleReportComponent `json:"reports"`
+}
+
+// BattleReports returns the battles fought at the requested planets, per planet in the requested order and oldest first
+func BattleReports(_ cardinal.WorldContext, req *BattleReportsMsg) (*BattleReportsReply, error) {
+	reports := make([]component.BattleReportComponent, 0)
+	for _, locationHash := range req.PlanetsList {
+		for _, report := range component.LoadBattleReports(locationHash) {
+			if report.Tick >= req.SinceTick {
+				reports = append(reports, report)
+			}
+		}
+	}
+	return &BattleReportsReply{reports}, nil
+}
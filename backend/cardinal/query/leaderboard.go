@@ -0,0 +1,83 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// LeaderboardQueries are the queries that read the leaderboards of their world and their history
+type LeaderboardQueries struct {
+	boards  game.LeaderboardRegistry
+	history game.LeaderboardHistory
+}
+
+func NewLeaderboardQueries(boards game.LeaderboardRegistry, history game.LeaderboardHistory) *LeaderboardQueries {
+	return &LeaderboardQueries{boards: boards, history: history}
+}
+
+type LeaderboardMsg struct {
+	// Name of the leaderboard, see game.LeaderboardNames
+	Board string `json:"board"`
+	// 0-based ranks of the members to return, both included
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	// Persona to look up, on game.BoardAlliances the alliance of the persona is looked up
+	PersonaTag string `json:"personaTag"`
+}
+
+type LeaderboardReply struct {
+	Board   string              `json:"board"`
+	Players []game.RankedPlayer `json:"players"`
+	// Rank and score of the looked up persona or alliance, nil if it isn't on the leaderboard
+	Player *game.RankedPlayer `json:"player"`
+	// Names of all leaderboards
+	Boards []string `json:"boards"`
+}
+
+func (q *LeaderboardQueries) Leaderboard(wCtx cardinal.WorldContext, req *LeaderboardMsg) (*LeaderboardReply, error) {
+	reply := &LeaderboardReply{Board: req.Board, Players: []game.RankedPlayer{}, Boards: game.LeaderboardNames}
+	leaderboard, err := q.boards.Board(req.Board)
+	if err != nil {
+		return reply, err
+	}
+	if req.Start < 0 || req.End < req.Start {
+		return reply, fmt.Errorf("invalid rank range [%d, %d]", req.Start, req.End)
+	}
+
+	players, err := leaderboard.GetPlayersInRankRange(context.Background(), req.Start, req.End)
+	if err != nil {
+		wCtx.Logger().Debug().Msgf("error reading range [%d, %d] of leaderboard %s %v", req.Start, req.End, req.Board, err)
+		return reply, err
+	}
+	// Ranks count from the top of the leaderboard, not from the start of the range
+	for i := range players {
+		players[i].Rank = int(req.Start) + i + 1
+	}
+	reply.Players = players
+
+	if req.PersonaTag == "" {
+		return reply, nil
+	}
+	member := req.PersonaTag
+	if req.Board == game.BoardAlliances {
+		allianceEntity, ok := comp.LoadAllianceOf(req.PersonaTag)
+		if !ok {
+			return reply, nil
+		}
+		member = allianceEntity.Component.AllianceName
+	}
+	rank, score, err := leaderboard.GetPlayerRankAndScore(context.Background(), member)
+	if err != nil {
+		if errors.Is(err, game.ErrPlayerNotFound) {
+			return reply, nil
+		}
+		wCtx.Logger().Debug().Msgf("error reading %s on leaderboard %s %v", member, req.Board, err)
+		return reply, err
+	}
+	reply.Player = &game.RankedPlayer{Player: game.Player{PersonaTag: member, Score: int(score)}, Rank: int(rank)}
+	return reply, nil
+}
@@ -0,0 +1,81 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type PlayerTimelineMsg struct {
+	PersonaTag string `json:"personaTag"`
+}
+
+type PlayerTimelineReply struct {
+	// Rank and score of the player in every leaderboard snapshot, oldest first
+	Timeline []game.TimelinePoint `json:"timeline"`
+}
+
+func (q *LeaderboardQueries) PlayerTimeline(wCtx cardinal.WorldContext, req *PlayerTimelineMsg) (*PlayerTimelineReply, error) {
+	timeline, err := q.history.GetPlayerTimeline(context.Background(), currentGameID(wCtx), req.PersonaTag)
+	if err != nil {
+		wCtx.Logger().Debug().Msgf("error reading timeline of player %s %v", req.PersonaTag, err)
+		return &PlayerTimelineReply{}, err
+	}
+
+	return &PlayerTimelineReply{Timeline: timeline}, nil
+}
+
+type LeaderboardSnapshotMsg struct {
+	// Tick of the snapshot, 0 for the latest one
+	Tick uint64 `json:"tick"`
+	// Number of players to return, starting with the highest ranked
+	Top int64 `json:"top"`
+}
+
+type LeaderboardSnapshotReply struct {
+	Tick    uint64              `json:"tick"`
+	Players []game.RankedPlayer `json:"players"`
+	// Ticks of all snapshots, oldest first
+	Ticks []uint64 `json:"ticks"`
+}
+
+func (q *LeaderboardQueries) LeaderboardSnapshot(wCtx cardinal.WorldContext, req *LeaderboardSnapshotMsg) (*LeaderboardSnapshotReply, error) {
+	if req.Top <= 0 {
+		return &LeaderboardSnapshotReply{}, fmt.Errorf("top must be a positive number of players, got %d", req.Top)
+	}
+
+	gameID := currentGameID(wCtx)
+	ticks, err := q.history.SnapshotTicks(context.Background(), gameID)
+	if err != nil {
+		wCtx.Logger().Debug().Msgf("error reading leaderboard snapshot ticks %v", err)
+		return &LeaderboardSnapshotReply{}, err
+	}
+	tick := req.Tick
+	if tick == 0 {
+		if len(ticks) == 0 {
+			return &LeaderboardSnapshotReply{}, errors.New("no leaderboard snapshot was taken yet")
+		}
+		tick = ticks[len(ticks)-1]
+	}
+
+	players, err := q.history.GetSnapshotTop(context.Background(), gameID, tick, req.Top)
+	if err != nil {
+		wCtx.Logger().Debug().Msgf("error reading top %d of leaderboard snapshot at tick %d %v", req.Top, tick, err)
+		return &LeaderboardSnapshotReply{}, err
+	}
+
+	return &LeaderboardSnapshotReply{Tick: tick, Players: players, Ticks: ticks}, nil
+}
+
+// currentGameID returns the game the history is read for, worlds without defaults read the
+// snapshots saved without a game ID like LeaderboardSnapshotSystem saves them
+func currentGameID(wCtx cardinal.WorldContext) string {
+	gameID, err := comp.GetGameID(wCtx)
+	if err != nil {
+		wCtx.Logger().Debug().Msgf("error reading the game ID %v", err)
+	}
+	return gameID
+}
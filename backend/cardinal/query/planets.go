@@ -14,6 +14,8 @@ type EnergyTransfer struct {
 	EnergyOnEmbark      string `json:"energyOnEmbark"`
 	OwnerPersonaTag     string `json:"ownerPersonaTag"`
 	TravelTimeInSeconds int64  `json:"travelTimeInSeconds"`
+	// Recalled ships travel back from PlanetFromHash, the planet they were sent to, to PlanetToHash
+	Recalled bool `json:"recalled"`
 }
 
 type PlanetData struct {
@@ -29,6 +31,9 @@ type PlanetData struct {
 	LastUpdateRefillAge string           `json:"lastUpdateRefillAge"`
 	LastUpdateTick      string           `json:"lastUpdateTick"`
 	EnergyTransfers     []EnergyTransfer `json:"energyTransfers"`
+	RangeRank           int64            `json:"rangeRank"`
+	SpeedRank           int64            `json:"speedRank"`
+	DefenseRank         int64            `json:"defenseRank"`
 }
 
 type PlanetsMsg struct {
@@ -71,6 +76,7 @@ func Planets(wCtx cardinal.WorldContext, req *PlanetsMsg) (*PlanetsReply, error)
 				EnergyOnEmbark:      utils.DecToStr(ship.EnergyOnEmbark),
 				OwnerPersonaTag:     ship.OwnerPersonaTag,
 				TravelTimeInSeconds: utils.ScaleDownByTickRateInt(ship.TickArrive - ship.TickStart),
+				Recalled:            ship.Recalled,
 			}
 			energyTransferLookup[ship.LocationHashTo] = append(energyTransferLookup[ship.LocationHashTo], energyTransfer)
 		}
@@ -83,6 +89,7 @@ func Planets(wCtx cardinal.WorldContext, req *PlanetsMsg) (*PlanetsReply, error)
 				EnergyOnEmbark:      utils.DecToStr(ship.EnergyOnEmbark),
 				OwnerPersonaTag:     ship.OwnerPersonaTag,
 				TravelTimeInSeconds: utils.ScaleDownByTickRateInt(ship.TickArrive - ship.TickStart),
+				Recalled:            ship.Recalled,
 			}
 			energyTransferLookup[ship.LocationHashFrom] = append(energyTransferLookup[ship.LocationHashFrom], energyTransfer)
 		}
@@ -113,6 +120,9 @@ func Planets(wCtx cardinal.WorldContext, req *PlanetsMsg) (*PlanetsReply, error)
 				LastUpdateRefillAge: utils.DecToStr(planetComp.LastUpdateRefillAge),
 				LastUpdateTick:      utils.DecToStr(planetComp.LastUpdateTick),
 				EnergyTransfers:     energyTransferLookup[planetComp.LocationHash],
+				RangeRank:           planetComp.RangeRank,
+				SpeedRank:           planetComp.SpeedRank,
+				DefenseRank:         planetComp.DefenseRank,
 			}
 			foundPlanets = append(foundPlanets, planetData)
 		}
@@ -15,8 +15,12 @@ type PlayerRangeReply struct {
 	Players []game.RankedPlayer `json:"players"`
 }
 
-func PlayerRange(wCtx cardinal.WorldContext, req *PlayerRangeMsg) (*PlayerRangeReply, error) {
-	players, err := game.GetPlayersInRankRange(context.Background(), req.Start, req.End)
+func (q *LeaderboardQueries) PlayerRange(wCtx cardinal.WorldContext, req *PlayerRangeMsg) (*PlayerRangeReply, error) {
+	leaderboard, err := q.boards.Board(game.BoardScore)
+	if err != nil {
+		return &PlayerRangeReply{}, err
+	}
+	players, err := leaderboard.GetPlayersInRankRange(context.Background(), req.Start, req.End)
 	if err != nil {
 		wCtx.Logger().Debug().Msgf("error reading player range [%d, %d] %v", req.Start, req.End, err)
 		return &PlayerRangeReply{}, err
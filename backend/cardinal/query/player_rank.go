@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
-	"github.com/redis/go-redis/v9"
 	"pkg.world.dev/world-engine/cardinal"
 )
 
@@ -17,10 +16,14 @@ type PlayerRankReply struct {
 	Score float64 `json:"score"`
 }
 
-func PlayerRank(wCtx cardinal.WorldContext, req *PlayerRankMsg) (*PlayerRankReply, error) {
-	rank, score, err := game.GetPlayerRankAndScore(context.Background(), req.PersonaTag)
+func (q *LeaderboardQueries) PlayerRank(wCtx cardinal.WorldContext, req *PlayerRankMsg) (*PlayerRankReply, error) {
+	leaderboard, err := q.boards.Board(game.BoardScore)
 	if err != nil {
-		if !errors.Is(err, redis.Nil) {
+		return &PlayerRankReply{}, err
+	}
+	rank, score, err := leaderboard.GetPlayerRankAndScore(context.Background(), req.PersonaTag)
+	if err != nil {
+		if !errors.Is(err, game.ErrPlayerNotFound) {
 			wCtx.Logger().Warn().Msgf("error reading player rank %v", err)
 		}
 		return &PlayerRankReply{Rank: 99999, Score: 0}, nil
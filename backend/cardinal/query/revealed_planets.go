@@ -0,0 +1,62 @@
+package query
+
+import (
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal"
+	"sort"
+)
+
+type RevealedPlanet struct {
+	LocationHash    string `json:"locationHash"`
+	X               int64  `json:"x"`
+	Y               int64  `json:"y"`
+	Level           int64  `json:"level"`
+	OwnerPersonaTag string `json:"ownerPersonaTag"`
+	RevealedBy      string `json:"revealedBy"`
+	RevealedTick    int64  `json:"revealedTick"`
+}
+
+type RevealedPlanetsMsg struct{}
+
+type RevealedPlanetsReply struct {
+	Planets []RevealedPlanet `json:"planets"`
+}
+
+// RevealedPlanets lists every planet whose coordinates were revealed, in the order they were revealed
+func RevealedPlanets(wCtx cardinal.WorldContext, _ *RevealedPlanetsMsg) (*RevealedPlanetsReply, error) {
+	revealedPlanets := make([]RevealedPlanet, 0)
+
+	component.PlanetIndex.Range(func(key, value interface{}) bool {
+		// Type assertion to get the actual types of key and value
+		_, ok1 := key.(string)
+		planetEntity, ok2 := value.(component.PlanetEntity)
+		if !ok1 || !ok2 {
+			wCtx.Logger().Info().Msg("Found incorrect type in key or value of PlanetIndex sync.Map")
+			return true
+		}
+		planetComp := planetEntity.Component
+		if !planetComp.Revealed {
+			return true
+		}
+		revealedPlanets = append(revealedPlanets, RevealedPlanet{
+			LocationHash:    planetComp.LocationHash,
+			X:               planetComp.RevealedX,
+			Y:               planetComp.RevealedY,
+			Level:           planetComp.Level,
+			OwnerPersonaTag: planetComp.OwnerPersonaTag,
+			RevealedBy:      planetComp.RevealedBy,
+			RevealedTick:    planetComp.RevealedTick,
+		})
+		return true
+	})
+
+	// sync.Map has no order, sort so that the reply is deterministic
+	sort.Slice(revealedPlanets, func(i, j int) bool {
+		if revealedPlanets[i].RevealedTick != revealedPlanets[j].RevealedTick {
+			return revealedPlanets[i].RevealedTick < revealedPlanets[j].RevealedTick
+		}
+		return revealedPlanets[i].LocationHash < revealedPlanets[j].LocationHash
+	})
+
+	return &RevealedPlanetsReply{revealedPlanets}, nil
+}
@@ -0,0 +1,117 @@
+package system
+
+import (
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/ericlagergren/decimal"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// AbandonPlanetSystem gives up ownership of a planet. The planet loses
+// game.WorldConstants.AbandonPenalty of its energy and its score is taken from the player.
+// A player cannot abandon their last planet.
+func (s *ScoringSystems) AbandonPlanetSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each abandon planet transaction
+	tx.AbandonPlanet.Each(wCtx, func(t cardinal.TxData[tx.AbandonPlanetMsg]) (result tx.AbandonPlanetReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to abandon planet: %s", txData.LocationHash)
+
+		// 1. PRE-CONDITION: Check that the LocationHash is well formatted
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Check that a planet exists at the location hash
+		planetEntity, ok := comp.LoadPlanetComponent(txData.LocationHash)
+		if !ok {
+			err = fmt.Errorf("no planet exists at the following location hash %s", txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		planet := planetEntity.Component
+
+		// 2b. PRE-CONDITION: Verify that the planet is owned by the player
+		if planet.OwnerPersonaTag != txSig.PersonaTag {
+			err = fmt.Errorf("player with persona %s does not own planet with location hash %s", txSig.PersonaTag, txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. PRE-CONDITION: Verify that it is not the last planet of the player
+		if countOwnedPlanets(wCtx, txSig.PersonaTag) <= 1 {
+			err = fmt.Errorf("player with persona %s cannot abandon their last planet", txSig.PersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		score, err := planetScore(planet)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// Lazy energy refill, the planet no longer refills once it has no owner
+		log.Debug().Msgf("Applying lazy energy refill to planet: %s", planet.LocationHash)
+		normalizedRefillAge := utils.NormalizedRefillAge(planet.LastUpdateRefillAge, planet.LastUpdateTick, utils.IntToDec(int(wCtx.CurrentTick())), utils.ScaleUpByTickRate(planet.EnergyRefill))
+		planet.EnergyCurrent = utils.EnergyLevel(planet.EnergyMax, normalizedRefillAge)
+		planet.LastUpdateTick = utils.IntToDec(int(wCtx.CurrentTick()))
+
+		// 2d. POST-CONDITION: The planet has no owner and loses part of its energy
+		planet.OwnerPersonaTag = ""
+		penalty := utils.StrToDec(game.WorldConstants.AbandonPenalty)
+		if penalty.Sign() > 0 {
+			energyLost := new(decimal.Big).Mul(planet.EnergyCurrent, penalty)
+			planet.EnergyCurrent = new(decimal.Big).Sub(planet.EnergyCurrent, energyLost)
+		}
+		planet.LastUpdateRefillAge = utils.InvEnergyCurve(utils.InvEnergyCurve(utils.Saturate(new(decimal.Big).Quo(planet.EnergyCurrent, planet.EnergyMax))))
+		err = planet.Set(wCtx, planetEntity.EntityId)
+		if err != nil {
+			err = fmt.Errorf("failed to set abandoned planet with location hash %s, error: %w", txData.LocationHash, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2e. POST-CONDITION: The player loses the score of the planet
+		s.stage(wCtx, planetScoreMutations(txSig.PersonaTag, planet, -score)...)
+
+		log.Debug().Msgf("Persona %s abandoned planet %s, energy left: %s", txSig.PersonaTag, txData.LocationHash, utils.DecToStr(planet.EnergyCurrent))
+
+		result.AbandonedPlanet = convertPlanetCompToReceipt(planet)
+		result.ScoreRemoved = score
+		return result, nil
+	})
+
+	return nil
+}
+
+// countOwnedPlanets returns the number of planets owned by the persona
+func countOwnedPlanets(wCtx cardinal.WorldContext, personaTag string) int {
+	count := 0
+	comp.PlanetIndex.Range(func(key, value interface{}) bool {
+		planetEntity, ok := value.(comp.PlanetEntity)
+		if !ok {
+			wCtx.Logger().Info().Msg("Found incorrect type in value of PlanetIndex sync.Map")
+			return true
+		}
+		if planetEntity.Component.OwnerPersonaTag == personaTag {
+			count++
+		}
+		return true
+	})
+	return count
+}
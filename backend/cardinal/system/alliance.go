@@ -0,0 +1,398 @@
+package system
+
+import (
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"pkg.world.dev/world-engine/cardinal"
+	"slices"
+)
+
+// CreateAllianceSystem founds an alliance led by the sender. A player can be a member of only one alliance.
+func (s *ScoringSystems) CreateAllianceSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each create alliance transaction
+	tx.CreateAlliance.Each(wCtx, func(t cardinal.TxData[tx.CreateAllianceMsg]) (result tx.AllianceReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to create alliance: %s", txData.Name)
+
+		// 1. PRE-CONDITION: Check that the name is well formatted
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Verify that the sender is a player outside of any alliance
+		if err = checkAllianceCandidate(txSig.PersonaTag); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2b. PRE-CONDITION: Verify that the name is not taken
+		if _, ok := comp.LoadAllianceComponent(txData.Name); ok {
+			err = fmt.Errorf("alliance %s already exists", txData.Name)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		score, err := personaPlanetScore(wCtx, txSig.PersonaTag)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. POST-CONDITION: The alliance exists with the sender as its leader and only member
+		allianceId, err := cardinal.Create(wCtx, comp.AllianceComponent{})
+		if err != nil {
+			err = fmt.Errorf("failed to create alliance %s: %w", txData.Name, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		alliance := comp.AllianceComponent{
+			AllianceName: txData.Name,
+			Leader:       txSig.PersonaTag,
+			Members:      []string{txSig.PersonaTag},
+			Invites:      []string{},
+			CreatedTick:  int64(wCtx.CurrentTick()),
+		}
+		err = alliance.Set(wCtx, allianceId)
+		if err != nil {
+			err = fmt.Errorf("failed to set alliance %s: %w", txData.Name, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2d. POST-CONDITION: The alliance ranks with the score of its leader
+		s.stage(wCtx, game.ScoreSet(game.BoardAlliances, alliance.AllianceName, score))
+
+		log.Debug().Msgf("Persona %s created alliance %s", txSig.PersonaTag, txData.Name)
+
+		result.Alliance = alliance
+		return result, nil
+	})
+
+	return nil
+}
+
+// InviteToAllianceSystem lets the leader of an alliance invite a player, who joins by accepting the invite
+func InviteToAllianceSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each invite to alliance transaction
+	tx.InviteToAlliance.Each(wCtx, func(t cardinal.TxData[tx.InviteToAllianceMsg]) (result tx.AllianceReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to invite %s to an alliance", txData.PersonaTag)
+
+		// 1. PRE-CONDITION: Check that there is a persona to invite
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Verify that the sender leads an alliance
+		allianceEntity, err := loadLedAlliance(txSig.PersonaTag)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		alliance := allianceEntity.Component
+
+		// 2b. PRE-CONDITION: Verify that the invitee is a player that is neither a member nor invited yet
+		if _, ok := comp.LoadPlayerComponent(txData.PersonaTag); !ok {
+			err = fmt.Errorf("no player exists with persona %s", txData.PersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if slices.Contains(alliance.Members, txData.PersonaTag) {
+			err = fmt.Errorf("player with persona %s is already a member of alliance %s", txData.PersonaTag, alliance.AllianceName)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if slices.Contains(alliance.Invites, txData.PersonaTag) {
+			err = fmt.Errorf("player with persona %s is already invited to alliance %s", txData.PersonaTag, alliance.AllianceName)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. POST-CONDITION: The invitee is on the invite list
+		alliance.Invites = withPersona(alliance.Invites, txData.PersonaTag)
+		err = alliance.Set(wCtx, allianceEntity.EntityId)
+		if err != nil {
+			err = fmt.Errorf("failed to set alliance %s: %w", alliance.AllianceName, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		log.Debug().Msgf("Persona %s invited %s to alliance %s", txSig.PersonaTag, txData.PersonaTag, alliance.AllianceName)
+
+		result.Alliance = alliance
+		return result, nil
+	})
+
+	return nil
+}
+
+// AcceptAllianceInviteSystem makes the sender a member of an alliance that invited them
+func (s *ScoringSystems) AcceptAllianceInviteSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each accept alliance invite transaction
+	tx.AcceptAllianceInvite.Each(wCtx, func(t cardinal.TxData[tx.AcceptAllianceInviteMsg]) (result tx.AllianceReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to accept the invite of alliance %s", txData.Name)
+
+		// 1. PRE-CONDITION: Check that the name is well formatted
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Verify that the alliance exists and invited the sender
+		allianceEntity, ok := comp.LoadAllianceComponent(txData.Name)
+		if !ok {
+			err = fmt.Errorf("alliance %s does not exist", txData.Name)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		alliance := allianceEntity.Component
+		if !slices.Contains(alliance.Invites, txSig.PersonaTag) {
+			err = fmt.Errorf("player with persona %s is not invited to alliance %s", txSig.PersonaTag, alliance.AllianceName)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2b. PRE-CONDITION: Verify that the sender is a player outside of any alliance
+		if err = checkAllianceCandidate(txSig.PersonaTag); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		score, err := personaPlanetScore(wCtx, txSig.PersonaTag)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. POST-CONDITION: The sender is a member instead of an invitee
+		alliance.Invites = withoutPersona(alliance.Invites, txSig.PersonaTag)
+		alliance.Members = withPersona(alliance.Members, txSig.PersonaTag)
+		err = alliance.Set(wCtx, allianceEntity.EntityId)
+		if err != nil {
+			err = fmt.Errorf("failed to set alliance %s: %w", alliance.AllianceName, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2d. POST-CONDITION: The score of the sender counts towards the alliance
+		s.stage(wCtx, game.ScoreIncrement(game.BoardAlliances, alliance.AllianceName, score))
+
+		log.Debug().Msgf("Persona %s joined alliance %s", txSig.PersonaTag, alliance.AllianceName)
+
+		result.Alliance = alliance
+		return result, nil
+	})
+
+	return nil
+}
+
+// LeaveAllianceSystem removes the sender from their alliance. If the leader leaves, the longest
+// standing member leads the alliance. The alliance is disbanded when its last member leaves.
+func (s *ScoringSystems) LeaveAllianceSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each leave alliance transaction
+	tx.LeaveAlliance.Each(wCtx, func(t cardinal.TxData[tx.LeaveAllianceMsg]) (result tx.AllianceReply, err error) {
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload from %s to leave their alliance", txSig.PersonaTag)
+
+		// 2a. PRE-CONDITION: Verify that the sender is a member of an alliance
+		allianceEntity, ok := comp.LoadAllianceOf(txSig.PersonaTag)
+		if !ok {
+			err = fmt.Errorf("player with persona %s is not a member of any alliance", txSig.PersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2b. POST-CONDITION: The sender is no longer a member and takes its score along
+		alliance, err := s.removeAllianceMember(wCtx, allianceEntity, txSig.PersonaTag)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		log.Debug().Msgf("Persona %s left alliance %s", txSig.PersonaTag, alliance.AllianceName)
+
+		result.Alliance = alliance
+		return result, nil
+	})
+
+	return nil
+}
+
+// KickFromAllianceSystem lets the leader of an alliance remove one of its other members
+func (s *ScoringSystems) KickFromAllianceSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each kick from alliance transaction
+	tx.KickFromAlliance.Each(wCtx, func(t cardinal.TxData[tx.KickFromAllianceMsg]) (result tx.AllianceReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to kick %s from an alliance", txData.PersonaTag)
+
+		// 1. PRE-CONDITION: Check that there is a persona to kick
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Verify that the sender leads an alliance
+		allianceEntity, err := loadLedAlliance(txSig.PersonaTag)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2b. PRE-CONDITION: Verify that the persona is another member of the alliance
+		if txData.PersonaTag == txSig.PersonaTag {
+			err = fmt.Errorf("player with persona %s cannot kick themselves, leave the alliance instead", txSig.PersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if !slices.Contains(allianceEntity.Component.Members, txData.PersonaTag) {
+			err = fmt.Errorf("player with persona %s is not a member of alliance %s", txData.PersonaTag, allianceEntity.Component.AllianceName)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. POST-CONDITION: The persona is no longer a member and takes its score along
+		alliance, err := s.removeAllianceMember(wCtx, allianceEntity, txData.PersonaTag)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		log.Debug().Msgf("Persona %s kicked %s from alliance %s", txSig.PersonaTag, txData.PersonaTag, alliance.AllianceName)
+
+		result.Alliance = alliance
+		return result, nil
+	})
+
+	return nil
+}
+
+// checkAllianceCandidate returns an error unless the persona is a player that is not a member of any alliance
+func checkAllianceCandidate(personaTag string) error {
+	if _, ok := comp.LoadPlayerComponent(personaTag); !ok {
+		return fmt.Errorf("no player exists with persona %s", personaTag)
+	}
+	if alliance, ok := comp.LoadAllianceOf(personaTag); ok {
+		return fmt.Errorf("player with persona %s is already a member of alliance %s", personaTag, alliance.Component.AllianceName)
+	}
+	return nil
+}
+
+// loadLedAlliance returns the alliance the persona is the leader of
+func loadLedAlliance(personaTag string) (comp.AllianceEntity, error) {
+	allianceEntity, ok := comp.LoadAllianceOf(personaTag)
+	if !ok {
+		return allianceEntity, fmt.Errorf("player with persona %s is not a member of any alliance", personaTag)
+	}
+	if allianceEntity.Component.Leader != personaTag {
+		return allianceEntity, fmt.Errorf("player with persona %s is not the leader of alliance %s", personaTag, allianceEntity.Component.AllianceName)
+	}
+	return allianceEntity, nil
+}
+
+// removeAllianceMember removes the member and its score from the alliance, passing the lead on
+// to the longest standing member, and removes the alliance from the world and its leaderboard
+// once it has no members
+func (s *ScoringSystems) removeAllianceMember(wCtx cardinal.WorldContext, allianceEntity comp.AllianceEntity, personaTag string) (comp.AllianceComponent, error) {
+	alliance := allianceEntity.Component
+	alliance.Members = withoutPersona(alliance.Members, personaTag)
+
+	if len(alliance.Members) == 0 {
+		alliance.Leader = ""
+		err := alliance.Remove(wCtx, allianceEntity.EntityId)
+		if err != nil {
+			return alliance, fmt.Errorf("failed to remove alliance %s: %w", alliance.AllianceName, err)
+		}
+		s.stage(wCtx, game.ScoreRemove(game.BoardAlliances, alliance.AllianceName))
+		return alliance, nil
+	}
+
+	score, err := personaPlanetScore(wCtx, personaTag)
+	if err != nil {
+		return alliance, err
+	}
+	if alliance.Leader == personaTag {
+		alliance.Leader = alliance.Members[0]
+	}
+	err = alliance.Set(wCtx, allianceEntity.EntityId)
+	if err != nil {
+		return alliance, fmt.Errorf("failed to set alliance %s: %w", alliance.AllianceName, err)
+	}
+	s.stage(wCtx, game.ScoreDecrement(game.BoardAlliances, alliance.AllianceName, score))
+	return alliance, nil
+}
+
+// withPersona returns a copy of the persona tags with the persona appended, so that the
+// slices stored in AllianceIndex are never written to
+func withPersona(personaTags []string, personaTag string) []string {
+	return append(slices.Clone(personaTags), personaTag)
+}
+
+// withoutPersona returns a copy of the persona tags without the persona
+func withoutPersona(personaTags []string, personaTag string) []string {
+	result := make([]string, 0, len(personaTags))
+	for _, p := range personaTags {
+		if p != personaTag {
+			result = append(result, p)
+		}
+	}
+	return result
+}
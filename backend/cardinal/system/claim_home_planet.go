@@ -1,7 +1,6 @@
 package system
 
 import (
-	"context"
 	"fmt"
 	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
@@ -12,7 +11,7 @@ import (
 	"strconv"
 )
 
-func ClaimHomePlanetSystem(wCtx cardinal.WorldContext) error {
+func (s *ScoringSystems) ClaimHomePlanetSystem(wCtx cardinal.WorldContext) error {
 	log := wCtx.Logger()
 
 	// 1. Check that the game timer is not over, if it is, exit
@@ -66,26 +65,62 @@ func ClaimHomePlanetSystem(wCtx cardinal.WorldContext) error {
 			return result, err
 		}
 
-		// 2d. PRE-CONDITION: Verify ZK proof
+		// 2d. PRE-CONDITION: Check that the radius the proof was generated for is within the world radius.
+		// The circuit only constrains r^2, a proof for -r would hold for r as well.
+		if txData.Radius <= 0 {
+			err = fmt.Errorf("radius %d must be positive", txData.Radius)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if txData.Radius > game.WorldConstants.RadiusMax {
+			err = fmt.Errorf("radius %d exceeds the maximum radius %d", txData.Radius, game.WorldConstants.RadiusMax)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2di. PRE-CONDITION: In rim-spawn mode the proof must be for the world radius itself,
+		// the rim of a smaller radius would let players spawn anywhere
+		if game.WorldConstants.RimSpawn && txData.Radius != game.WorldConstants.RadiusMax {
+			err = fmt.Errorf("radius %d must be the maximum radius %d when spawning in the rim", txData.Radius, game.WorldConstants.RadiusMax)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2e. PRE-CONDITION: Verify ZK proof
 		// Prove: I know (x,y) such that:
 		// - x^2 + y^2 <= r^2
+		// - x^2 + y^2 > 0.98 * r^2, if RimSpawn is set
 		// - perlin(x, y) = perl
 		// - MiMC(x,y) = pub
-		publicWitness, err := txData.CreatePublicWitness()
-		if err != nil {
+		// The proof was verified ahead of time by VerifyProofsSystem
+		proof := loadProofResult(t.Hash(), func() proofResult { return verifyInitProof(txData) })
+		publicWitness := proof.publicWitness
+		if err = proof.witnessErr; err != nil {
 			err = fmt.Errorf("error creating public witness in tx for planet with location hash %s: ", txData.LocationHash)
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
-		err = txData.VerifyInitProof(publicWitness)
-		if err != nil {
+		if err = proof.verifyErr; err != nil {
 			err = fmt.Errorf("error with verifying circuit for planet with location hash %s: %w", txData.LocationHash, err)
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
-		// 2d. POST-CONDITION: Create a new planet with the player as owner
+		// 2ei. PRE-CONDITION: Check that the proof has not been accepted before
+		proofDigest, err := txData.ProofDigest(txSig.PersonaTag, publicWitness)
+		if err != nil {
+			err = fmt.Errorf("error computing proof digest for planet with location hash %s: %w", txData.LocationHash, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if _, ok = comp.LoadProofNullifierComponent(proofDigest); ok {
+			err = fmt.Errorf("proof for planet with location hash %s has already been used", txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2f. POST-CONDITION: Create a new planet with the player as owner
 		id, err := cardinal.Create(wCtx, comp.PlanetComponent{})
 		if err != nil {
 			err = fmt.Errorf("failed to create and claim planet with id %d, error: %w", id, err)
@@ -118,7 +153,7 @@ func ClaimHomePlanetSystem(wCtx cardinal.WorldContext) error {
 			return result, err
 		}
 
-		// 2e. POST-CONDITION: Create a new player entity
+		// 2g. POST-CONDITION: Create a new player entity
 		id, err = cardinal.Create(wCtx, comp.PlayerComponent{})
 		if err != nil {
 			err = fmt.Errorf("failed to create player component with id %d, error: %w", id, err)
@@ -137,24 +172,23 @@ func ClaimHomePlanetSystem(wCtx cardinal.WorldContext) error {
 			return result, err
 		}
 
-		// Add player to leaderboard with the score of a Level 0 planet
-		score, err := strconv.Atoi(game.PlanetLevel0Stats.Score)
+		score, err := planetScore(homePlanetComp)
 		if err != nil {
-			err = fmt.Errorf("failed to convert string to int, error: %w", err)
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
-		err = game.AddPlayerToLeaderboard(context.Background(), game.Player{
-			PersonaTag: txSig.PersonaTag,
-			Score:      score,
-		})
+		// 2h. POST-CONDITION: Record the proof so that it cannot be replayed. Only done after the last
+		// step that can fail, a claim that failed can be retried with the same proof.
+		err = nullifyProof(wCtx, proofDigest)
 		if err != nil {
-			err = fmt.Errorf("failed to add player with id %d to leaderboard, error: %w", id, err)
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
+		// Add player to leaderboard with the score of its home planet
+		s.stage(wCtx, game.ScoreSet(game.BoardScore, txSig.PersonaTag, score), game.ScoreSet(game.SpaceAreaScoreBoards[homePlanetComp.SpaceArea-1], txSig.PersonaTag, score))
+
 		log.Debug().Msgf("Successfully created player with persona %s with home planet at location hash %s", newPlayer.PersonaTag, homePlanetComp.LocationHash)
 
 		result = tx.ClaimHomePlanetReply{
@@ -1,7 +1,6 @@
 package system
 
 import (
-	"context"
 	"fmt"
 	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
@@ -12,7 +11,7 @@ import (
 	"strconv"
 )
 
-func DebugClaimPlanetSystem(wCtx cardinal.WorldContext) error {
+func (s *ScoringSystems) DebugClaimPlanetSystem(wCtx cardinal.WorldContext) error {
 	log := wCtx.Logger()
 
 	// Check that the game timer is not over, if it is, exit
@@ -104,24 +103,13 @@ func DebugClaimPlanetSystem(wCtx cardinal.WorldContext) error {
 			return result, err
 		}
 
-		// Add player to leaderboard with the score of a Level 0 planet
-		basePlanetScore, err := strconv.Atoi(game.BasePlanetLevelStats[int(homePlanetComp.Level)].Score)
+		// Add player to leaderboard with the score of its home planet
+		score, err := planetScore(homePlanetComp)
 		if err != nil {
-			err = fmt.Errorf("failed to convert string to int, error: %w", err)
-			log.Error().Err(err).Msg("")
-			return result, err
-		}
-
-		score := basePlanetScore * int(homePlanetComp.SpaceArea)
-		err = game.AddPlayerToLeaderboard(context.Background(), game.Player{
-			PersonaTag: txSig.PersonaTag,
-			Score:      score,
-		})
-		if err != nil {
-			err = fmt.Errorf("failed to add player with id %d to leaderboard, error: %w", id, err)
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
+		s.stage(wCtx, game.ScoreSet(game.BoardScore, txSig.PersonaTag, score), game.ScoreSet(game.SpaceAreaScoreBoards[homePlanetComp.SpaceArea-1], txSig.PersonaTag, score))
 
 		log.Debug().Msgf("Successfully created player with persona %s with home planet at location hash %s", newPlayer.PersonaTag, homePlanetComp.LocationHash)
 
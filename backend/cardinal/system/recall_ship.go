@@ -0,0 +1,87 @@
+package system
+
+import (
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// RecallShipSystem turns a ship around. It flies back to its origin planet for as long as it
+// has been travelling and loses game.WorldConstants.RecallPenalty of its energy. Once back,
+// ShipArriveSystem lands it like any other ship.
+func RecallShipSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each recall ship transaction
+	tx.RecallShip.Each(wCtx, func(t cardinal.TxData[tx.RecallShipMsg]) (result tx.RecallShipReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to recall ship: %d", txData.ShipId)
+
+		// 2a. PRE-CONDITION: Check that the ship exists
+		shipId := cardinal.EntityID(txData.ShipId)
+		ship, ok := comp.LoadShipComponent(shipId)
+		if !ok {
+			err = fmt.Errorf("no ship exists with id %d", txData.ShipId)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2b. PRE-CONDITION: Check that the ship belongs to the signer
+		if ship.OwnerPersonaTag != txSig.PersonaTag {
+			err = fmt.Errorf("ship with id %d is not owned by %s", txData.ShipId, txSig.PersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. PRE-CONDITION: Check that the ship is still on its way out
+		if ship.Recalled {
+			err = fmt.Errorf("ship with id %d has already been recalled", txData.ShipId)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		currentTick := int64(wCtx.CurrentTick())
+		if ship.TickArrive <= currentTick {
+			err = fmt.Errorf("ship with id %d arrives at tick %d and can no longer be recalled", txData.ShipId, ship.TickArrive)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2d. POST-CONDITION: The ship flies back the way it came, with less energy
+		shipReceipt := tx.ShipReceipt{
+			Id:               txData.ShipId,
+			OwnerPersonaTag:  ship.OwnerPersonaTag,
+			LocationHashFrom: ship.LocationHashTo,
+			LocationHashTo:   ship.LocationHashFrom,
+			TickStart:        currentTick,
+			TickArrive:       currentTick + (currentTick - ship.TickStart),
+			EnergyOnEmbark:   utils.DecToStr(utils.EnergyAfterRecall(ship.EnergyOnEmbark, utils.StrToDec(game.WorldConstants.RecallPenalty))),
+			Recalled:         true,
+		}
+		recalledShip := convertShipReceiptToComp(shipReceipt)
+		err = recalledShip.Set(wCtx, shipId)
+		if err != nil {
+			err = fmt.Errorf("failed to set recalled ship with id %d: %w", txData.ShipId, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		log.Debug().Msgf("Ship was recalled successfully: %+v", shipReceipt)
+
+		result.RecalledShip = shipReceipt
+		return result, nil
+	})
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package system
+
+import (
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// RevealLocationSystem publishes the coordinates of a planet. Anyone who knows the
+// coordinates of an existing planet can reveal them, not only its owner.
+func RevealLocationSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each reveal location transaction
+	tx.RevealLocation.Each(wCtx, func(t cardinal.TxData[tx.RevealLocationMsg]) (result tx.RevealLocationReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to reveal location hash: %s", txData.LocationHash)
+
+		// 1. PRE-CONDITION: Check that the LocationHash is well formatted
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Check that a planet exists at the location hash
+		planetEntity, ok := comp.LoadPlanetComponent(txData.LocationHash)
+		if !ok {
+			err = fmt.Errorf("no planet exists at the following location hash %s", txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2b. PRE-CONDITION: Check that the planet has not been revealed yet
+		planet := planetEntity.Component
+		if planet.Revealed {
+			err = fmt.Errorf("planet with location hash %s has already been revealed", txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. PRE-CONDITION: Verify ZK proof
+		// Prove: MiMC(x,y) = pub
+		// The proof was verified ahead of time by VerifyProofsSystem
+		proof := loadProofResult(t.Hash(), func() proofResult { return verifyRevealProof(txData) })
+		if err = proof.witnessErr; err != nil {
+			err = fmt.Errorf("error creating public witness in tx for planet with location hash %s: %w", txData.LocationHash, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		if err = proof.verifyErr; err != nil {
+			err = fmt.Errorf("error with verifying reveal circuit for planet with location hash %s: %w", txData.LocationHash, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2d. POST-CONDITION: Store the coordinates on the planet
+		planet.Revealed = true
+		planet.RevealedX = txData.X
+		planet.RevealedY = txData.Y
+		planet.RevealedBy = txSig.PersonaTag
+		planet.RevealedTick = int64(wCtx.CurrentTick())
+		err = planet.Set(wCtx, planetEntity.EntityId)
+		if err != nil {
+			err = fmt.Errorf("failed to set revealed coordinates for planet with location hash %s, error: %w", txData.LocationHash, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		log.Debug().Msgf("Persona %s revealed planet at location hash %s", txSig.PersonaTag, txData.LocationHash)
+
+		result = tx.RevealLocationReply{
+			LocationHash:    planet.LocationHash,
+			X:               planet.RevealedX,
+			Y:               planet.RevealedY,
+			OwnerPersonaTag: planet.OwnerPersonaTag,
+			RevealedBy:      planet.RevealedBy,
+			RevealedTick:    planet.RevealedTick,
+		}
+		return result, nil
+	})
+
+	return nil
+}
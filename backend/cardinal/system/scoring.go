@@ -0,0 +1,385 @@
+package system
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"pkg.world.dev/world-engine/cardinal"
+	"sort"
+)
+
+// ScoringSystems are the systems that change the scores of players and alliances, they keep the
+// leaderboards of their world up to date. Score changes are staged per tick and only committed
+// to the leaderboards once the tick is over, see LeaderboardCommitSystem.
+type ScoringSystems struct {
+	boards  game.LeaderboardRegistry
+	history game.LeaderboardHistory
+	// Batches of the ticks that are not committed to the leaderboard yet, in tick order
+	pending []game.ScoreBatch
+	// Whether the scores were reconciled since the world started
+	reconciled bool
+	// Whether a snapshot of the leaderboard is due but couldn't be taken yet
+	snapshotDue bool
+	// Whether the snapshot of the end of the game was saved
+	finalSnapshotSaved bool
+}
+
+func NewScoringSystems(boards game.LeaderboardRegistry, history game.LeaderboardHistory) *ScoringSystems {
+	return &ScoringSystems{boards: boards, history: history}
+}
+
+// stage buffers score mutations in the batch of the current tick. Systems stage them after
+// their last step that can fail, so that a failed transaction leaves the leaderboard untouched.
+func (s *ScoringSystems) stage(wCtx cardinal.WorldContext, mutations ...game.ScoreMutation) {
+	if len(mutations) == 0 {
+		return
+	}
+	tick := wCtx.CurrentTick()
+	if n := len(s.pending); n > 0 && s.pending[n-1].Tick == tick {
+		s.pending[n-1].Mutations = append(s.pending[n-1].Mutations, mutations...)
+		return
+	}
+	s.pending = append(s.pending, game.ScoreBatch{Tick: tick, Mutations: mutations})
+}
+
+// planetScoreMutations add the score of the planet to the boards of its owner, a negative score
+// takes it away. Planets without an owner score for nobody.
+func planetScoreMutations(personaTag string, planet comp.PlanetComponent, score int) []game.ScoreMutation {
+	if personaTag == "" {
+		return nil
+	}
+	mutations := []game.ScoreMutation{
+		game.ScoreIncrement(game.BoardScore, personaTag, score),
+		// planetScore verified that the space area is valid
+		game.ScoreIncrement(game.SpaceAreaScoreBoards[planet.SpaceArea-1], personaTag, score),
+	}
+	if allianceEntity, ok := comp.LoadAllianceOf(personaTag); ok {
+		mutations = append(mutations, game.ScoreIncrement(game.BoardAlliances, allianceEntity.Component.AllianceName, score))
+	}
+	return mutations
+}
+
+// LeaderboardCommitSystem commits the score mutations of the previous ticks to the leaderboards,
+// one batch per tick. A tick only starts once the state of the previous one was committed, so
+// the leaderboard never holds scores of a tick that is rolled back. It must run before the
+// other scoring systems. A batch that fails to commit is kept and retried on the next tick,
+// committing it again after a commit whose reply was lost does nothing. Batches are committed
+// as ticks of the game of the DefaultsComponent, so a reset world doesn't collide with the
+// ticks committed before the reset.
+func (s *ScoringSystems) LeaderboardCommitSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	if len(s.pending) == 0 || s.pending[0].Tick >= wCtx.CurrentTick() {
+		return nil
+	}
+	// Worlds without defaults commit their ticks like worlds created before games had an ID
+	gameID, err := comp.GetGameID(wCtx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read the game ID, committing the scores without one")
+	}
+
+	for len(s.pending) > 0 && s.pending[0].Tick < wCtx.CurrentTick() {
+		batch := s.pending[0]
+		batch.Game = gameID
+		err := s.boards.Commit(context.Background(), batch)
+		if errors.Is(err, game.ErrTickAlreadyCommitted) {
+			// The world went back to an older tick of the game, e.g. it was restored from an older
+			// state. The leaderboard already holds the scores of the ticks it went back over.
+			log.Error().Err(err).Msgf("dropping the scores of tick %d, the leaderboard already holds a later tick of the game", batch.Tick)
+			s.pending = s.pending[1:]
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("failed to commit the scores of tick %d to the leaderboard, %d batch(es) pending",
+				batch.Tick, len(s.pending))
+			return nil
+		}
+		s.pending = s.pending[1:]
+	}
+	return nil
+}
+
+// LeaderboardSnapshotSystem saves the leaderboard to the history every
+// game.WorldConstants.LeaderboardSnapshotInterval ticks and once more when the game is over.
+// Snapshots are saved as ticks of the game of the DefaultsComponent, like the committed scores.
+// Snapshots hold the scores at the start of their tick, so it must run after
+// LeaderboardCommitSystem and ScoreReconcileSystem. A snapshot that can't be taken because
+// scores are still pending is taken on the first tick they are committed.
+func (s *ScoringSystems) LeaderboardSnapshotSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Snapshot on every interval and once when the game is over. The final snapshot is saved
+	// as the tick the game ended, taking it again after a restart replaces it.
+	tick := wCtx.CurrentTick()
+	gameOver := checkTimer(wCtx) != nil
+	if gameOver {
+		if s.finalSnapshotSaved {
+			return nil
+		}
+		tick = uint64(game.WorldConstants.InstanceTimer) * uint64(game.WorldConstants.TickRate)
+		s.snapshotDue = true
+	} else if interval := game.WorldConstants.LeaderboardSnapshotInterval; interval > 0 && tick > 0 && tick%uint64(interval) == 0 {
+		s.snapshotDue = true
+	}
+	if !s.snapshotDue {
+		return nil
+	}
+
+	// 2. PRE-CONDITION: The leaderboard holds the scores of all previous ticks
+	if len(s.pending) > 0 {
+		log.Warn().Msgf("Delaying the leaderboard snapshot of tick %d, %d batch(es) of scores are pending", tick, len(s.pending))
+		return nil
+	}
+
+	// 3. POST-CONDITION: The whole leaderboard is saved as the snapshot of the tick
+	leaderboard, err := s.boards.Board(game.BoardScore)
+	if err != nil {
+		log.Error().Err(err).Msg("")
+		return nil
+	}
+	ranked, err := leaderboard.GetPlayersInRankRange(context.Background(), 0, -1)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to read the leaderboard for its snapshot")
+		return nil
+	}
+	players := make([]game.Player, len(ranked))
+	for i, player := range ranked {
+		players[i] = player.Player
+	}
+	gameID, err := comp.GetGameID(wCtx)
+	if err != nil {
+		log.Warn().Err(err).Msg("failed to read the game ID, saving the snapshot without one")
+	}
+	err = s.history.SaveSnapshot(context.Background(), gameID, tick, players)
+	if err != nil {
+		log.Error().Err(err).Msgf("failed to save the leaderboard snapshot of tick %d", tick)
+		return nil
+	}
+	s.snapshotDue = false
+	s.finalSnapshotSaved = gameOver
+	log.Info().Msgf("Saved the leaderboard snapshot of tick %d with %d player(s)", tick, len(players))
+	return nil
+}
+
+// reconciledBoards are the leaderboards whose scores are derived from the world state, the score
+// boards from the owned planets and the boards that count events from the counters of the players
+var reconciledBoards = []string{
+	game.BoardScore,
+	game.BoardScoreNebula,
+	game.BoardScoreSafeSpace,
+	game.BoardScoreDeepSpace,
+	game.BoardConquests,
+	game.BoardEnergySent,
+	game.BoardAlliances,
+}
+
+// countPlayerEvent updates the counters of the player that the boards counting events are
+// rebuilt from, see expectedScores. Systems call it along with staging the score mutation, once
+// the event happened, so a failure is only logged.
+func countPlayerEvent(wCtx cardinal.WorldContext, personaTag string, count func(player *comp.PlayerComponent)) error {
+	playerEntity, ok := comp.LoadPlayerEntity(personaTag)
+	if !ok {
+		return fmt.Errorf("player %s does not exist", personaTag)
+	}
+	count(&playerEntity.Component)
+	return playerEntity.Component.Set(wCtx, playerEntity.EntityId)
+}
+
+// ScoreDrift is a member of a leaderboard whose score disagrees with the world state
+type ScoreDrift struct {
+	Board string
+	// Persona tag of a player, or the name of an alliance on game.BoardAlliances
+	PersonaTag    string
+	ExpectedScore int
+	// Score on the leaderboard, 0 if the persona is missing from it
+	ActualScore int
+	Missing     bool
+}
+
+// ScoreReconcileSystem recomputes every score from the owned planets and the counters of the
+// players on startup, then every game.WorldConstants.ScoreReconcileInterval ticks. Scores are
+// changed incrementally by the other systems, so staged scores lost on a restart, a wiped Redis
+// or new level or space area score constants leave the leaderboards disagreeing with the world.
+// It must run right after LeaderboardCommitSystem, when the planets and the leaderboards both
+// hold the state of the previous tick.
+func (s *ScoringSystems) ScoreReconcileSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Wait for the staged scores to be committed, the planets are ahead of the leaderboards until then
+	if len(s.pending) > 0 {
+		return nil
+	}
+
+	// 1b. The scores are derived from the planets, check if indexes need to be rebuilt, if so, rebuild
+	err := rebuildIndexesOnce(wCtx)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to rebuild the indexes before reconciling the leaderboards")
+		return nil
+	}
+
+	// 2. Reconcile once on startup, then on every interval
+	interval := game.WorldConstants.ScoreReconcileInterval
+	if s.reconciled && (interval <= 0 || wCtx.CurrentTick()%uint64(interval) != 0) {
+		return nil
+	}
+
+	drifts, err := s.ReconcileScores(wCtx, game.WorldConstants.ScoreReconcileFix)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to reconcile the leaderboards")
+		return nil
+	}
+	s.reconciled = true
+
+	// 3. POST-CONDITION: Report the drift
+	for _, drift := range drifts {
+		log.Warn().Msgf("Leaderboard %s score of %s was %d (missing: %v) but its planets score %d, fixed: %v",
+			drift.Board, drift.PersonaTag, drift.ActualScore, drift.Missing, drift.ExpectedScore, game.WorldConstants.ScoreReconcileFix)
+	}
+	log.Info().Msgf("Reconciled the leaderboards at tick %d, %d score(s) drifted", wCtx.CurrentTick(), len(drifts))
+	return nil
+}
+
+// ReconcileScores compares the leaderboards with the scores of the world state and returns the
+// members that drifted, ordered by board and persona tag. If fix is set, their scores are overwritten.
+func (s *ScoringSystems) ReconcileScores(wCtx cardinal.WorldContext, fix bool) ([]ScoreDrift, error) {
+	expectedBoards, err := expectedScores(wCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifts []ScoreDrift
+	for _, name := range reconciledBoards {
+		board, err := s.boards.Board(name)
+		if err != nil {
+			return nil, err
+		}
+		players, err := board.GetPlayersInRankRange(context.Background(), 0, -1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the leaderboard %s: %w", name, err)
+		}
+		actual := make(map[string]int, len(players))
+		for _, player := range players {
+			actual[player.PersonaTag] = player.Score
+		}
+
+		expected := expectedBoards[name]
+		var boardDrifts []ScoreDrift
+		for personaTag, expectedScore := range expected {
+			actualScore, ok := actual[personaTag]
+			if !ok || actualScore != expectedScore {
+				boardDrifts = append(boardDrifts, ScoreDrift{Board: name, PersonaTag: personaTag, ExpectedScore: expectedScore, ActualScore: actualScore, Missing: !ok})
+			}
+		}
+		// Members of the leaderboard that own no planet and aren't players or alliances should have no score
+		for personaTag, actualScore := range actual {
+			if _, ok := expected[personaTag]; !ok && actualScore != 0 {
+				boardDrifts = append(boardDrifts, ScoreDrift{Board: name, PersonaTag: personaTag, ActualScore: actualScore})
+			}
+		}
+		sort.Slice(boardDrifts, func(i, j int) bool {
+			return boardDrifts[i].PersonaTag < boardDrifts[j].PersonaTag
+		})
+
+		if fix {
+			for _, drift := range boardDrifts {
+				err = board.AddPlayer(context.Background(), game.Player{PersonaTag: drift.PersonaTag, Score: drift.ExpectedScore})
+				if err != nil {
+					return append(drifts, boardDrifts...), fmt.Errorf("failed to fix the score of persona tag %s on leaderboard %s: %w", drift.PersonaTag, name, err)
+				}
+			}
+		}
+		drifts = append(drifts, boardDrifts...)
+	}
+	return drifts, nil
+}
+
+// expectedScores sums the scores of the planets owned by each persona, per reconciled board, and
+// reads the conquests and energy sent from the players. Players without planets score 0 on
+// game.BoardScore, alliances without planets score 0 on game.BoardAlliances. Players only
+// have a score on the boards counting events once they count one.
+func expectedScores(wCtx cardinal.WorldContext) (map[string]map[string]int, error) {
+	scores := make(map[string]map[string]int, len(reconciledBoards))
+	for _, name := range reconciledBoards {
+		scores[name] = make(map[string]int)
+	}
+	comp.PlayerIndex.Range(func(key, value interface{}) bool {
+		playerEntity, ok := value.(comp.PlayerEntity)
+		if !ok {
+			wCtx.Logger().Info().Msg("Found incorrect type in value of PlayerIndex sync.Map")
+			return true
+		}
+		player := playerEntity.Component
+		scores[game.BoardScore][player.PersonaTag] = 0
+		if player.Conquests != 0 {
+			scores[game.BoardConquests][player.PersonaTag] = player.Conquests
+		}
+		if player.EnergySent != 0 {
+			scores[game.BoardEnergySent][player.PersonaTag] = player.EnergySent
+		}
+		return true
+	})
+	comp.AllianceIndex.Range(func(key, value interface{}) bool {
+		allianceEntity, ok := value.(comp.AllianceEntity)
+		if !ok {
+			wCtx.Logger().Info().Msg("Found incorrect type in value of AllianceIndex sync.Map")
+			return true
+		}
+		scores[game.BoardAlliances][allianceEntity.Component.AllianceName] = 0
+		return true
+	})
+
+	var err error
+	comp.PlanetIndex.Range(func(key, value interface{}) bool {
+		planetEntity, ok := value.(comp.PlanetEntity)
+		if !ok {
+			wCtx.Logger().Info().Msg("Found incorrect type in value of PlanetIndex sync.Map")
+			return true
+		}
+		planet := planetEntity.Component
+		if planet.OwnerPersonaTag == "" {
+			return true
+		}
+		var score int
+		score, err = planetScore(planet)
+		if err != nil {
+			return false
+		}
+		for _, mutation := range planetScoreMutations(planet.OwnerPersonaTag, planet, score) {
+			scores[mutation.Board][mutation.Member] += mutation.Amount
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scores, nil
+}
+
+// personaPlanetScore sums the scores of the planets owned by the persona
+func personaPlanetScore(wCtx cardinal.WorldContext, personaTag string) (int, error) {
+	total := 0
+	var err error
+	comp.PlanetIndex.Range(func(key, value interface{}) bool {
+		planetEntity, ok := value.(comp.PlanetEntity)
+		if !ok {
+			wCtx.Logger().Info().Msg("Found incorrect type in value of PlanetIndex sync.Map")
+			return true
+		}
+		if planetEntity.Component.OwnerPersonaTag != personaTag {
+			return true
+		}
+		var score int
+		score, err = planetScore(planetEntity.Component)
+		if err != nil {
+			return false
+		}
+		total += score
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -3,6 +3,7 @@ package system
 import (
 	"fmt"
 	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
 	"github.com/ericlagergren/decimal"
@@ -12,7 +13,7 @@ import (
 
 var RebuildIndex = true
 
-func SendEnergySystem(wCtx cardinal.WorldContext) error {
+func (s *ScoringSystems) SendEnergySystem(wCtx cardinal.WorldContext) error {
 	log := wCtx.Logger()
 
 	// 1. Check that the game timer is not over, if it is, exit
@@ -23,14 +24,10 @@ func SendEnergySystem(wCtx cardinal.WorldContext) error {
 	}
 
 	// 1b. Check if indexes need to be rebuilt, if so, rebuild
-	if RebuildIndex {
-		err = rebuildDefaultsAndComponentIndexes(wCtx)
-		if err != nil {
-			log.Debug().Msg(err.Error())
-			return nil
-		} else {
-			RebuildIndex = false
-		}
+	err = rebuildIndexesOnce(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
 	}
 
 	// 2. For each ship send transactions,
@@ -46,6 +43,27 @@ func SendEnergySystem(wCtx cardinal.WorldContext) error {
 			return result, err
 		}
 
+		// 1b. PRE-CONDITION: Check that the radius the proof was generated for is within the world radius.
+		// The circuit only constrains r^2 and distMax^2, a proof for -r or -distMax would hold for r and distMax as well.
+		if txData.RadiusTo <= 0 {
+			err = fmt.Errorf("radius %d must be positive", txData.RadiusTo)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if txData.RadiusTo > game.WorldConstants.RadiusMax {
+			err = fmt.Errorf("radius %d exceeds the maximum radius %d", txData.RadiusTo, game.WorldConstants.RadiusMax)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 1c. PRE-CONDITION: Check that the distance the proof was generated for is positive, a negative
+		// distance would refund energy on embark and arrive before the ship was sent
+		if txData.MaxDistance <= 0 {
+			err = fmt.Errorf("max distance %d must be positive", txData.MaxDistance)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
 		// 2a. PRE-CONDITION: Verify that the origin planet exist
 		planetFromEntity, ok := comp.LoadPlanetComponent(txData.LocationHashFrom)
 		if ok == false {
@@ -78,6 +96,21 @@ func SendEnergySystem(wCtx cardinal.WorldContext) error {
 			return result, err
 		}
 
+		// 2ci. PRE-CONDITION: Verify that the distance the proof was generated for is within the range of the origin planet
+		if utils.GreaterThan(utils.Int64ToDec(txData.MaxDistance), planetFrom.Range) {
+			err = fmt.Errorf("max distance %d exceeds the range %s of origin planet with hash %s", txData.MaxDistance, utils.DecToStr(planetFrom.Range), txData.LocationHashFrom)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2cii. PRE-CONDITION: Verify that the energy sent covers the travel cost over the distance
+		energyOnEmbark := utils.EnergyOnEmbark(utils.Int64ToDec(txData.Energy), planetFrom.EnergyMax, utils.Int64ToDec(txData.MaxDistance), planetFrom.Range)
+		if utils.LessThanOrEqual(energyOnEmbark, decimal.New(0, 0)) {
+			err = fmt.Errorf("destination planet with hash %s is out of range, %d energy does not cover the travel cost over distance %d", txData.LocationHashTo, txData.Energy, txData.MaxDistance)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
 		// 2d. PRE-CONDITION: Verify that the destination planet exist
 		planetToStats, err := utils.GetPlanetStatsByLocationHash(txData.LocationHashTo, txData.PerlinTo)
 		if err != nil {
@@ -93,20 +126,34 @@ func SendEnergySystem(wCtx cardinal.WorldContext) error {
 		// - (x1-x2)^2 + (y1-y2)^2 <= distMax^2
 		// - MiMCSponge(x1,y1) = pub1
 		// - MiMCSponge(x2,y2) = pub2
-		publicWitness, err := txData.CreatePublicWitness()
-		if err != nil {
+		// The proof was verified ahead of time by VerifyProofsSystem
+		proof := loadProofResult(t.Hash(), func() proofResult { return verifyMoveProof(txData) })
+		publicWitness := proof.publicWitness
+		if err = proof.witnessErr; err != nil {
 			err = fmt.Errorf("error creating public witness for send energy tx: %w", err)
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
-		err = txData.VerifyMoveProof(publicWitness)
-		if err != nil {
+		if err = proof.verifyErr; err != nil {
 			err = fmt.Errorf("error with verifying circuit for send energy tx from planet with location hash %s: %w", txData.LocationHashFrom, err)
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
+		// 2ei. PRE-CONDITION: Check that the proof has not been accepted before
+		proofDigest, err := txData.ProofDigest(txSig.PersonaTag, publicWitness)
+		if err != nil {
+			err = fmt.Errorf("error computing proof digest for send energy tx from planet with location hash %s: %w", txData.LocationHashFrom, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if _, ok = comp.LoadProofNullifierComponent(proofDigest); ok {
+			err = fmt.Errorf("proof for send energy tx from planet with location hash %s has already been used", txData.LocationHashFrom)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
 		// TODO: make this atomic
 		// 2f. POST-CONDITION: Destination planet is created if it doesn't exist before
 		var planetToId cardinal.EntityID
@@ -155,21 +202,30 @@ func SendEnergySystem(wCtx cardinal.WorldContext) error {
 		}
 
 		// 2fi. PRE-CONDITION: Verify that the ship has enough energy to reach the destination planet with energy to spare
-		energyOnEmbark := utils.EnergyOnEmbark(utils.Int64ToDec(txData.Energy), planetFrom.EnergyMax, utils.Int64ToDec(txData.MaxDistance), planetFrom.Range)
-		enoughEnergyForFriendlyPlant := (planetFrom.OwnerPersonaTag == planetTo.OwnerPersonaTag) && (utils.LessThanOrEqual(utils.EnergyOnArrivalAtFriendlyPlanet(energyOnEmbark), decimal.New(0, 0)))
+		// Planets of the sender and its allies are friendly
+		isFriendly := comp.AreFriendly(planetFrom.OwnerPersonaTag, planetTo.OwnerPersonaTag)
+		enoughEnergyForFriendlyPlant := isFriendly && (utils.LessThanOrEqual(utils.EnergyOnArrivalAtFriendlyPlanet(energyOnEmbark), decimal.New(0, 0)))
 		if enoughEnergyForFriendlyPlant {
 			err = fmt.Errorf("ship did not have enough energy to arrive at friendly planet")
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
-		enoughEnergyForEnemyPlanet := (planetFrom.OwnerPersonaTag != planetTo.OwnerPersonaTag) && (utils.LessThanOrEqual(utils.EnergyAfterDefenseDebuff(energyOnEmbark, planetTo.Defense), decimal.New(0, 0)))
+		enoughEnergyForEnemyPlanet := !isFriendly && (utils.LessThanOrEqual(utils.EnergyAfterDefenseDebuff(energyOnEmbark, planetTo.Defense), decimal.New(0, 0)))
 		if enoughEnergyForEnemyPlanet {
 			err = fmt.Errorf("ship did not have enough energy to arrive at enemy planet")
 			log.Error().Err(err).Msg("")
 			return result, err
 		}
 
+		// 2fii. POST-CONDITION: Record the proof so that it cannot be replayed. Only done once every
+		// pre-condition passed, a rejected send can be retried with the same proof.
+		err = nullifyProof(wCtx, proofDigest)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
 		// 2f. POST-CONDITION: Ship entity is created
 		shipId, err := cardinal.Create(wCtx, comp.ShipComponent{})
 		if err != nil {
@@ -209,6 +265,15 @@ func SendEnergySystem(wCtx cardinal.WorldContext) error {
 			return result, err
 		}
 
+		// 2h. POST-CONDITION: The energy counts towards the energy sent by the player
+		err = countPlayerEvent(wCtx, txSig.PersonaTag, func(player *comp.PlayerComponent) {
+			player.EnergySent += int(txData.Energy)
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to count the energy sent by %s", txSig.PersonaTag)
+		}
+		s.stage(wCtx, game.ScoreIncrement(game.BoardEnergySent, txSig.PersonaTag, int(txData.Energy)))
+
 		result.SentShip = shipReceipt
 		result.NewSenderEnergy = utils.DecToStr(planetFrom.EnergyCurrent)
 		return result, nil
@@ -7,7 +7,9 @@ import (
 	"regexp"
 
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/keys"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
 	"pkg.world.dev/world-engine/cardinal"
 )
 
@@ -90,14 +92,120 @@ func SetConstantSystem(wCtx cardinal.WorldContext) error {
 			log.Debug().Msgf("Successfully set the instance time remaining to: %d", game.WorldConstants.InstanceTimer)
 
 		case "InstanceName":
-			newName, ok := txData.Value.(string)
-			log.Debug().Msgf("Received payload to set InstanceName at with new instance name: %s", newName)
+			// The leaderboards and their history are namespaced by the instance name when cardinal
+			// starts, renaming the instance would leave them behind
+			return result, errors.New("InstanceName can only be set with CARDINAL_NAMESPACE when cardinal starts")
+
+		case "CircuitArtifactUUID":
+			newUUID, ok := txData.Value.(string)
+			log.Debug().Msgf("Received payload to set CircuitArtifactUUID with new artifact version: %s", newUUID)
+			if !ok {
+				return result, errors.New("new value for CircuitArtifactUUID was not a string")
+			}
+			vks, ok := keys.Load(newUUID)
+			if !ok {
+				return result, fmt.Errorf("no verifying keys registered for circuit artifact version %s", newUUID)
+			}
+			if game.WorldConstants.RimSpawn && vks.InitRim == nil {
+				return result, fmt.Errorf("circuit artifact version %s has no rim-spawn init verifying key", newUUID)
+			}
+			if err = vks.CheckWorldConstants(game.WorldConstants); err != nil {
+				return result, fmt.Errorf("circuit artifact version %s: %w", newUUID, err)
+			}
+			game.WorldConstants.CircuitArtifactUUID = newUUID
+			result.Success = true
+			log.Debug().Msgf("Successfully set the circuit artifact version to: %s", game.WorldConstants.CircuitArtifactUUID)
+
+		case "AcceptedCircuitArtifactUUIDs":
+			bz, err := json.Marshal(txData.Value)
+			if err != nil {
+				return result, err
+			}
+			var uuids []string
+			err = json.Unmarshal(bz, &uuids)
+			if err != nil {
+				return result, errors.New("new value for AcceptedCircuitArtifactUUIDs was not a list of strings")
+			}
+			for _, uuid := range uuids {
+				vks, ok := keys.Load(uuid)
+				if !ok {
+					return result, fmt.Errorf("no verifying keys registered for circuit artifact version %s", uuid)
+				}
+				if err = vks.CheckWorldConstants(game.WorldConstants); err != nil {
+					return result, fmt.Errorf("circuit artifact version %s: %w", uuid, err)
+				}
+			}
+			game.WorldConstants.AcceptedCircuitArtifactUUIDs = uuids
+			result.Success = true
+			log.Debug().Msgf("Successfully set the accepted circuit artifact versions to: %v", game.WorldConstants.AcceptedCircuitArtifactUUIDs)
+
+		case "RimSpawn":
+			rimSpawn, ok := txData.Value.(bool)
+			log.Debug().Msgf("Received payload to set RimSpawn with new value: %v", txData.Value)
 			if !ok {
-				return result, errors.New("new value for InstanceName was not a string")
+				return result, errors.New("new value for RimSpawn was not a bool")
+			}
+			if rimSpawn {
+				vks, ok := keys.Load(game.WorldConstants.CircuitArtifactUUID)
+				if !ok || vks.InitRim == nil {
+					return result, fmt.Errorf(
+						"circuit artifact version %s has no rim-spawn init verifying key", game.WorldConstants.CircuitArtifactUUID,
+					)
+				}
 			}
-			game.WorldConstants.InstanceName = newName
+			game.WorldConstants.RimSpawn = rimSpawn
 			result.Success = true
-			log.Debug().Msgf("Successfully set the instance name to: %s", game.WorldConstants.InstanceName)
+			log.Debug().Msgf("Successfully set rim spawn to: %v", game.WorldConstants.RimSpawn)
+
+		case "RecallPenalty":
+			log.Debug().Msgf("Received payload to set RecallPenalty with new value: %v", txData.Value)
+			newPenalty, err := fractionConstant(txData.ConstantName, txData.Value)
+			if err != nil {
+				return result, err
+			}
+			game.WorldConstants.RecallPenalty = newPenalty
+			result.Success = true
+			log.Debug().Msgf("Successfully set the recall penalty to: %s", game.WorldConstants.RecallPenalty)
+
+		case "AbandonPenalty":
+			log.Debug().Msgf("Received payload to set AbandonPenalty with new value: %v", txData.Value)
+			newPenalty, err := fractionConstant(txData.ConstantName, txData.Value)
+			if err != nil {
+				return result, err
+			}
+			game.WorldConstants.AbandonPenalty = newPenalty
+			result.Success = true
+			log.Debug().Msgf("Successfully set the abandon penalty to: %s", game.WorldConstants.AbandonPenalty)
+
+		case "ScoreReconcileInterval":
+			log.Debug().Msgf("Received payload to set ScoreReconcileInterval with new value: %v", txData.Value)
+			newInterval, ok := txData.Value.(float64)
+			if !ok || newInterval < 0 || newInterval != float64(int(newInterval)) {
+				return result, fmt.Errorf("new value for ScoreReconcileInterval must be a non-negative number of ticks, got %v", txData.Value)
+			}
+			game.WorldConstants.ScoreReconcileInterval = int(newInterval)
+			result.Success = true
+			log.Debug().Msgf("Successfully set the score reconcile interval to: %d", game.WorldConstants.ScoreReconcileInterval)
+
+		case "ScoreReconcileFix":
+			log.Debug().Msgf("Received payload to set ScoreReconcileFix with new value: %v", txData.Value)
+			fix, ok := txData.Value.(bool)
+			if !ok {
+				return result, errors.New("new value for ScoreReconcileFix was not a bool")
+			}
+			game.WorldConstants.ScoreReconcileFix = fix
+			result.Success = true
+			log.Debug().Msgf("Successfully set score reconcile fix to: %v", game.WorldConstants.ScoreReconcileFix)
+
+		case "LeaderboardSnapshotInterval":
+			log.Debug().Msgf("Received payload to set LeaderboardSnapshotInterval with new value: %v", txData.Value)
+			newInterval, ok := txData.Value.(float64)
+			if !ok || newInterval < 0 || newInterval != float64(int(newInterval)) {
+				return result, fmt.Errorf("new value for LeaderboardSnapshotInterval must be a non-negative number of ticks, got %v", txData.Value)
+			}
+			game.WorldConstants.LeaderboardSnapshotInterval = int(newInterval)
+			result.Success = true
+			log.Debug().Msgf("Successfully set the leaderboard snapshot interval to: %d", game.WorldConstants.LeaderboardSnapshotInterval)
 
 		case "NebulaSpaceConstants":
 			err = handleSpaceConstantsMsg(wCtx, txData.Value, 0)
@@ -144,3 +252,16 @@ func handleSpaceConstantsMsg(wCtx cardinal.WorldContext, value any, spaceArea in
 	}
 	return nil
 }
+
+// fractionConstant checks that the value of a constant is a decimal string between 0 and 1
+func fractionConstant(name string, value any) (string, error) {
+	fraction, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("new value for %s was not a decimal string", name)
+	}
+	dec := utils.StrToDec(fraction)
+	if !dec.IsFinite() || utils.LessThan(dec, utils.IntToDec(0)) || utils.GreaterThan(dec, utils.IntToDec(1)) {
+		return "", fmt.Errorf("new value for %s must be between 0 and 1, got %s", name, fraction)
+	}
+	return fraction, nil
+}
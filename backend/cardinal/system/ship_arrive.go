@@ -1,17 +1,15 @@
 package system
 
 import (
-	"context"
-	"fmt"
 	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
 	"github.com/ericlagergren/decimal"
 	"pkg.world.dev/world-engine/cardinal"
-	"strconv"
+	"sort"
 )
 
-func ShipArriveSystem(wCtx cardinal.WorldContext) error {
+func (s *ScoringSystems) ShipArriveSystem(wCtx cardinal.WorldContext) error {
 	log := wCtx.Logger()
 
 	// Check that the game timer is not over, if it is, exit
@@ -21,7 +19,27 @@ func ShipArriveSystem(wCtx cardinal.WorldContext) error {
 		return nil
 	}
 
-	// 1. For each ships
+	// 1. Collect the ships that have arrived, in the order they are applied
+	// 2. Resolve the ships landing on the same planet in the same tick as one battle
+	for _, battle := range groupArrivalsByBattle(arrivedShips(wCtx)) {
+		s.resolveBattle(wCtx, battle)
+	}
+
+	return nil
+}
+
+// shipArrival is a ship due to land this tick
+type shipArrival struct {
+	id   cardinal.EntityID
+	ship comp.ShipComponent
+}
+
+// arrivedShips returns the ships whose arrival tick has passed, ordered by arrival tick,
+// then launch tick, then entity ID. ShipIndex is a sync.Map with no iteration order, so
+// without sorting, ships landing on the same planet in the same tick could be applied in
+// any order and the same inputs could produce different conquest outcomes.
+func arrivedShips(wCtx cardinal.WorldContext) []shipArrival {
+	var arrivals []shipArrival
 	comp.ShipIndex.Range(func(key, value interface{}) bool {
 		// Type assertion to get the actual types of key and value
 		shipId, ok1 := key.(cardinal.EntityID)
@@ -37,121 +55,254 @@ func ShipArriveSystem(wCtx cardinal.WorldContext) error {
 		if ship.TickArrive > int64(wCtx.CurrentTick()) {
 			return true
 		}
-		log.Debug().Msgf("Starting to process ship arrival for ship with planetFrom: %s, planetTo: %s, energyOnEmbark: %s", ship.LocationHashFrom, ship.LocationHashTo, utils.DecToStr(ship.EnergyOnEmbark))
+		arrivals = append(arrivals, shipArrival{id: shipId, ship: ship})
+		return true
+	})
 
-		// 1b. PRE-CONDITION: Check that the planet already exists in ECS
-		planetToEntity, ok := comp.LoadPlanetComponent(ship.LocationHashTo)
-		if ok == false {
-			log.Error().Msgf("tried to send a ship to a non-existing planet %s", ship.LocationHashTo)
-			return true
+	sort.Slice(arrivals, func(i, j int) bool {
+		a, b := arrivals[i], arrivals[j]
+		if a.ship.TickArrive != b.ship.TickArrive {
+			return a.ship.TickArrive < b.ship.TickArrive
 		}
-		planetTo := planetToEntity.Component
-		planetToId := planetToEntity.EntityId
-
-		// 1i. PRE-CONDITION: Check that the planet is claimed (has an owner)
-		if planetTo.OwnerPersonaTag != "" {
-			// Apply lazy energy refill
-			log.Debug().Msgf("Applying lazy energy refill to planet: %s", planetTo.LocationHash)
-			normalizedRefillAge := utils.NormalizedRefillAge(planetTo.LastUpdateRefillAge, planetTo.LastUpdateTick, utils.IntToDec(int(wCtx.CurrentTick())), utils.ScaleUpByTickRate(planetTo.EnergyRefill))
-			planetTo.EnergyCurrent = utils.EnergyLevel(planetTo.EnergyMax, normalizedRefillAge)
-			planetTo.LastUpdateTick = utils.IntToDec(int(wCtx.CurrentTick()))
-			planetTo.LastUpdateRefillAge = normalizedRefillAge
-			log.Debug().Msgf("Updated energy of planet with location hash %s to %s", planetTo.LocationHash, utils.DecToStr(planetTo.EnergyCurrent))
+		if a.ship.TickStart != b.ship.TickStart {
+			return a.ship.TickStart < b.ship.TickStart
 		}
+		return a.id < b.id
+	})
+	return arrivals
+}
 
-		var shipEnergyOnArrival *decimal.Big
-		if planetTo.OwnerPersonaTag == ship.OwnerPersonaTag {
-			shipEnergyOnArrival = utils.EnergyOnArrivalAtFriendlyPlanet(ship.EnergyOnEmbark)
-		} else {
-			shipEnergyOnArrival = utils.EnergyAfterDefenseDebuff(ship.EnergyOnEmbark, planetTo.Defense)
+// battle is every ship landing on a planet in one tick
+type battle struct {
+	locationHash string
+	tickArrive   int64
+	arrivals     []shipArrival
+}
+
+// groupArrivalsByBattle groups sorted arrivals by destination planet and arrival tick,
+// keeping the battles and the ships within a battle in the order of arrivals
+func groupArrivalsByBattle(arrivals []shipArrival) []*battle {
+	type battleKey struct {
+		locationHash string
+		tickArrive   int64
+	}
+	var battles []*battle
+	battleLookup := make(map[battleKey]*battle)
+	for _, arrival := range arrivals {
+		key := battleKey{arrival.ship.LocationHashTo, arrival.ship.TickArrive}
+		b, ok := battleLookup[key]
+		if !ok {
+			b = &battle{locationHash: key.locationHash, tickArrive: key.tickArrive}
+			battleLookup[key] = b
+			battles = append(battles, b)
 		}
-		log.Debug().Msgf("Ship energy after arrival: %s", utils.DecToStr(shipEnergyOnArrival))
+		b.arrivals = append(b.arrivals, arrival)
+	}
+	return battles
+}
 
-		// 1bii. PRE-CONDITION: Verify that the ship's energy is positive so it doesn't increase the planet's energy
+// battleForce is the combined energy the ships of one persona bring to a battle
+type battleForce struct {
+	ownerPersonaTag string
+	shipIds         []uint64
+	energy          *decimal.Big
+}
+
+// battleSide is the combined energy of the attacking forces of one alliance, or of a persona without alliance
+type battleSide struct {
+	energy    *decimal.Big
+	strongest *battleForce
+}
+
+// resolveBattle applies every ship of the battle to the planet at once and removes the ships.
+// The ships of the owner and its allies reinforce the planet. The other sides' ships fight
+// each other first, so only the strongest side hits the planet, with its energy minus the
+// energy of the runner-up, and the defense of the planet is applied once to that net energy.
+func (s *ScoringSystems) resolveBattle(wCtx cardinal.WorldContext, b *battle) {
+	log := wCtx.Logger()
+
+	log.Debug().Msgf("Starting to resolve the arrival of %d ship(s) at planet %s", len(b.arrivals), b.locationHash)
+
+	// 2a. PRE-CONDITION: Check that the planet already exists in ECS
+	planetToEntity, ok := comp.LoadPlanetComponent(b.locationHash)
+	if ok == false {
+		log.Error().Msgf("tried to send a ship to a non-existing planet %s", b.locationHash)
+		return
+	}
+	planetTo := planetToEntity.Component
+	planetToId := planetToEntity.EntityId
+
+	// 2b. PRE-CONDITION: Check that the planet is claimed (has an owner)
+	if planetTo.OwnerPersonaTag != "" {
+		// Apply lazy energy refill
+		log.Debug().Msgf("Applying lazy energy refill to planet: %s", planetTo.LocationHash)
+		normalizedRefillAge := utils.NormalizedRefillAge(planetTo.LastUpdateRefillAge, planetTo.LastUpdateTick, utils.IntToDec(int(wCtx.CurrentTick())), utils.ScaleUpByTickRate(planetTo.EnergyRefill))
+		planetTo.EnergyCurrent = utils.EnergyLevel(planetTo.EnergyMax, normalizedRefillAge)
+		planetTo.LastUpdateTick = utils.IntToDec(int(wCtx.CurrentTick()))
+		planetTo.LastUpdateRefillAge = normalizedRefillAge
+		log.Debug().Msgf("Updated energy of planet with location hash %s to %s", planetTo.LocationHash, utils.DecToStr(planetTo.EnergyCurrent))
+	}
+
+	// 2c. Net the energy of the ships per owner
+	var forces []*battleForce
+	forceLookup := make(map[string]*battleForce)
+	for _, arrival := range b.arrivals {
+		log.Debug().Msgf("Ship %d arrived with planetFrom: %s, energyOnEmbark: %s", arrival.id, arrival.ship.LocationHashFrom, utils.DecToStr(arrival.ship.EnergyOnEmbark))
+		force, ok := forceLookup[arrival.ship.OwnerPersonaTag]
+		if !ok {
+			force = &battleForce{ownerPersonaTag: arrival.ship.OwnerPersonaTag, energy: decimal.New(0, 0)}
+			forceLookup[arrival.ship.OwnerPersonaTag] = force
+			forces = append(forces, force)
+		}
+		force.shipIds = append(force.shipIds, uint64(arrival.id))
+		force.energy = new(decimal.Big).Add(force.energy, arrival.ship.EnergyOnEmbark)
+	}
+
+	// 2d. The ships of the owner and its allies reinforce the planet before the attack,
+	// the other forces are combined per side so that allied attackers don't fight each other
+	// PRE-CONDITION: Verify that the ships' energy is positive so it doesn't decrease the planet's energy
+	var attackers []*battleSide
+	sideLookup := make(map[string]*battleSide)
+	for _, force := range forces {
+		if !comp.AreFriendly(force.ownerPersonaTag, planetTo.OwnerPersonaTag) {
+			key := "persona:" + force.ownerPersonaTag
+			if alliance, ok := comp.LoadAllianceOf(force.ownerPersonaTag); ok {
+				key = "alliance:" + alliance.Component.AllianceName
+			}
+			side, ok := sideLookup[key]
+			if !ok {
+				side = &battleSide{energy: decimal.New(0, 0), strongest: force}
+				sideLookup[key] = side
+				attackers = append(attackers, side)
+			}
+			side.energy = new(decimal.Big).Add(side.energy, force.energy)
+			if utils.GreaterThan(force.energy, side.strongest.energy) {
+				side.strongest = force
+			}
+			continue
+		}
+		shipEnergyOnArrival := utils.EnergyOnArrivalAtFriendlyPlanet(force.energy)
 		if utils.LessThan(decimal.New(0, 0), shipEnergyOnArrival) {
-			if planetTo.OwnerPersonaTag == ship.OwnerPersonaTag {
-				// Handle the case where the planet is owned by the player
-				e := new(decimal.Big).Add(shipEnergyOnArrival, planetTo.EnergyCurrent)
+			e := new(decimal.Big).Add(shipEnergyOnArrival, planetTo.EnergyCurrent)
+
+			// Clamp the energy to the planet max energy
+			planetTo.EnergyCurrent = utils.DecMin(e, planetTo.EnergyMax)
+			log.Debug().Msgf("Ships are friendly, setting planetTo energy to %s", utils.DecToStr(planetTo.EnergyCurrent))
+		}
+	}
+
+	var report *comp.BattleReportComponent
+	var scoreMutations []game.ScoreMutation
+	if len(attackers) > 0 {
+		// 2e. The sides fight each other, the strongest one hits the planet with what is left
+		// and its strongest force conquers the planet for its side
+		sort.SliceStable(attackers, func(i, j int) bool {
+			return utils.GreaterThan(attackers[i].energy, attackers[j].energy)
+		})
+		netAttack := new(decimal.Big).Copy(attackers[0].energy)
+		attackerPersonaTag := attackers[0].strongest.ownerPersonaTag
+		if len(attackers) > 1 {
+			netAttack.Sub(netAttack, attackers[1].energy)
+			if netAttack.Sign() == 0 {
+				attackerPersonaTag = ""
+			}
+		}
 
-				// Clamp the energy to the planet max energy
-				planetTo.EnergyCurrent = utils.DecMin(e, planetTo.EnergyMax)
-				log.Debug().Msgf("Ship is friendly, setting planetTo energy to %s", utils.DecToStr(planetTo.EnergyCurrent))
+		report = &comp.BattleReportComponent{
+			LocationHash:       planetTo.LocationHash,
+			Tick:               int64(wCtx.CurrentTick()),
+			DefenderPersonaTag: planetTo.OwnerPersonaTag,
+			DefenderEnergy:     utils.DecToStr(planetTo.EnergyCurrent),
+			AttackerPersonaTag: attackerPersonaTag,
+		}
+		for _, force := range forces {
+			report.Forces = append(report.Forces, comp.BattleForce{
+				OwnerPersonaTag: force.ownerPersonaTag,
+				ShipIds:         force.shipIds,
+				Energy:          utils.DecToStr(force.energy),
+			})
+		}
+
+		// 2f. Apply the defense of the planet once to the net energy
+		shipEnergyOnArrival := utils.EnergyAfterDefenseDebuff(netAttack, planetTo.Defense)
+		log.Debug().Msgf("Net energy of the attack after arrival: %s", utils.DecToStr(shipEnergyOnArrival))
+		report.NetAttackEnergy = utils.DecToStr(shipEnergyOnArrival)
+
+		// 2fi. PRE-CONDITION: Verify that the net energy is positive so it doesn't increase the planet's energy
+		if attackerPersonaTag != "" && utils.LessThan(decimal.New(0, 0), shipEnergyOnArrival) {
+			// Check that planetTo's energy is below 0 (it's been conquered)
+			postAttackEnergy := new(decimal.Big).Sub(planetTo.EnergyCurrent, shipEnergyOnArrival)
+			log.Debug().Msgf("Attack by %s, postAttackEnergy of planetTo is %s", attackerPersonaTag, utils.DecToStr(postAttackEnergy))
 
-				err := planetTo.Set(wCtx, planetToId)
+			isConquered := utils.LessThan(postAttackEnergy, utils.IntToDec(0))
+			if isConquered {
+				previousOwner := planetTo.OwnerPersonaTag
+				planetTo.OwnerPersonaTag = attackerPersonaTag
+
+				// Reverse the application of the planet's defense before applying the remaining energy to the planet
+				reverseDefensePostAttackEnergy := new(decimal.Big).Quo(new(decimal.Big).Mul(postAttackEnergy, planetTo.Defense), utils.StrToDec("100"))
+				// Also, clamp the energy to the planet max energy
+				planetTo.EnergyCurrent = utils.DecMin(new(decimal.Big).Abs(reverseDefensePostAttackEnergy), planetTo.EnergyMax)
+
+				score, err := planetScore(planetTo)
 				if err != nil {
-					log.Error().Err(err).Msg("Failed to set planet component after friendly ship arrival")
-					return true
+					log.Error().Err(err).Msg("")
+					return
 				}
+
+				// Move the score from the player that lost the planet to the player that conquered it
+				scoreMutations = append(scoreMutations, planetScoreMutations(previousOwner, planetTo, -score)...)
+				scoreMutations = append(scoreMutations, planetScoreMutations(attackerPersonaTag, planetTo, score)...)
+				scoreMutations = append(scoreMutations, game.ScoreIncrement(game.BoardConquests, attackerPersonaTag, 1))
+
+				report.Conquered = true
+				log.Debug().Msgf("Planet %s was conquered, setting energy to %s", planetTo.LocationHash, utils.DecToStr(planetTo.EnergyCurrent))
 			} else {
-				// Handle the case where the planet is owned by another player
-				// Check that planetTo's energy is below 0 (it's been conquered)
-				postAttackEnergy := new(decimal.Big).Sub(planetTo.EnergyCurrent, shipEnergyOnArrival)
-				log.Debug().Msgf("Ship was not friendly, postAttackEnergy of planetTo is %s", utils.DecToStr(postAttackEnergy))
-
-				isConquered := utils.LessThan(postAttackEnergy, utils.IntToDec(0))
-				if isConquered {
-					previousOwner := planetTo.OwnerPersonaTag
-					planetTo.OwnerPersonaTag = ship.OwnerPersonaTag
-
-					// Reverse the application of the planet's defense before applying the remaining energy to the planet
-					reverseDefensePostAttackEnergy := new(decimal.Big).Quo(new(decimal.Big).Mul(postAttackEnergy, planetTo.Defense), utils.StrToDec("100"))
-					// Also, clamp the energy to the planet max energy
-					planetTo.EnergyCurrent = utils.DecMin(new(decimal.Big).Abs(reverseDefensePostAttackEnergy), planetTo.EnergyMax)
-
-					basePlanetScore, err := strconv.Atoi(game.BasePlanetLevelStats[int(planetTo.Level)].Score)
-					if err != nil {
-						err = fmt.Errorf("failed to convert string to int, error: %w", err)
-						log.Error().Err(err).Msg("")
-						return true
-					}
-					spaceAreaScoreMultiplier, err := strconv.Atoi(utils.GetSpaceArea(planetTo.SpaceArea).ScoreMultiplier)
-					if err != nil {
-						err = fmt.Errorf("failed to convert string to int, error: %w", err)
-						log.Error().Err(err).Msg("")
-						return true
-					}
-					score := basePlanetScore * spaceAreaScoreMultiplier
-
-					// Decrement score of player that lost the planet
-					err = game.DecrementScore(context.Background(), previousOwner, score)
-					if err != nil {
-						log.Error().Msgf("Failed to decrement score for persona tag %s: %v", previousOwner, err)
-						return true
-					}
-
-					// Increment score of player that conquered the planet
-					err = game.IncrementScore(context.Background(), ship.OwnerPersonaTag, score)
-					if err != nil {
-						log.Error().Msgf("Failed to increment score for persona tag %s: %v", ship.OwnerPersonaTag, err)
-						return true
-					}
-
-					log.Debug().Msgf("Planet %s was conquered, setting energy to %s", planetTo.LocationHash, utils.DecToStr(planetTo.EnergyCurrent))
-				} else {
-					// Handle the case where the planet is not conquered
-					planetTo.EnergyCurrent = postAttackEnergy
-					log.Debug().Msgf("Planet %s was not conquered, setting new energy to %s", planetTo.LocationHash, utils.DecToStr(planetTo.EnergyCurrent))
-				}
+				// Handle the case where the planet is not conquered
+				planetTo.EnergyCurrent = postAttackEnergy
+				log.Debug().Msgf("Planet %s was not conquered, setting new energy to %s", planetTo.LocationHash, utils.DecToStr(planetTo.EnergyCurrent))
 			}
+		}
+		report.OwnerPersonaTag = planetTo.OwnerPersonaTag
+		report.EnergyAfter = utils.DecToStr(planetTo.EnergyCurrent)
+	}
 
-			planetTo.LastUpdateRefillAge = utils.InvEnergyCurve(utils.InvEnergyCurve(utils.Saturate(new(decimal.Big).Quo(planetTo.EnergyCurrent, planetTo.EnergyMax))))
-			planetTo.LastUpdateTick = utils.IntToDec(int(wCtx.CurrentTick()))
-			log.Debug().Msgf("Updated refill age after ship landing for the receiving planet %s", planetTo.LocationHash)
+	planetTo.LastUpdateRefillAge = utils.InvEnergyCurve(utils.InvEnergyCurve(utils.Saturate(new(decimal.Big).Quo(planetTo.EnergyCurrent, planetTo.EnergyMax))))
+	planetTo.LastUpdateTick = utils.IntToDec(int(wCtx.CurrentTick()))
+	log.Debug().Msgf("Updated refill age after ship landing for the receiving planet %s", planetTo.LocationHash)
 
-			err := planetTo.Set(wCtx, planetToId)
-			if err != nil {
-				log.Error().Err(err).Msg("Error updating planet component after ship arrive refill.")
-				return true
-			}
+	err := planetTo.Set(wCtx, planetToId)
+	if err != nil {
+		log.Error().Err(err).Msg("Error updating planet component after ship arrive refill.")
+		return
+	}
+	if report != nil && report.Conquered {
+		err = countPlayerEvent(wCtx, report.OwnerPersonaTag, func(player *comp.PlayerComponent) {
+			player.Conquests++
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to count the conquest of planet %s", planetTo.LocationHash)
 		}
+	}
+	s.stage(wCtx, scoreMutations...)
 
-		// 1c. POST-CONDITION: Delete the ship
-		err := ship.Remove(wCtx, shipId)
+	// 2g. POST-CONDITION: Record the battle report
+	if report != nil {
+		reportId, err := cardinal.Create(wCtx, comp.BattleReportComponent{})
 		if err != nil {
-			return true
+			log.Error().Err(err).Msgf("Failed to create battle report for planet %s", planetTo.LocationHash)
+		} else if err = report.Set(wCtx, reportId); err != nil {
+			log.Error().Err(err).Msgf("Failed to set battle report for planet %s", planetTo.LocationHash)
+		} else {
+			log.Info().Msgf("Battle at planet %s: %+v", planetTo.LocationHash, *report)
 		}
-		return false
-	})
+	}
 
-	return nil
+	// 2h. POST-CONDITION: Delete the ships
+	for _, arrival := range b.arrivals {
+		err = arrival.ship.Remove(wCtx, arrival.id)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to remove ship %d after arrival", arrival.id)
+		}
+	}
 }
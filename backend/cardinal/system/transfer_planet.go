@@ -0,0 +1,133 @@
+package system
+
+import (
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"pkg.world.dev/world-engine/cardinal"
+	"sort"
+)
+
+// TransferPlanetSystem hands a planet to another player along with its score. Ships of the
+// previous owner heading to the planet would otherwise land as attackers, so they are handed
+// over too and reinforce the planet for its new owner. Ships that left the planet earlier
+// stay with the previous owner. Like abandoning, a player cannot give away their last planet.
+func (s *ScoringSystems) TransferPlanetSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each transfer planet transaction
+	tx.TransferPlanet.Each(wCtx, func(t cardinal.TxData[tx.TransferPlanetMsg]) (result tx.TransferPlanetReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to transfer planet %s to %s", txData.LocationHash, txData.RecipientPersonaTag)
+
+		// 1. PRE-CONDITION: Check that the LocationHash is well formatted and there is a recipient
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Check that a planet exists at the location hash
+		planetEntity, ok := comp.LoadPlanetComponent(txData.LocationHash)
+		if !ok {
+			err = fmt.Errorf("no planet exists at the following location hash %s", txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		planet := planetEntity.Component
+
+		// 2b. PRE-CONDITION: Verify that the planet is owned by the player
+		if planet.OwnerPersonaTag != txSig.PersonaTag {
+			err = fmt.Errorf("player with persona %s does not own planet with location hash %s", txSig.PersonaTag, txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. PRE-CONDITION: Verify that the recipient is another player
+		if txData.RecipientPersonaTag == txSig.PersonaTag {
+			err = fmt.Errorf("player with persona %s cannot transfer a planet to themselves", txSig.PersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		if _, ok = comp.LoadPlayerComponent(txData.RecipientPersonaTag); !ok {
+			err = fmt.Errorf("no player exists with persona %s", txData.RecipientPersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2d. PRE-CONDITION: Verify that it is not the last planet of the player
+		if countOwnedPlanets(wCtx, txSig.PersonaTag) <= 1 {
+			err = fmt.Errorf("player with persona %s cannot transfer their last planet", txSig.PersonaTag)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		score, err := planetScore(planet)
+		if err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2e. POST-CONDITION: The recipient owns the planet, the lazy refill carries on as before
+		planet.OwnerPersonaTag = txData.RecipientPersonaTag
+		err = planet.Set(wCtx, planetEntity.EntityId)
+		if err != nil {
+			err = fmt.Errorf("failed to set transferred planet with location hash %s, error: %w", txData.LocationHash, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2f. POST-CONDITION: The ships of the previous owner heading to the planet belong to the recipient
+		result.ConvertedShips = make([]tx.ShipReceipt, 0)
+		for _, shipId := range incomingShipsOf(wCtx, txData.LocationHash, txSig.PersonaTag) {
+			ship, _ := comp.LoadShipComponent(shipId)
+			ship.OwnerPersonaTag = txData.RecipientPersonaTag
+			err = ship.Set(wCtx, shipId)
+			if err != nil {
+				err = fmt.Errorf("failed to convert ship with id %d, error: %w", shipId, err)
+				log.Error().Err(err).Msg("")
+				return result, err
+			}
+			result.ConvertedShips = append(result.ConvertedShips, convertShipCompToReceipt(shipId, ship))
+		}
+
+		// 2g. POST-CONDITION: The score of the planet moves to the recipient
+		s.stage(wCtx, planetScoreMutations(txSig.PersonaTag, planet, -score)...)
+		s.stage(wCtx, planetScoreMutations(txData.RecipientPersonaTag, planet, score)...)
+
+		log.Debug().Msgf("Persona %s transferred planet %s to %s", txSig.PersonaTag, txData.LocationHash, txData.RecipientPersonaTag)
+
+		result.TransferredPlanet = convertPlanetCompToReceipt(planet)
+		result.ScoreTransferred = score
+		return result, nil
+	})
+
+	return nil
+}
+
+// incomingShipsOf returns the ids of the ships of the persona heading to the planet, in ascending order
+func incomingShipsOf(wCtx cardinal.WorldContext, locationHash string, personaTag string) []cardinal.EntityID {
+	var shipIds []cardinal.EntityID
+	comp.ShipIndex.Range(func(key, value interface{}) bool {
+		shipId, ok1 := key.(cardinal.EntityID)
+		ship, ok2 := value.(comp.ShipComponent)
+		if !ok1 || !ok2 {
+			wCtx.Logger().Info().Msg("Found incorrect type in key or value of ShipIndex sync.Map")
+			return true
+		}
+		if ship.LocationHashTo == locationHash && ship.OwnerPersonaTag == personaTag {
+			shipIds = append(shipIds, shipId)
+		}
+		return true
+	})
+	sort.Slice(shipIds, func(i, j int) bool { return shipIds[i] < shipIds[j] })
+	return shipIds
+}
@@ -0,0 +1,107 @@
+package system
+
+import (
+	"fmt"
+	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/ericlagergren/decimal"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// UpgradePlanetSystem spends energy of a planet to raise the rank of one of its stat branches
+func UpgradePlanetSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. For each upgrade planet transaction
+	tx.UpgradePlanet.Each(wCtx, func(t cardinal.TxData[tx.UpgradePlanetMsg]) (result tx.UpgradePlanetReply, err error) {
+		txData := t.Msg()
+		txSig := t.Tx()
+
+		log.Debug().Msgf("Received payload to upgrade the %s of planet: %s", txData.Branch, txData.LocationHash)
+
+		// 1. PRE-CONDITION: Check that the LocationHash is well formatted and the branch exists
+		if err = txData.Validate(); err != nil {
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2a. PRE-CONDITION: Check that a planet exists at the location hash
+		planetEntity, ok := comp.LoadPlanetComponent(txData.LocationHash)
+		if !ok {
+			err = fmt.Errorf("no planet exists at the following location hash %s", txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+		planet := planetEntity.Component
+
+		// 2b. PRE-CONDITION: Verify that the planet is owned by the player
+		if planet.OwnerPersonaTag != txSig.PersonaTag {
+			err = fmt.Errorf("player with persona %s does not own planet with location hash %s", txSig.PersonaTag, txData.LocationHash)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2c. PRE-CONDITION: Verify that the branch is below the maximum rank of the planet level
+		var rank *int64
+		switch txData.Branch {
+		case tx.UpgradeRange:
+			rank = &planet.RangeRank
+		case tx.UpgradeSpeed:
+			rank = &planet.SpeedRank
+		case tx.UpgradeDefense:
+			rank = &planet.DefenseRank
+		}
+		maxRank := game.PlanetUpgradeConstants.MaxRank[planet.Level]
+		if *rank >= maxRank {
+			err = fmt.Errorf("%s of planet with location hash %s is already at the maximum rank %d of level %d", txData.Branch, txData.LocationHash, maxRank, planet.Level)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// Lazy energy refill
+		log.Debug().Msgf("Applying lazy energy refill to planet: %s", planet.LocationHash)
+		normalizedRefillAge := utils.NormalizedRefillAge(planet.LastUpdateRefillAge, planet.LastUpdateTick, utils.IntToDec(int(wCtx.CurrentTick())), utils.ScaleUpByTickRate(planet.EnergyRefill))
+		planet.EnergyCurrent = utils.EnergyLevel(planet.EnergyMax, normalizedRefillAge)
+		planet.LastUpdateTick = utils.IntToDec(int(wCtx.CurrentTick()))
+		planet.LastUpdateRefillAge = normalizedRefillAge
+
+		// 2d. PRE-CONDITION: Verify that the planet has enough energy to pay for the upgrade
+		energyCost := new(decimal.Big).Mul(planet.EnergyMax, utils.StrToDec(game.PlanetUpgradeConstants.EnergyCost))
+		if utils.LessThan(planet.EnergyCurrent, energyCost) {
+			err = fmt.Errorf("planet with location hash %s has %s energy, the upgrade costs %s", txData.LocationHash, utils.DecToStr(planet.EnergyCurrent), utils.DecToStr(energyCost))
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		// 2e. POST-CONDITION: Raise the rank and recompute the stats of the planet
+		*rank++
+		stats := utils.GetSpaceAdjustedPlanetStats(*game.BasePlanetLevelStats[planet.Level], *game.SpaceConstants[planet.SpaceArea-1])
+		setPlanetStats(&planet, stats)
+
+		planet.EnergyCurrent = new(decimal.Big).Sub(planet.EnergyCurrent, energyCost)
+		planet.LastUpdateRefillAge = utils.InvEnergyCurve(utils.InvEnergyCurve(utils.Saturate(new(decimal.Big).Quo(planet.EnergyCurrent, planet.EnergyMax))))
+		err = planet.Set(wCtx, planetEntity.EntityId)
+		if err != nil {
+			err = fmt.Errorf("failed to set upgraded planet with location hash %s, error: %w", txData.LocationHash, err)
+			log.Error().Err(err).Msg("")
+			return result, err
+		}
+
+		log.Debug().Msgf("Persona %s upgraded the %s of planet %s to rank %d", txSig.PersonaTag, txData.Branch, txData.LocationHash, *rank)
+
+		result.Planet = convertPlanetCompToReceipt(planet)
+		result.EnergySpent = utils.DecToStr(energyCost)
+		return result, nil
+	})
+
+	return nil
+}
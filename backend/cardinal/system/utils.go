@@ -5,6 +5,7 @@ import (
 	"fmt"
 	comp "github.com/argus-labs/darkfrontier-backend/cardinal/component"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/keys"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
 	"pkg.world.dev/world-engine/cardinal"
@@ -21,6 +22,19 @@ func checkTimer(wCtx cardinal.WorldContext) error {
 	return nil
 }
 
+// rebuildIndexesOnce rebuilds the indexes on the first tick of the world, see RebuildIndex
+func rebuildIndexesOnce(wCtx cardinal.WorldContext) error {
+	if !RebuildIndex {
+		return nil
+	}
+	err := rebuildDefaultsAndComponentIndexes(wCtx)
+	if err != nil {
+		return err
+	}
+	RebuildIndex = false
+	return nil
+}
+
 func rebuildDefaultsAndComponentIndexes(wCtx cardinal.WorldContext) error {
 	err := comp.RebuildPlanetIndex(wCtx)
 	if err != nil {
@@ -37,6 +51,21 @@ func rebuildDefaultsAndComponentIndexes(wCtx cardinal.WorldContext) error {
 		return fmt.Errorf("failed to rebuild ship index: %w", err)
 	}
 
+	err = comp.RebuildProofNullifierIndex(wCtx)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild proof nullifier index: %w", err)
+	}
+
+	err = comp.RebuildBattleReportIndex(wCtx)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild battle report index: %w", err)
+	}
+
+	err = comp.RebuildAllianceIndex(wCtx)
+	if err != nil {
+		return fmt.Errorf("failed to rebuild alliance index: %w", err)
+	}
+
 	dc, err := comp.LoadDefaultsComponent(wCtx)
 	if err != nil {
 		wCtx.Logger().Info().Msg("DefaultsComponent did not exist, building now")
@@ -47,6 +76,14 @@ func rebuildDefaultsAndComponentIndexes(wCtx cardinal.WorldContext) error {
 			return fmt.Errorf("failed to build and set DefaultsComponent %w", err)
 		}
 		wCtx.Logger().Info().Msg("Successfully built and set DefaultsComponent")
+	} else {
+		// The stored constants replaced the ones cardinal was started and checked with
+		if err = game.WorldConstants.CheckSpacePerlinThresholds(); err != nil {
+			wCtx.Logger().Error().Err(err).Msg("stored world constants are invalid")
+		}
+		if err = keys.CheckWorldConstants(game.WorldConstants); err != nil {
+			wCtx.Logger().Error().Err(err).Msg("stored world constants don't match the circuit artifacts")
+		}
 	}
 	wCtx.Logger().Info().Msg("Successfully rebuilt all defaults and component indexes.")
 	return nil
@@ -66,10 +103,73 @@ func convertPlanetReceiptToComp(pr tx.PlanetReceipt) comp.PlanetComponent {
 		LastUpdateRefillAge: utils.StrToDec(pr.LastUpdateRefillAge),
 		LastUpdateTick:      utils.StrToDec(pr.LastUpdateTick),
 		SpaceArea:           pr.SpaceArea,
+		RangeRank:           pr.RangeRank,
+		SpeedRank:           pr.SpeedRank,
+		DefenseRank:         pr.DefenseRank,
 	}
 	return newPlanet
 }
 
+func convertShipCompToReceipt(id cardinal.EntityID, ship comp.ShipComponent) tx.ShipReceipt {
+	return tx.ShipReceipt{
+		Id:               uint64(id),
+		OwnerPersonaTag:  ship.OwnerPersonaTag,
+		LocationHashFrom: ship.LocationHashFrom,
+		LocationHashTo:   ship.LocationHashTo,
+		TickStart:        ship.TickStart,
+		TickArrive:       ship.TickArrive,
+		EnergyOnEmbark:   utils.DecToStr(ship.EnergyOnEmbark),
+		Recalled:         ship.Recalled,
+	}
+}
+
+func convertPlanetCompToReceipt(planet comp.PlanetComponent) tx.PlanetReceipt {
+	return tx.PlanetReceipt{
+		Level:               planet.Level,
+		LocationHash:        planet.LocationHash,
+		OwnerPersonaTag:     planet.OwnerPersonaTag,
+		EnergyCurrent:       utils.DecToStr(planet.EnergyCurrent),
+		EnergyMax:           utils.DecToStr(planet.EnergyMax),
+		EnergyRefill:        utils.DecToStr(planet.EnergyRefill),
+		Defense:             utils.DecToStr(planet.Defense),
+		Range:               utils.DecToStr(planet.Range),
+		Speed:               utils.DecToStr(planet.Speed),
+		LastUpdateRefillAge: utils.DecToStr(planet.LastUpdateRefillAge),
+		LastUpdateTick:      utils.DecToStr(planet.LastUpdateTick),
+		SpaceArea:           planet.SpaceArea,
+		RangeRank:           planet.RangeRank,
+		SpeedRank:           planet.SpeedRank,
+		DefenseRank:         planet.DefenseRank,
+	}
+}
+
+// planetScore is the leaderboard score a player holds for owning the planet, the base score
+// of its level times the score multiplier of its space area
+func planetScore(planet comp.PlanetComponent) (int, error) {
+	basePlanetScore, err := strconv.Atoi(game.BasePlanetLevelStats[int(planet.Level)].Score)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert string to int, error: %w", err)
+	}
+	// SpaceArea is 1-based, see utils.SpaceAreaToInt
+	if planet.SpaceArea < 1 || planet.SpaceArea > int64(len(game.SpaceConstants)) {
+		return 0, fmt.Errorf("planet with location hash %s has an invalid space area %d", planet.LocationHash, planet.SpaceArea)
+	}
+	spaceAreaScoreMultiplier, err := strconv.Atoi(game.SpaceConstants[planet.SpaceArea-1].ScoreMultiplier)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert string to int, error: %w", err)
+	}
+	return basePlanetScore * spaceAreaScoreMultiplier, nil
+}
+
+// setPlanetStats sets the level and space area adjusted stats of the planet, raised by its upgrades
+func setPlanetStats(planet *comp.PlanetComponent, stats *game.PlanetLevelStats) {
+	planet.EnergyMax = utils.StrToDec(stats.EnergyMax)
+	planet.EnergyRefill = utils.StrToDec(stats.EnergyRefill)
+	planet.Defense = utils.UpgradedStat(utils.StrToDec(stats.Defense), planet.DefenseRank)
+	planet.Speed = utils.UpgradedStat(utils.StrToDec(stats.Speed), planet.SpeedRank)
+	planet.Range = utils.UpgradedStat(utils.StrToDec(stats.Range), planet.RangeRank)
+}
+
 func convertShipReceiptToComp(sr tx.ShipReceipt) comp.ShipComponent {
 	newShipComp := comp.ShipComponent{
 		OwnerPersonaTag:  sr.OwnerPersonaTag,
@@ -78,6 +178,7 @@ func convertShipReceiptToComp(sr tx.ShipReceipt) comp.ShipComponent {
 		TickStart:        sr.TickStart,
 		TickArrive:       sr.TickArrive,
 		EnergyOnEmbark:   utils.StrToDec(sr.EnergyOnEmbark),
+		Recalled:         sr.Recalled,
 	}
 	return newShipComp
 }
@@ -100,12 +201,8 @@ func findAndUpdatePlanetsBySpaceArea(wCtx cardinal.WorldContext, spaceArea int64
 			// Get new stats based on new space constants
 			newStats := utils.GetSpaceAdjustedPlanetStats(*game.BasePlanetLevelStats[planetEntity.Component.Level], newSpaceConstants)
 
-			// Update the planet component with new stats
-			planetEntity.Component.EnergyMax = utils.StrToDec(newStats.EnergyMax)
-			planetEntity.Component.EnergyRefill = utils.StrToDec(newStats.EnergyRefill)
-			planetEntity.Component.Defense = utils.StrToDec(newStats.Defense)
-			planetEntity.Component.Speed = utils.StrToDec(newStats.Speed)
-			planetEntity.Component.Range = utils.StrToDec(newStats.Range)
+			// Update the planet component with new stats, keeping its upgrades
+			setPlanetStats(&planetEntity.Component, newStats)
 
 			planetEntity.Component.Set(wCtx, planetEntity.EntityId)
 		}
@@ -142,12 +239,8 @@ func findAndUpdatePlanetsByLevel(wCtx cardinal.WorldContext, level int64, msg Le
 			// Get new stats based on new level constants
 			newStats := utils.GetSpaceAdjustedPlanetStats(newLevelConstants, *game.SpaceConstants[planetEntity.Component.SpaceArea-1])
 
-			// Update the planet component with new stats
-			planetEntity.Component.EnergyMax = utils.StrToDec(newStats.EnergyMax)
-			planetEntity.Component.EnergyRefill = utils.StrToDec(newStats.EnergyRefill)
-			planetEntity.Component.Defense = utils.StrToDec(newStats.Defense)
-			planetEntity.Component.Speed = utils.StrToDec(newStats.Speed)
-			planetEntity.Component.Range = utils.StrToDec(newStats.Range)
+			// Update the planet component with new stats, keeping its upgrades
+			setPlanetStats(&planetEntity.Component, newStats)
 
 			planetEntity.Component.Set(wCtx, planetEntity.EntityId)
 		}
@@ -184,3 +277,17 @@ func extractLevelNumber(constantName string) (int64, error) {
 
 	return 0, errors.New("constant name does not follow the expected format")
 }
+
+// nullifyProof records the digest of an accepted proof so that it cannot be submitted again
+func nullifyProof(wCtx cardinal.WorldContext, digest string) error {
+	id, err := cardinal.Create(wCtx, comp.ProofNullifierComponent{})
+	if err != nil {
+		return fmt.Errorf("failed to create proof nullifier with id %d: %w", id, err)
+	}
+
+	nullifier := comp.ProofNullifierComponent{
+		Digest: digest,
+		Tick:   wCtx.CurrentTick(),
+	}
+	return nullifier.Set(wCtx, id)
+}
@@ -0,0 +1,140 @@
+package system
+
+import (
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/consensys/gnark/backend/witness"
+	"pkg.world.dev/world-engine/cardinal"
+	"runtime"
+	"sync"
+)
+
+// ProofVerificationWorkers bounds the number of proofs verified at the same time
+var ProofVerificationWorkers = runtime.NumCPU()
+
+// proofResult is the outcome of verifying the proof of a single transaction
+type proofResult struct {
+	publicWitness witness.Witness
+	witnessErr    error
+	verifyErr     error
+}
+
+// proofResults maps tx hashes of the current tick to their proofResult
+var proofResults sync.Map
+
+// VerifyProofsSystem verifies the proofs of every pending claim-home-planet, send-energy and
+// reveal-location transaction of the tick concurrently. It must run before ClaimHomePlanetSystem,
+// SendEnergySystem and RevealLocationSystem, which then only look up the results. Verification has no side effects,
+// so the order in which transactions are applied stays the order of the tx queue.
+func VerifyProofsSystem(wCtx cardinal.WorldContext) error {
+	log := wCtx.Logger()
+
+	// 1. Check that the game timer is not over, if it is, exit
+	err := checkTimer(wCtx)
+	if err != nil {
+		log.Debug().Msg(err.Error())
+		return nil
+	}
+
+	// 2. Drop results of transactions that were never consumed
+	proofResults.Range(func(key, _ any) bool {
+		proofResults.Delete(key)
+		return true
+	})
+
+	// 3. Collect the proofs of this tick
+	claims := tx.ClaimHomePlanet.In(wCtx)
+	sends := tx.SendEnergy.In(wCtx)
+	reveals := tx.RevealLocation.In(wCtx)
+	total := len(claims) + len(sends) + len(reveals)
+	if total == 0 {
+		return nil
+	}
+
+	hashes := make([]any, 0, total)
+	jobs := make([]func() proofResult, 0, total)
+	for _, claim := range claims {
+		msg := claim.Msg()
+		hashes = append(hashes, claim.Hash())
+		jobs = append(jobs, func() proofResult { return verifyInitProof(msg) })
+	}
+	for _, send := range sends {
+		msg := send.Msg()
+		hashes = append(hashes, send.Hash())
+		jobs = append(jobs, func() proofResult { return verifyMoveProof(msg) })
+	}
+	for _, reveal := range reveals {
+		msg := reveal.Msg()
+		hashes = append(hashes, reveal.Hash())
+		jobs = append(jobs, func() proofResult { return verifyRevealProof(msg) })
+	}
+
+	// 4. Verify them with a bounded worker pool
+	results := runVerificationJobs(jobs, ProofVerificationWorkers)
+	for i, result := range results {
+		proofResults.Store(hashes[i], result)
+	}
+	log.Debug().Msgf("Verified %d proofs", len(results))
+	return nil
+}
+
+// runVerificationJobs runs the jobs on at most workers goroutines, results[i] is the result of jobs[i]
+func runVerificationJobs(jobs []func() proofResult, workers int) []proofResult {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	results := make([]proofResult, len(jobs))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range queue {
+				results[i] = jobs[i]()
+			}
+		}()
+	}
+	for i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	return results
+}
+
+// loadProofResult returns the precomputed result for the transaction, verifying it
+// inline if VerifyProofsSystem did not run for it
+func loadProofResult(hash any, verify func() proofResult) proofResult {
+	if result, ok := proofResults.LoadAndDelete(hash); ok {
+		return result.(proofResult)
+	}
+	return verify()
+}
+
+func verifyInitProof(msg tx.ClaimHomePlanetMsg) proofResult {
+	publicWitness, err := msg.CreatePublicWitness()
+	if err != nil {
+		return proofResult{witnessErr: err}
+	}
+	return proofResult{publicWitness: publicWitness, verifyErr: msg.VerifyInitProof(publicWitness)}
+}
+
+func verifyMoveProof(msg tx.SendEnergyMsg) proofResult {
+	publicWitness, err := msg.CreatePublicWitness()
+	if err != nil {
+		return proofResult{witnessErr: err}
+	}
+	return proofResult{publicWitness: publicWitness, verifyErr: msg.VerifyMoveProof(publicWitness)}
+}
+
+func verifyRevealProof(msg tx.RevealLocationMsg) proofResult {
+	publicWitness, err := msg.CreatePublicWitness()
+	if err != nil {
+		return proofResult{witnessErr: err}
+	}
+	return proofResult{publicWitness: publicWitness, verifyErr: msg.VerifyRevealProof(publicWitness)}
+}
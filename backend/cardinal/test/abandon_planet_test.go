@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/ericlagergren/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// 1) Verify that a player can abandon a planet, losing its score and part of its energy
+func TestAbandonPlanet(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "AbandonPlayer1"
+	game.WorldConstants.AbandonPenalty = "0.5"
+	defer func() { game.WorldConstants.AbandonPenalty = "0" }()
+
+	// 1) Player1 owns two planets
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	_, _, err = CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+	assert.NoError(t, err)
+	// The leaderboard is derived from the owned planets on the first tick
+	doTick()
+	_, scoreBefore, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+
+	// 2) Abandon one of them
+	abandonTick := world.CurrentTick()
+	AbandonPlanet(world, tx.AbandonPlanetMsg{LocationHash: planet.LocationHash}, player1)
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(abandonTick)
+	assert.Equal(t, 1, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	reply, ok := receipts[0].Result.(tx.AbandonPlanetReply)
+	assert.True(t, ok)
+
+	// 3) The planet has no owner and kept half of its energy
+	planetEntity, ok := component.LoadPlanetComponent(planet.LocationHash)
+	assert.True(t, ok)
+	expectedPlanet := planet
+	RefillEnergyWithAsIs(&expectedPlanet, int64(abandonTick))
+	expectedEnergy := new(decimal.Big).Sub(expectedPlanet.EnergyCurrent, new(decimal.Big).Mul(expectedPlanet.EnergyCurrent, utils.StrToDec("0.5")))
+	assert.Equal(t, "", planetEntity.Component.OwnerPersonaTag)
+	assert.Equal(t, utils.DecToStr(expectedEnergy), utils.DecToStr(planetEntity.Component.EnergyCurrent))
+	assert.Equal(t, "", reply.AbandonedPlanet.OwnerPersonaTag)
+	assert.Equal(t, planet.LocationHash, reply.AbandonedPlanet.LocationHash)
+	assert.Equal(t, utils.DecToStr(expectedEnergy), reply.AbandonedPlanet.EnergyCurrent)
+
+	// 4) The score of the planet was taken from the player once the tick was committed
+	assert.Greater(t, reply.ScoreRemoved, 0)
+	doTick()
+	_, score, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, scoreBefore-float64(reply.ScoreRemoved), score)
+
+	// 5) The player cannot abandon the planet again
+	abandonTick = world.CurrentTick()
+	AbandonPlanet(world, tx.AbandonPlanetMsg{LocationHash: planet.LocationHash}, player1)
+	doTick()
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(abandonTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("player with persona %s does not own planet with location hash %s", player1, planet.LocationHash), receipts[0].Errs[0].Error())
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 2) Verify that a player cannot abandon their last planet
+func TestCannotAbandonLastPlanet(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "AbandonPlayer1"
+
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+
+	abandonTick := world.CurrentTick()
+	AbandonPlanet(world, tx.AbandonPlanetMsg{LocationHash: planet.LocationHash}, player1)
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(abandonTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("player with persona %s cannot abandon their last planet", player1), receipts[0].Errs[0].Error())
+
+	planetEntity, ok := component.LoadPlanetComponent(planet.LocationHash)
+	assert.True(t, ok)
+	assert.Equal(t, player1, planetEntity.Component.OwnerPersonaTag)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/query"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/stretchr/testify/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// 1) Verify that players can create, join and leave an alliance and that the queries follow along
+func TestAllianceLifecycle(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	leader := "AlliancePlayer1"
+	member := "AlliancePlayer2"
+	outsider := "AlliancePlayer3"
+	name := "Andromeda"
+
+	_, _, err := CreatePlayerWithClaimedPlanet(world, leader, "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+	_, _, err = CreatePlayerWithClaimedPlanet(world, member, "0x2", levelZeroPlanetTwo.LocationHash, levelZeroPlanetTwo.Perlin)
+	assert.NoError(t, err)
+	_, _, err = CreatePlayerWithClaimedPlanet(world, outsider, "0x3", outOfRange.LocationHash, outOfRange.Perlin)
+	assert.NoError(t, err)
+
+	// 1) The leader creates the alliance and invites the member
+	CreateAlliance(world, tx.CreateAllianceMsg{Name: name}, leader)
+	doTick()
+	inviteTick := world.CurrentTick()
+	InviteToAlliance(world, tx.InviteToAllianceMsg{PersonaTag: member}, leader)
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(inviteTick)
+	assert.Equal(t, 1, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	reply, ok := receipts[0].Result.(tx.AllianceReply)
+	assert.True(t, ok)
+	assert.Equal(t, []string{leader}, reply.Alliance.Members)
+	assert.Equal(t, []string{member}, reply.Alliance.Invites)
+
+	// 2) Only invited players can join
+	acceptTick := world.CurrentTick()
+	AcceptAllianceInvite(world, tx.AcceptAllianceInviteMsg{Name: name}, member)
+	AcceptAllianceInvite(world, tx.AcceptAllianceInviteMsg{Name: name}, outsider)
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(acceptTick)
+	assert.Equal(t, 2, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	assert.Equal(t, 1, len(receipts[1].Errs))
+	assert.Equal(t, fmt.Sprintf("player with persona %s is not invited to alliance %s", outsider, name), receipts[1].Errs[0].Error())
+
+	// 3) The roster can be looked up by name or by member, and the planets of both members are listed
+	roster, err := query.Alliance(wCtx, &query.AllianceMsg{PersonaTag: member})
+	assert.NoError(t, err)
+	assert.True(t, roster.Found)
+	assert.Equal(t, name, roster.Alliance.AllianceName)
+	assert.Equal(t, leader, roster.Alliance.Leader)
+	assert.Equal(t, []string{leader, member}, roster.Alliance.Members)
+	assert.Equal(t, 0, len(roster.Alliance.Invites))
+	assert.True(t, component.AreFriendly(leader, member))
+	assert.False(t, component.AreFriendly(leader, outsider))
+
+	planets, err := query.AlliancePlanets(wCtx, &query.AlliancePlanetsMsg{Name: name})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(planets.Planets))
+	for _, planet := range planets.Planets {
+		assert.Contains(t, []string{leader, member}, planet.OwnerPersonaTag)
+	}
+
+	// 4) The member takes over the lead when the leader leaves, the alliance is gone when the member leaves too
+	LeaveAlliance(world, leader)
+	doTick()
+	roster, err = query.Alliance(wCtx, &query.AllianceMsg{Name: name})
+	assert.NoError(t, err)
+	assert.Equal(t, member, roster.Alliance.Leader)
+	assert.Equal(t, []string{member}, roster.Alliance.Members)
+	assert.False(t, component.AreFriendly(leader, member))
+
+	LeaveAlliance(world, member)
+	doTick()
+	roster, err = query.Alliance(wCtx, &query.AllianceMsg{Name: name})
+	assert.NoError(t, err)
+	assert.False(t, roster.Found)
+	_, err = query.AlliancePlanets(wCtx, &query.AlliancePlanetsMsg{PersonaTag: member})
+	assert.Error(t, err)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 2) Verify that only the leader can kick members
+func TestKickFromAlliance(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	leader := "AlliancePlayer1"
+	member := "AlliancePlayer2"
+	name := "Andromeda"
+
+	_, _, err := CreatePlayerWithClaimedPlanet(world, leader, "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+	_, _, err = CreatePlayerWithClaimedPlanet(world, member, "0x2", levelZeroPlanetTwo.LocationHash, levelZeroPlanetTwo.Perlin)
+	assert.NoError(t, err)
+
+	CreateAlliance(world, tx.CreateAllianceMsg{Name: name}, leader)
+	doTick()
+	InviteToAlliance(world, tx.InviteToAllianceMsg{PersonaTag: member}, leader)
+	doTick()
+	AcceptAllianceInvite(world, tx.AcceptAllianceInviteMsg{Name: name}, member)
+	doTick()
+
+	// 1) The member cannot kick the leader
+	kickTick := world.CurrentTick()
+	KickFromAlliance(world, tx.KickFromAllianceMsg{PersonaTag: leader}, member)
+	doTick()
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(kickTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("player with persona %s is not the leader of alliance %s", member, name), receipts[0].Errs[0].Error())
+
+	// 2) The leader kicks the member
+	kickTick = world.CurrentTick()
+	KickFromAlliance(world, tx.KickFromAllianceMsg{PersonaTag: member}, leader)
+	doTick()
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(kickTick)
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	reply, ok := receipts[0].Result.(tx.AllianceReply)
+	assert.True(t, ok)
+	assert.Equal(t, []string{leader}, reply.Alliance.Members)
+	_, ok = component.LoadAllianceOf(member)
+	assert.False(t, ok)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 3) Verify that ships landing on the planet of an ally reinforce it instead of attacking it
+func TestAlliedShipsReinforce(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	player1 := "AlliancePlayer1"
+	player2 := "AlliancePlayer2"
+	name := "Andromeda"
+
+	// 1) Player1 and Player2 are allies, Player1 owns a planet next to a planet of Player2
+	_, _, err := CreatePlayerWithClaimedPlanet(world, player1, "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+	_, _, err = CreatePlayerWithClaimedPlanet(world, player2, "0x2", levelZeroPlanetTwo.LocationHash, levelZeroPlanetTwo.Perlin)
+	assert.NoError(t, err)
+	_, planetFrom, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+	assert.NoError(t, err)
+	_, planetTo, err := CreatePlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player2)
+	assert.NoError(t, err)
+
+	CreateAlliance(world, tx.CreateAllianceMsg{Name: name}, player1)
+	doTick()
+	InviteToAlliance(world, tx.InviteToAllianceMsg{PersonaTag: player2}, player1)
+	doTick()
+	AcceptAllianceInvite(world, tx.AcceptAllianceInviteMsg{Name: name}, player2)
+	doTick()
+
+	// 2) Player1 sends energy to the planet of Player2
+	distance := 11 // distance between the two planets is ~10.6
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanetTwo.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok2)
+	proof, err := getProofForMoveCircuit(t, move.MoveCircuit{
+		X1:      levelTwoPlanetTwo.X,
+		Y1:      levelTwoPlanetTwo.Y,
+		X2:      levelTwoPlanet.X,
+		Y2:      levelTwoPlanet.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.Itoa(distance),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelTwoPlanet.Perlin, 10),
+		Nonce:   0,
+	})
+	assert.NoError(t, err)
+	SendEnergy(world, tx.SendEnergyMsg{
+		LocationHashFrom: planetFrom.LocationHash,
+		LocationHashTo:   planetTo.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelTwoPlanet.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(distance),
+		Proof:            proof,
+	}, player1)
+
+	energySendTick := world.CurrentTick()
+	energyArrivalTick := utils.ShipArrivalTick(utils.IntToDec(distance), utils.ScaleDownByTickRate(planetFrom.Speed), int64(energySendTick))
+	for i := int64(0); i <= energyArrivalTick-int64(energySendTick); i++ {
+		doTick()
+	}
+
+	// 3) No battle was fought, the planet still belongs to Player2 and the ship was applied as a reinforcement
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(energySendTick)
+	assert.Equal(t, 1, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+
+	reports, err := query.BattleReports(wCtx, &query.BattleReportsMsg{PlanetsList: []string{planetTo.LocationHash}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(reports.Reports))
+
+	planetToQueried, err := GetPlanetByLocationHash(wCtx, planetTo.LocationHash)
+	assert.NoError(t, err)
+	assert.Equal(t, player2, planetToQueried.OwnerPersonaTag)
+	assert.True(t, utils.GreaterThan(planetToQueried.EnergyCurrent, planetTo.EnergyCurrent))
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/keys"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
 	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
 	"github.com/stretchr/testify/assert"
@@ -70,6 +72,7 @@ func TestPlayerWithPlanetCannotClaim(t *testing.T) {
 	transaction := tx.ClaimHomePlanetMsg{
 		LocationHash: levelZeroPlanetTwo.LocationHash,
 		Perlin:       levelZeroPlanetTwo.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
 		Proof:        proof,
 	}
 
@@ -123,6 +126,7 @@ func TestClaimedPlanetCannotBeClaimed(t *testing.T) {
 	transaction := tx.ClaimHomePlanetMsg{
 		LocationHash: levelZeroPlanet.LocationHash,
 		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
 		Proof:        proof,
 	}
 
@@ -182,3 +186,320 @@ func TestPlayerIsOwnerOfClaimedPlanet(t *testing.T) {
 	err := world.ShutDown()
 	assert.NoError(t, err)
 }
+
+// The proof nullifier of a claim is bound to the persona that signed it, a pending claim submitted
+// again under another persona doesn't use up the proof of the original one
+func TestClaimProofDigestIsBoundToPersona(t *testing.T) {
+	transaction := tx.ClaimHomePlanetMsg{
+		LocationHash: levelZeroPlanet.LocationHash,
+		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
+	}
+	publicWitness, err := transaction.CreatePublicWitness()
+	assert.NoError(t, err)
+
+	digest, err := transaction.ProofDigest("Player1", publicWitness)
+	assert.NoError(t, err)
+	sameDigest, err := transaction.ProofDigest("Player1", publicWitness)
+	assert.NoError(t, err)
+	otherDigest, err := transaction.ProofDigest("Player2", publicWitness)
+	assert.NoError(t, err)
+
+	assert.Equal(t, digest, sameDigest)
+	assert.NotEqual(t, digest, otherDigest)
+}
+
+// The radius the proof was generated for cannot exceed RadiusMax
+func TestCannotClaimWithRadiusAboveRadiusMax(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	err := QueuePersonaTx(world, "Player1", "0x1")
+	assert.NoError(t, err)
+
+	// 1) Generate a valid proof for a radius larger than RadiusMax
+	radius := game.WorldConstants.RadiusMax + 100
+	pub, ok := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok)
+	initAssignment := initialize.InitCircuit{
+		X:       levelZeroPlanet.X,
+		Y:       levelZeroPlanet.Y,
+		R:       strconv.FormatInt(radius, 10),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub:     pub,
+		Perl:    strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+	}
+	proof, err := getProofForInitCircuit(t, initAssignment)
+	assert.NoError(t, err)
+
+	// 2) Send transaction
+	transaction := tx.ClaimHomePlanetMsg{
+		LocationHash: levelZeroPlanet.LocationHash,
+		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       radius,
+		Proof:        proof,
+	}
+
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.ClaimHomePlanet.AddToQueue(world, transaction, &signedPayload)
+
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) Check that the claim was rejected because of the radius
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, txHash, receipts[0].TxHash)
+	assert.Equal(t, fmt.Sprintf("radius %d exceeds the maximum radius %d", radius, game.WorldConstants.RadiusMax), receipts[0].Errs[0].Error())
+
+	_, ok = component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.False(t, ok)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// The circuit only constrains the square of the radius, so a negative radius is rejected before verification
+func TestCannotClaimWithNegativeRadius(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	err := QueuePersonaTx(world, "Player1", "0x1")
+	assert.NoError(t, err)
+
+	// 1) Generate a valid proof for a negative radius whose square exceeds RadiusMax^2
+	radius := -game.WorldConstants.RadiusMax * 10
+	pub, ok := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok)
+	initAssignment := initialize.InitCircuit{
+		X:       levelZeroPlanet.X,
+		Y:       levelZeroPlanet.Y,
+		R:       strconv.FormatInt(radius, 10),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub:     pub,
+		Perl:    strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+	}
+	proof, err := getProofForInitCircuit(t, initAssignment)
+	assert.NoError(t, err)
+
+	// 2) Send transaction
+	transaction := tx.ClaimHomePlanetMsg{
+		LocationHash: levelZeroPlanet.LocationHash,
+		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       radius,
+		Proof:        proof,
+	}
+
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.ClaimHomePlanet.AddToQueue(world, transaction, &signedPayload)
+
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) Check that the claim was rejected because of the radius
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, txHash, receipts[0].TxHash)
+	assert.Equal(t, fmt.Sprintf("radius %d must be positive", radius), receipts[0].Errs[0].Error())
+
+	_, ok = component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.False(t, ok)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// Proofs generated with an older artifact version are only accepted once an admin accepts that version
+func TestClaimWithOlderCircuitArtifactVersion(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	defer func() { game.WorldConstants.AcceptedCircuitArtifactUUIDs = []string{} }()
+
+	err := QueuePersonaTx(world, "Player1", "0x1")
+	assert.NoError(t, err)
+	err = QueuePersonaTx(world, "admin", "0xd5e099c71b797516c10ed0f0d895f429c2781142")
+	assert.NoError(t, err)
+
+	// 1) Register the test keys under a second artifact version
+	olderVersion := "00000000-0000-4000-8000-000000000000"
+	vks, ok := keys.Load(game.WorldConstants.CircuitArtifactUUID)
+	assert.True(t, ok)
+	keys.Register(olderVersion, vks)
+
+	// 2) Generate a proof and claim with the older version
+	pub, ok := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok)
+	initAssignment := initialize.InitCircuit{
+		X:       levelZeroPlanet.X,
+		Y:       levelZeroPlanet.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub:     pub,
+		Perl:    strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+	}
+	proof, err := getProofForInitCircuit(t, initAssignment)
+	assert.NoError(t, err)
+
+	transaction := tx.ClaimHomePlanetMsg{
+		LocationHash:    levelZeroPlanet.LocationHash,
+		Perlin:          levelZeroPlanet.Perlin,
+		Radius:          game.WorldConstants.RadiusMax,
+		Proof:           proof,
+		ArtifactVersion: olderVersion,
+	}
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.ClaimHomePlanet.AddToQueue(world, transaction, &signedPayload)
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) The version is not accepted yet
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, txHash, receipts[0].TxHash)
+	assert.Equal(t, fmt.Sprintf("error with verifying circuit for planet with location hash %s: circuit artifact version %s is not accepted", levelZeroPlanet.LocationHash, olderVersion), receipts[0].Errs[0].Error())
+
+	// 4) Accept the older version and claim again with a fresh proof
+	SetConstant(world, tx.SetConstantMsg{
+		ConstantName: "AcceptedCircuitArtifactUUIDs",
+		Value:        []any{olderVersion}, // Send as a list of any to simulate JSON
+	}, "admin")
+	doTick()
+	assert.Equal(t, []string{olderVersion}, game.WorldConstants.AcceptedCircuitArtifactUUIDs)
+
+	transaction.Proof, err = getProofForInitCircuit(t, initAssignment)
+	assert.NoError(t, err)
+	txHash = tx.ClaimHomePlanet.AddToQueue(world, transaction, &signedPayload)
+	sentTick = world.CurrentTick()
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, txHash, receipts[0].TxHash)
+	assert.Equal(t, 0, len(receipts[0].Errs))
+
+	_, ok = component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.True(t, ok)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// Artifact versions compiled with other circuit keys than the world constants are neither used nor accepted
+func TestCannotUseCircuitArtifactsOfOtherKeys(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	defer func() { game.WorldConstants.AcceptedCircuitArtifactUUIDs = []string{} }()
+	currentVersion := game.WorldConstants.CircuitArtifactUUID
+
+	err := QueuePersonaTx(world, "admin", "0xd5e099c71b797516c10ed0f0d895f429c2781142")
+	assert.NoError(t, err)
+
+	// 1) Register the test keys under a version compiled with other perlin buckets
+	otherVersion := "00000000-0000-4000-8000-000000000001"
+	vks, ok := keys.Load(currentVersion)
+	assert.True(t, ok)
+	vks.CircuitKeys.PerlinProfile.Buckets = 10
+	keys.Register(otherVersion, vks)
+
+	// 2) Try to switch to the version and to accept it
+	SetConstant(world, tx.SetConstantMsg{
+		ConstantName: "CircuitArtifactUUID",
+		Value:        otherVersion,
+	}, "admin")
+	SetConstant(world, tx.SetConstantMsg{
+		ConstantName: "AcceptedCircuitArtifactUUIDs",
+		Value:        []any{otherVersion}, // Send as a list of any to simulate JSON
+	}, "admin")
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) Both are rejected
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, 2, len(receipts))
+	for _, receipt := range receipts {
+		assert.Equal(t, 1, len(receipt.Errs))
+		assert.Contains(t, receipt.Errs[0].Error(), fmt.Sprintf("circuit artifact version %s: circuits were compiled with", otherVersion))
+	}
+	assert.Equal(t, currentVersion, game.WorldConstants.CircuitArtifactUUID)
+	assert.Equal(t, []string{}, game.WorldConstants.AcceptedCircuitArtifactUUIDs)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// In rim-spawn mode home planets can only be claimed with proofs of the rim-spawn init circuit
+func TestRimSpawnRejectsRegularInitProof(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	defer func() { game.WorldConstants.RimSpawn = false }()
+
+	err := QueuePersonaTx(world, "Player1", "0x1")
+	assert.NoError(t, err)
+	err = QueuePersonaTx(world, "admin", "0xd5e099c71b797516c10ed0f0d895f429c2781142")
+	assert.NoError(t, err)
+
+	// 1) Enable rim spawn
+	SetConstant(world, tx.SetConstantMsg{
+		ConstantName: "RimSpawn",
+		Value:        true,
+	}, "admin")
+	doTick()
+	assert.True(t, game.WorldConstants.RimSpawn)
+
+	// 2) Claim with a proof of the regular init circuit
+	pub, ok := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok)
+	initAssignment := initialize.InitCircuit{
+		X:       levelZeroPlanet.X,
+		Y:       levelZeroPlanet.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub:     pub,
+		Perl:    strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+	}
+	proof, err := getProofForInitCircuit(t, initAssignment)
+	assert.NoError(t, err)
+
+	transaction := tx.ClaimHomePlanetMsg{
+		LocationHash: levelZeroPlanet.LocationHash,
+		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
+		Proof:        proof,
+	}
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.ClaimHomePlanet.AddToQueue(world, transaction, &signedPayload)
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) The proof does not verify against the rim-spawn verifying key
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, txHash, receipts[0].TxHash)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	_, ok = component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.False(t, ok)
+
+	// 4) Proofs for a radius smaller than RadiusMax are rejected before verification
+	transaction.Radius = game.WorldConstants.RadiusMax - 1
+	txHash = tx.ClaimHomePlanet.AddToQueue(world, transaction, &signedPayload)
+	sentTick = world.CurrentTick()
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, txHash, receipts[0].TxHash)
+	assert.Equal(t, fmt.Sprintf("radius %d must be the maximum radius %d when spawning in the rim", transaction.Radius, game.WorldConstants.RadiusMax), receipts[0].Errs[0].Error())
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -84,6 +84,46 @@ func TestGetSpaceArea(t *testing.T) {
 	}
 }
 
+func TestCheckSpacePerlinThresholds(t *testing.T) {
+	// The default thresholds split the 16 buckets, perlin values in [0, 32], around the middle
+	assert.NilError(t, game.WorldConstants.CheckSpacePerlinThresholds())
+
+	testCases := []struct {
+		name       string
+		thresholds []int64
+		buckets    int64
+	}{
+		{
+			name:       "Too few thresholds",
+			thresholds: []int64{15},
+			buckets:    16,
+		},
+		{
+			name:       "Empty nebula",
+			thresholds: []int64{0, 17},
+			buckets:    16,
+		},
+		{
+			name:       "Descending thresholds",
+			thresholds: []int64{17, 15},
+			buckets:    16,
+		},
+		{
+			name:       "Thresholds beyond the buckets",
+			thresholds: []int64{15, 17},
+			buckets:    8,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			constants := game.WorldConstants
+			constants.SpacePerlinThresholds = tc.thresholds
+			constants.PerlinBuckets = tc.buckets
+			assert.ErrorContains(t, constants.CheckSpacePerlinThresholds(), "SpacePerlinThresholds must")
+		})
+	}
+}
+
 func TestSaturate(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -14,10 +14,12 @@ type NewPlanetInfo struct {
 }
 
 var (
-	InitProvingKey = groth16.NewProvingKey(ecc.BN254)
-	InitCCS        = groth16.NewCS(ecc.BN254)
-	MoveProvingKey = groth16.NewProvingKey(ecc.BN254)
-	MoveCCS        = groth16.NewCS(ecc.BN254)
+	InitProvingKey   = groth16.NewProvingKey(ecc.BN254)
+	InitCCS          = groth16.NewCS(ecc.BN254)
+	MoveProvingKey   = groth16.NewProvingKey(ecc.BN254)
+	MoveCCS          = groth16.NewCS(ecc.BN254)
+	RevealProvingKey = groth16.NewProvingKey(ecc.BN254)
+	RevealCCS        = groth16.NewCS(ecc.BN254)
 
 	startRange = NewPlanetInfo{
 		Level:        "2",
@@ -64,6 +64,7 @@ func TestCannotClaimPlanetAfterTimerIsPast(t *testing.T) {
 	transaction := tx.ClaimHomePlanetMsg{
 		LocationHash: levelZeroPlanet.LocationHash,
 		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
 		Proof:        proof,
 	}
 
@@ -116,6 +117,7 @@ func TestIndexesRebuildAfterShutdown(t *testing.T) {
 	transaction := tx.ClaimHomePlanetMsg{
 		LocationHash: levelZeroPlanet.LocationHash,
 		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
 		Proof:        proof,
 	}
 
@@ -7,6 +7,7 @@ import (
 	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
 	"github.com/argus-labs/darkfrontier-backend/circuit/move"
 	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/backend/groth16"
@@ -54,3 +55,17 @@ func getProofForMoveCircuit(t *testing.T, assignment move.MoveCircuit) (proof st
 	proof = base64.StdEncoding.EncodeToString(proofBuf.Bytes())
 	return proof, nil
 }
+
+func getProofForRevealCircuit(t *testing.T, assignment reveal.RevealCircuit) (proof string, err error) {
+	fullWitness, err := frontend.NewWitness(&assignment, ecc.BN254.ScalarField())
+	assert.NoError(t, err)
+
+	tempProof, err := groth16.Prove(RevealCCS, RevealProvingKey, fullWitness)
+	assert.NoError(t, err)
+
+	proofBuf := bytes.Buffer{}
+	_, err = tempProof.WriteRawTo(&proofBuf)
+	assert.NoError(t, err)
+	proof = base64.StdEncoding.EncodeToString(proofBuf.Bytes())
+	return proof, nil
+}
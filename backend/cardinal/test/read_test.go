@@ -46,6 +46,7 @@ func TestReadPlanetsClaimingHomePlanet(t *testing.T) {
 	transaction := tx.ClaimHomePlanetMsg{
 		LocationHash: levelZeroPlanet.LocationHash,
 		Perlin:       levelZeroPlanet.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
 		Proof:        proof,
 	}
 
@@ -113,6 +114,7 @@ func TestReadPlanetsWithEnergyTransfer(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -177,19 +179,30 @@ func TestReadAfterSettingConstants(t *testing.T) {
 		ConstantName: "InstanceName",
 		Value:        "NewInstanceName",
 	}
+	instanceName := game.WorldConstants.InstanceName
 	SetConstant(world, setRadiusTx, persona)
 	SetConstant(world, setTimerTx, persona)
 	SetConstant(world, setInstanceNameTx, persona)
+	sentTick := world.CurrentTick()
 
 	doTick()
 
+	// The instance name namespaces the leaderboards, it can't be changed at runtime
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	var errs []error
+	for _, receipt := range receipts {
+		errs = append(errs, receipt.Errs...)
+	}
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "InstanceName can only be set with CARDINAL_NAMESPACE when cardinal starts", errs[0].Error())
+
 	req := query.ConstantMsg{ConstantLabel: "world"}
 	reply, err := query.Constants(wCtx, &req)
 
 	constants := reply.Constants.(*game.WorldConstant)
 	assert.Equal(t, int64(3000), constants.RadiusMax)
 	assert.Equal(t, 10, constants.InstanceTimer)
-	assert.Equal(t, "NewInstanceName", constants.InstanceName)
+	assert.Equal(t, instanceName, constants.InstanceName)
 	assert.NoError(t, err)
 
 	err = world.ShutDown()
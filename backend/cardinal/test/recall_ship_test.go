@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/query"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/ericlagergren/decimal"
+	"github.com/stretchr/testify/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// Sends a ship from levelTwoPlanet of player1 to levelTwoPlanetTwo of player2 and returns its receipt
+func sendShipToEnemyPlanet(t *testing.T, world *cardinal.World, doTick func(), player1 string, player2 string) tx.ShipReceipt {
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	_, toPlanet, err := CreatePlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player2)
+	assert.NoError(t, err)
+
+	distance := 11 // distance between the two planets is ~10.6
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelTwoPlanetTwo.LocationHash, 16)
+	assert.True(t, ok2)
+	proof, err := getProofForMoveCircuit(t, move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelTwoPlanetTwo.X,
+		Y2:      levelTwoPlanetTwo.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.Itoa(distance),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
+	})
+	assert.NoError(t, err)
+
+	SendEnergy(world, tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelTwoPlanetTwo.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(distance),
+		Proof:            proof,
+	}, player1)
+	energySendTick := world.CurrentTick()
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(energySendTick)
+	assert.Equal(t, 1, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	reply, ok := receipts[0].Result.(tx.SendEnergyReply)
+	assert.True(t, ok)
+	return reply.SentShip
+}
+
+// 1) Verify that a recalled ship flies back to its origin planet with less energy
+func TestRecallShip(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	player1 := "Player1"
+	player2 := "Player2"
+
+	// 1) Player1 attacks the planet of Player2
+	sentShip := sendShipToEnemyPlanet(t, world, doTick, player1, player2)
+	for i := 0; i < 10; i++ {
+		doTick()
+	}
+	assert.Less(t, int64(world.CurrentTick()), sentShip.TickArrive)
+
+	// 2) Turn the ship around
+	recallTick := world.CurrentTick()
+	RecallShip(world, tx.RecallShipMsg{ShipId: sentShip.Id}, player1)
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(recallTick)
+	assert.Equal(t, 1, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	reply, ok := receipts[0].Result.(tx.RecallShipReply)
+	assert.True(t, ok)
+
+	returnedEnergy := utils.EnergyAfterRecall(utils.StrToDec(sentShip.EnergyOnEmbark), utils.StrToDec(game.WorldConstants.RecallPenalty))
+	assert.Equal(t, tx.ShipReceipt{
+		Id:               sentShip.Id,
+		OwnerPersonaTag:  player1,
+		LocationHashFrom: levelTwoPlanetTwo.LocationHash,
+		LocationHashTo:   levelTwoPlanet.LocationHash,
+		TickStart:        int64(recallTick),
+		TickArrive:       int64(recallTick) + (int64(recallTick) - sentShip.TickStart),
+		EnergyOnEmbark:   utils.DecToStr(returnedEnergy),
+		Recalled:         true,
+	}, reply.RecalledShip)
+
+	// 3) The planets query shows the ship travelling back
+	planets, err := query.Planets(wCtx, &query.PlanetsMsg{PlanetsList: []string{levelTwoPlanet.LocationHash}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(planets.Planets))
+	assert.Equal(t, 1, len(planets.Planets[0].EnergyTransfers))
+	transfer := planets.Planets[0].EnergyTransfers[0]
+	assert.Equal(t, sentShip.Id, transfer.TransferId)
+	assert.Equal(t, levelTwoPlanetTwo.LocationHash, transfer.PlanetFromHash)
+	assert.Equal(t, levelTwoPlanet.LocationHash, transfer.PlanetToHash)
+	assert.Equal(t, utils.DecToStr(returnedEnergy), transfer.EnergyOnEmbark)
+	assert.True(t, transfer.Recalled)
+
+	// 4) Run the world until the ship is back and check that its energy landed on the origin planet
+	planetFromEntity, ok := component.LoadPlanetComponent(levelTwoPlanet.LocationHash)
+	assert.True(t, ok)
+	expectedFrom := planetFromEntity.Component
+	RefillEnergyWithAsIs(&expectedFrom, reply.RecalledShip.TickArrive)
+	expectedEnergy := utils.DecMin(new(decimal.Big).Add(expectedFrom.EnergyCurrent, returnedEnergy), expectedFrom.EnergyMax)
+
+	for int64(world.CurrentTick()) <= reply.RecalledShip.TickArrive {
+		doTick()
+	}
+
+	_, ok = component.LoadShipComponent(cardinal.EntityID(sentShip.Id))
+	assert.False(t, ok)
+	planetFrom, err := GetPlanetByLocationHash(wCtx, levelTwoPlanet.LocationHash)
+	assert.NoError(t, err)
+	assert.Equal(t, player1, planetFrom.OwnerPersonaTag)
+	assert.Equal(t, utils.DecToStr(expectedEnergy), utils.DecToStr(planetFrom.EnergyCurrent))
+	planetTo, err := GetPlanetByLocationHash(wCtx, levelTwoPlanetTwo.LocationHash)
+	assert.NoError(t, err)
+	assert.Equal(t, player2, planetTo.OwnerPersonaTag)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 2) Verify that only the owner can recall a ship, and only once
+func TestRecallShipPreconditions(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "Player1"
+	player2 := "Player2"
+
+	sentShip := sendShipToEnemyPlanet(t, world, doTick, player1, player2)
+
+	// 1) Player2 cannot recall the ship of Player1
+	recallTick := world.CurrentTick()
+	RecallShip(world, tx.RecallShipMsg{ShipId: sentShip.Id}, player2)
+	doTick()
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(recallTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("ship with id %d is not owned by %s", sentShip.Id, player2), receipts[0].Errs[0].Error())
+
+	// 2) Player1 can recall it once
+	RecallShip(world, tx.RecallShipMsg{ShipId: sentShip.Id}, player1)
+	doTick()
+	recallTick = world.CurrentTick()
+	RecallShip(world, tx.RecallShipMsg{ShipId: sentShip.Id}, player1)
+	doTick()
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(recallTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("ship with id %d has already been recalled", sentShip.Id), receipts[0].Errs[0].Error())
+
+	// 3) Ships that don't exist cannot be recalled
+	recallTick = world.CurrentTick()
+	RecallShip(world, tx.RecallShipMsg{ShipId: sentShip.Id + 1000}, player1)
+	doTick()
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(recallTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("no ship exists with id %d", sentShip.Id+1000), receipts[0].Errs[0].Error())
+
+	err := world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/query"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"pkg.world.dev/world-engine/cardinal"
+	"pkg.world.dev/world-engine/sign"
+	"strconv"
+	"testing"
+)
+
+func createRevealLocationMsg(t *testing.T, planet NewPlanetInfo) tx.RevealLocationMsg {
+	pub, ok := new(big.Int).SetString(planet.LocationHash, 16)
+	assert.True(t, ok)
+	x, err := strconv.ParseInt(planet.X, 10, 64)
+	assert.NoError(t, err)
+	y, err := strconv.ParseInt(planet.Y, 10, 64)
+	assert.NoError(t, err)
+
+	proof, err := getProofForRevealCircuit(t, reveal.RevealCircuit{X: x, Y: y, Pub: pub})
+	assert.NoError(t, err)
+
+	return tx.RevealLocationMsg{
+		LocationHash: planet.LocationHash,
+		X:            x,
+		Y:            y,
+		Proof:        proof,
+	}
+}
+
+func RevealLocation(world *cardinal.World, transaction tx.RevealLocationMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.RevealLocation.AddToQueue(world, transaction, &signedPayload)
+}
+
+// Anyone who knows the coordinates of a planet can reveal them
+func TestRevealLocation(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+
+	// 1) Claim a planet as "Player1"
+	_, _, err := CreatePlayerWithClaimedPlanet(world, "Player1", "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+
+	// 2) Reveal it as "Player2"
+	RevealLocation(world, createRevealLocationMsg(t, levelZeroPlanet), "Player2")
+	doTick()
+
+	// 3) Check that the coordinates are stored on the planet
+	planetEntity, ok := component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.True(t, ok)
+	assert.True(t, planetEntity.Component.Revealed)
+	assert.Equal(t, int64(0), planetEntity.Component.RevealedX)
+	assert.Equal(t, int64(22), planetEntity.Component.RevealedY)
+	assert.Equal(t, "Player2", planetEntity.Component.RevealedBy)
+
+	// 4) Check that the planet is listed by the revealed-planets query
+	reply, err := query.RevealedPlanets(wCtx, &query.RevealedPlanetsMsg{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reply.Planets))
+	assert.Equal(t, levelZeroPlanet.LocationHash, reply.Planets[0].LocationHash)
+	assert.Equal(t, int64(22), reply.Planets[0].Y)
+	assert.Equal(t, "Player1", reply.Planets[0].OwnerPersonaTag)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// A proof for other coordinates does not reveal the planet
+func TestRevealLocationWithWrongCoordinates(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	// 1) Claim a planet as "Player1"
+	_, _, err := CreatePlayerWithClaimedPlanet(world, "Player1", "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+
+	// 2) Claim coordinates that don't hash to the location hash
+	transaction := createRevealLocationMsg(t, levelZeroPlanet)
+	transaction.X++
+	RevealLocation(world, transaction, "Player1")
+	doTick()
+
+	planetEntity, ok := component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.True(t, ok)
+	assert.False(t, planetEntity.Component.Revealed)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// Only planets that exist can be revealed
+func TestRevealLocationOfUnknownPlanet(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+
+	// 1) Reveal a location nobody has claimed
+	RevealLocation(world, createRevealLocationMsg(t, levelZeroPlanetTwo), "Player1")
+	doTick()
+
+	_, ok := component.LoadPlanetComponent(levelZeroPlanetTwo.LocationHash)
+	assert.False(t, ok)
+	reply, err := query.RevealedPlanets(wCtx, &query.RevealedPlanetsMsg{})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(reply.Planets))
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
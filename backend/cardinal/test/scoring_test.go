@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/query"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/stretchr/testify/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// Score of a planet: the base score of its level times the score multiplier of its space area
+func expectedPlanetScore(t *testing.T, planet component.PlanetComponent) int {
+	base, err := strconv.Atoi(game.BasePlanetLevelStats[planet.Level].Score)
+	assert.NoError(t, err)
+	multiplier, err := strconv.Atoi(game.SpaceConstants[planet.SpaceArea-1].ScoreMultiplier)
+	assert.NoError(t, err)
+	return base * multiplier
+}
+
+// 1) Verify that the leaderboard is derived from the owned planets on startup and on every interval
+func TestLeaderboardIsReconciledWithPlanets(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "ScoringPlayer1"
+	ghost := "ScoringGhost"
+
+	// 1) Player1 owns its home planet and a level two planet, the leaderboard disagrees
+	_, _, err := CreatePlayerWithClaimedPlanet(world, player1, "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	homePlanet, ok := component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.True(t, ok)
+	err = testLeaderboard.AddPlayer(context.Background(), game.Player{PersonaTag: player1, Score: 5})
+	assert.NoError(t, err)
+	err = testLeaderboard.AddPlayer(context.Background(), game.Player{PersonaTag: ghost, Score: 100})
+	assert.NoError(t, err)
+
+	// 2) The scores are fixed on startup
+	doTick()
+	expected := expectedPlanetScore(t, homePlanet.Component) + expectedPlanetScore(t, planet)
+	_, score, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(expected), score)
+	_, score, err = testLeaderboard.GetPlayerRankAndScore(context.Background(), ghost)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), score)
+
+	// 3) Drift is only reported when fixing is disabled, and fixed on the next interval once it is enabled again
+	game.WorldConstants.ScoreReconcileInterval = 3
+	game.WorldConstants.ScoreReconcileFix = false
+	defer func() {
+		game.WorldConstants.ScoreReconcileInterval = 600
+		game.WorldConstants.ScoreReconcileFix = true
+	}()
+	err = testLeaderboard.SetScore(context.Background(), player1, 1)
+	assert.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		doTick()
+	}
+	_, score, err = testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), score)
+
+	game.WorldConstants.ScoreReconcileFix = true
+	for i := 0; i < 3; i++ {
+		doTick()
+	}
+	_, score, err = testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(expected), score)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 2) Verify that the scores of a tick only reach the leaderboard once the tick is over
+func TestLeaderboardIsCommittedAfterTheTick(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "ScoringPlayer1"
+
+	// 1) Player1 owns a planet, the leaderboard is derived from it on the first tick
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	doTick()
+	_, scoreBefore, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(expectedPlanetScore(t, planet)), scoreBefore)
+
+	// 2) Abandoning the planet doesn't change the leaderboard during the tick
+	AbandonPlanet(world, tx.AbandonPlanetMsg{LocationHash: planet.LocationHash}, player1)
+	doTick()
+	_, score, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, scoreBefore, score)
+
+	// 3) The score is removed when the next tick starts, and only once
+	doTick()
+	doTick()
+	_, score, err = testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), score)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 3) Verify that the leaderboard is snapshotted on every interval and that the history can be queried
+func TestLeaderboardSnapshots(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	leaderboardQueries := query.NewLeaderboardQueries(testLeaderboards, testLeaderboardHistory)
+	player1 := "ScoringPlayer1"
+	game.WorldConstants.LeaderboardSnapshotInterval = 2
+	defer func() { game.WorldConstants.LeaderboardSnapshotInterval = 120 }()
+
+	// 1) Player1 owns a planet, the leaderboard is derived from it on the first tick
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	doTick()
+	doTick()
+	doTick()
+	planetScore := expectedPlanetScore(t, planet)
+
+	// 2) Player1 abandons the planet, its score is gone from the next snapshots
+	AbandonPlanet(world, tx.AbandonPlanetMsg{LocationHash: planet.LocationHash}, player1)
+	for i := 0; i < 4; i++ {
+		doTick()
+	}
+
+	// 3) The timeline follows the score of the player from snapshot to snapshot
+	timeline, err := leaderboardQueries.PlayerTimeline(wCtx, &query.PlayerTimelineMsg{PersonaTag: player1})
+	assert.NoError(t, err)
+	assert.Equal(t, []game.TimelinePoint{
+		{Tick: 2, Rank: 1, Score: planetScore},
+		{Tick: 4, Rank: 1, Score: 0},
+		{Tick: 6, Rank: 1, Score: 0},
+	}, timeline.Timeline)
+
+	// 4) The top players of a past snapshot can be read, the latest snapshot by default
+	snapshot, err := leaderboardQueries.LeaderboardSnapshot(wCtx, &query.LeaderboardSnapshotMsg{Tick: 2, Top: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), snapshot.Tick)
+	assert.Equal(t, []game.RankedPlayer{{Player: game.Player{PersonaTag: player1, Score: planetScore}, Rank: 1}}, snapshot.Players)
+	assert.Equal(t, []uint64{2, 4, 6}, snapshot.Ticks)
+
+	snapshot, err = leaderboardQueries.LeaderboardSnapshot(wCtx, &query.LeaderboardSnapshotMsg{Top: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), snapshot.Tick)
+
+	_, err = leaderboardQueries.LeaderboardSnapshot(wCtx, &query.LeaderboardSnapshotMsg{Tick: 3, Top: 10})
+	assert.ErrorIs(t, err, game.ErrSnapshotNotFound)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 4) Verify that the space area and alliance leaderboards follow the planets and the alliance members
+func TestNamedLeaderboards(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	leaderboardQueries := query.NewLeaderboardQueries(testLeaderboards, testLeaderboardHistory)
+	player1 := "ScoringPlayer1"
+	player2 := "ScoringPlayer2"
+	name := "Andromeda"
+
+	// 1) Player1 owns its home planet and a level two planet, Player2 its home planet
+	_, _, err := CreatePlayerWithClaimedPlanet(world, player1, "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+	_, _, err = CreatePlayerWithClaimedPlanet(world, player2, "0x2", levelZeroPlanetTwo.LocationHash, levelZeroPlanetTwo.Perlin)
+	assert.NoError(t, err)
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	homePlanet1, ok := component.LoadPlanetComponent(levelZeroPlanet.LocationHash)
+	assert.True(t, ok)
+	homePlanet2, ok := component.LoadPlanetComponent(levelZeroPlanetTwo.LocationHash)
+	assert.True(t, ok)
+	score1 := expectedPlanetScore(t, homePlanet1.Component) + expectedPlanetScore(t, planet)
+	score2 := expectedPlanetScore(t, homePlanet2.Component)
+	doTick()
+
+	// 2) The space area leaderboards split the score of Player1 by the area of its planets
+	areaScores := make(map[string]int)
+	for _, p := range []component.PlanetComponent{homePlanet1.Component, planet} {
+		areaScores[game.SpaceAreaScoreBoards[p.SpaceArea-1]] += expectedPlanetScore(t, p)
+	}
+	for board, expected := range areaScores {
+		reply, err := leaderboardQueries.Leaderboard(wCtx, &query.LeaderboardMsg{Board: board, Start: 0, End: 10, PersonaTag: player1})
+		assert.NoError(t, err)
+		assert.NotNil(t, reply.Player)
+		assert.Equal(t, expected, reply.Player.Score)
+	}
+
+	// 3) The alliance ranks with the scores of its members once the tick was committed
+	CreateAlliance(world, tx.CreateAllianceMsg{Name: name}, player1)
+	doTick()
+	InviteToAlliance(world, tx.InviteToAllianceMsg{PersonaTag: player2}, player1)
+	doTick()
+	AcceptAllianceInvite(world, tx.AcceptAllianceInviteMsg{Name: name}, player2)
+	doTick()
+	doTick()
+
+	reply, err := leaderboardQueries.Leaderboard(wCtx, &query.LeaderboardMsg{Board: game.BoardAlliances, Start: 0, End: 10, PersonaTag: player2})
+	assert.NoError(t, err)
+	assert.Equal(t, []game.RankedPlayer{{Player: game.Player{PersonaTag: name, Score: score1 + score2}, Rank: 1}}, reply.Players)
+	assert.Equal(t, &game.RankedPlayer{Player: game.Player{PersonaTag: name, Score: score1 + score2}, Rank: 1}, reply.Player)
+
+	// 4) Members take their score along when they leave, the alliance is off the leaderboard once it is disbanded
+	LeaveAlliance(world, player1)
+	doTick()
+	doTick()
+	reply, err = leaderboardQueries.Leaderboard(wCtx, &query.LeaderboardMsg{Board: game.BoardAlliances, Start: 0, End: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []game.RankedPlayer{{Player: game.Player{PersonaTag: name, Score: score2}, Rank: 1}}, reply.Players)
+
+	LeaveAlliance(world, player2)
+	doTick()
+	doTick()
+	reply, err = leaderboardQueries.Leaderboard(wCtx, &query.LeaderboardMsg{Board: game.BoardAlliances, Start: 0, End: 10, PersonaTag: player2})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(reply.Players))
+	assert.Nil(t, reply.Player)
+
+	// 5) Unknown leaderboards are reported
+	_, err = leaderboardQueries.Leaderboard(wCtx, &query.LeaderboardMsg{Board: "unknown", Start: 0, End: 10})
+	assert.ErrorIs(t, err, game.ErrBoardNotFound)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 5) Verify that the leaderboards counting events are rebuilt from the counters of the players,
+// e.g. after the staged scores of a tick were lost on a restart
+func TestEventLeaderboardsAreReconciledWithPlayers(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	leaderboardQueries := query.NewLeaderboardQueries(testLeaderboards, testLeaderboardHistory)
+	player1 := "ScoringPlayer1"
+
+	// 1) Player1 conquered two planets and sent 300 energy, the leaderboards only hold part of it
+	id, player, err := CreatePlayerWithClaimedPlanet(world, player1, "0x1", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+	player.Conquests = 2
+	player.EnergySent = 300
+	err = player.Set(wCtx, id)
+	assert.NoError(t, err)
+	energySent, err := testLeaderboards.Board(game.BoardEnergySent)
+	assert.NoError(t, err)
+	err = energySent.AddPlayer(context.Background(), game.Player{PersonaTag: player1, Score: 100})
+	assert.NoError(t, err)
+
+	// 2) Both leaderboards are fixed on startup
+	doTick()
+	for board, expected := range map[string]int{game.BoardConquests: 2, game.BoardEnergySent: 300} {
+		reply, err := leaderboardQueries.Leaderboard(wCtx, &query.LeaderboardMsg{Board: board, Start: 0, End: 10, PersonaTag: player1})
+		assert.NoError(t, err)
+		assert.Equal(t, []game.RankedPlayer{{Player: game.Player{PersonaTag: player1, Score: expected}, Rank: 1}}, reply.Players)
+	}
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
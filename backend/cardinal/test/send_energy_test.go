@@ -47,6 +47,7 @@ func TestCannotSendFromPlanetNotOwned(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanet.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -109,6 +110,7 @@ func TestCannotSendMoreEnergyThanCurrent(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanet.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -164,13 +166,14 @@ func TestSendSmallEnergyToLargeDistancePlanet(t *testing.T) {
 		X2:      levelZeroPlanet.X,
 		Y2:      levelZeroPlanet.Y,
 		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
-		DistMax: "500", // I don't have the exact value for this, but this dist should be far enough to make the test pass
+		DistMax: "26", // distance between the two planets is ~25.2, within the range of the level two planet
 		Scale:   strconv.Itoa(game.WorldConstants.Scale),
 		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
 		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -182,6 +185,69 @@ func TestSendSmallEnergyToLargeDistancePlanet(t *testing.T) {
 		Energy:           1,
 		PerlinTo:         levelZeroPlanet.Perlin,
 		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      26,
+		Proof:            proof,
+	}
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.SendEnergy.AddToQueue(world, transaction, &signedPayload)
+
+	// 2c) Run tick so that the ship can be processed
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) Assert that the correct error was thrown in the system
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, eris.Errorf("destination planet with hash %s is out of range, %d energy does not cover the travel cost over distance %d", levelZeroPlanet.LocationHash, 1, 26).Error(), receipts[0].Errs[0].Error())
+	assert.Equal(t, txHash, receipts[0].TxHash)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 4b) Verify that ships cannot be sent further than the range of the origin planet
+func TestCannotSendFurtherThanPlanetRange(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	// 1) Create two planets for sending and receiving respectively
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, "Player1")
+	assert.NoError(t, err)
+	_, toPlanet, err := CreatePlanetByLocationHash(world, levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin, "Player2")
+	assert.NoError(t, err)
+
+	// 2) Send energy from non-owned planet to other planet
+	// 2a) Generate proof for the transaction
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok2)
+	moveAssignment := move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelZeroPlanet.X,
+		Y2:      levelZeroPlanet.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: "500",
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+		Nonce:   0,
+	}
+	proof, err := getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+
+	// 2b) Send transaction to Cardinal
+	transaction := tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelZeroPlanet.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
 		MaxDistance:      500,
 		Proof:            proof,
 	}
@@ -196,9 +262,206 @@ func TestSendSmallEnergyToLargeDistancePlanet(t *testing.T) {
 
 	// 3) Assert that the correct error was thrown in the system
 	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
-	assert.Equal(t, eris.Errorf("ship did not have enough energy to arrive at enemy planet").Error(), receipts[0].Errs[0].Error())
+	assert.Equal(t, eris.Errorf("max distance %d exceeds the range %s of origin planet with hash %s", 500, utils.DecToStr(fromPlanet.Range), levelTwoPlanet.LocationHash).Error(), receipts[0].Errs[0].Error())
+	assert.Equal(t, txHash, receipts[0].TxHash)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 4c) Verify that the radius the proof was generated for cannot exceed RadiusMax
+func TestCannotSendWithRadiusAboveRadiusMax(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	// 1) Create two planets for sending and receiving respectively
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, "Player1")
+	assert.NoError(t, err)
+	_, toPlanet, err := CreatePlanetByLocationHash(world, levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin, "Player2")
+	assert.NoError(t, err)
+
+	// 2) Send energy from non-owned planet to other planet
+	// 2a) Generate proof for the transaction
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok2)
+	moveAssignment := move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelZeroPlanet.X,
+		Y2:      levelZeroPlanet.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax+100, 10),
+		DistMax: "26",
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+		Nonce:   0,
+	}
+	proof, err := getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+
+	// 2b) Send transaction to Cardinal
+	transaction := tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelZeroPlanet.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax + 100,
+		MaxDistance:      26,
+		Proof:            proof,
+	}
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.SendEnergy.AddToQueue(world, transaction, &signedPayload)
+
+	// 2c) Run tick so that the ship can be processed
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) Assert that the correct error was thrown in the system
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, eris.Errorf("radius %d exceeds the maximum radius %d", game.WorldConstants.RadiusMax+100, game.WorldConstants.RadiusMax).Error(), receipts[0].Errs[0].Error())
+	assert.Equal(t, txHash, receipts[0].TxHash)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 4d) Verify that a negative radius is rejected, the circuit only constrains its square
+func TestCannotSendWithNegativeRadius(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	// 1) Create two planets for sending and receiving respectively
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, "Player1")
+	assert.NoError(t, err)
+	_, toPlanet, err := CreatePlanetByLocationHash(world, levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin, "Player2")
+	assert.NoError(t, err)
+
+	// 2) Send energy with a valid proof for a negative radius
+	// 2a) Generate proof for the transaction
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok2)
+	moveAssignment := move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelZeroPlanet.X,
+		Y2:      levelZeroPlanet.Y,
+		R:       strconv.FormatInt(-game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.FormatInt(int64(26), 10),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+		Nonce:   0,
+	}
+	proof, err := getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+
+	// 2b) Send transaction to Cardinal
+	transaction := tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelZeroPlanet.Perlin,
+		RadiusTo:         -game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(26),
+		Proof:            proof,
+	}
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.SendEnergy.AddToQueue(world, transaction, &signedPayload)
+
+	// 2c) Run tick so that the ship can be processed
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) Assert that the send was rejected and that no energy left the origin planet
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, eris.Errorf("radius %d must be positive", -game.WorldConstants.RadiusMax).Error(), receipts[0].Errs[0].Error())
+	assert.Equal(t, txHash, receipts[0].TxHash)
+
+	planetEntity, ok := component.LoadPlanetComponent(fromPlanet.LocationHash)
+	assert.True(t, ok)
+	assert.True(t, utils.Equal(fromPlanet.EnergyCurrent, planetEntity.Component.EnergyCurrent))
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 4e) Verify that a negative max distance is rejected, the circuit only constrains its square
+func TestCannotSendWithNegativeMaxDistance(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	// 1) Create two planets for sending and receiving respectively
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, "Player1")
+	assert.NoError(t, err)
+	_, toPlanet, err := CreatePlanetByLocationHash(world, levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin, "Player2")
+	assert.NoError(t, err)
+
+	// 2) Send energy with a valid proof for a negative max distance
+	// 2a) Generate proof for the transaction
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelZeroPlanet.LocationHash, 16)
+	assert.True(t, ok2)
+	moveAssignment := move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelZeroPlanet.X,
+		Y2:      levelZeroPlanet.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.FormatInt(int64(-1000), 10),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelZeroPlanet.Perlin, 10),
+		Nonce:   0,
+	}
+	proof, err := getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+
+	// 2b) Send transaction to Cardinal
+	transaction := tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelZeroPlanet.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(-1000),
+		Proof:            proof,
+	}
+	signedPayload := sign.Transaction{
+		PersonaTag: "Player1",
+	}
+	txHash := tx.SendEnergy.AddToQueue(world, transaction, &signedPayload)
+
+	// 2c) Run tick so that the ship can be processed
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 3) Assert that the send was rejected and that no energy left the origin planet
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, eris.Errorf("max distance %d must be positive", -1000).Error(), receipts[0].Errs[0].Error())
 	assert.Equal(t, txHash, receipts[0].TxHash)
 
+	planetEntity, ok := component.LoadPlanetComponent(fromPlanet.LocationHash)
+	assert.True(t, ok)
+	assert.True(t, utils.Equal(fromPlanet.EnergyCurrent, planetEntity.Component.EnergyCurrent))
+
 	err = world.ShutDown()
 	assert.NoError(t, err)
 }
@@ -240,6 +503,7 @@ func TestSendEnergyToFriendlyPlanet(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -315,6 +579,7 @@ func TestSendEnergyAndCreatePlanet(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -392,6 +657,7 @@ func TestSendEnergyAndDealDamageToUnclaimedPlanet(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -472,6 +738,7 @@ func TestSendEnergyAndDealDamageToEnemyPlanet(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -552,6 +819,7 @@ func TestSendEnergyAndConquerPlanet(t *testing.T) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
 	}
 	proof, err := getProofForMoveCircuit(t, moveAssignment)
 	assert.NoError(t, err)
@@ -597,3 +865,243 @@ func TestSendEnergyAndConquerPlanet(t *testing.T) {
 	err = world.ShutDown()
 	assert.NoError(t, err)
 }
+
+// 10) Verify that an accepted move cannot be submitted again, not even with a fresh proof, while the same move with another nonce can
+func TestCannotReplaySendEnergyProof(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "Player1"
+
+	// 1) Create two planets owned by Player1
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	_, toPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+	assert.NoError(t, err)
+
+	// 2) Send energy to the friendly planet
+	// 2a) Generate proof for the transaction
+	distance := 11 // distance between the two planets is ~10.6
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelTwoPlanetTwo.LocationHash, 16)
+	assert.True(t, ok2)
+	moveAssignment := move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelTwoPlanetTwo.X,
+		Y2:      levelTwoPlanetTwo.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.Itoa(distance),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
+	}
+	proof, err := getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+
+	// 2b) Send transaction to Cardinal
+	transaction := tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelTwoPlanetTwo.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(distance),
+		Proof:            proof,
+	}
+	SendEnergy(world, transaction, player1)
+	sentTick := world.CurrentTick()
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, 0, len(receipts[0].Errs))
+
+	// 3) Submit the exact same message again
+	SendEnergy(world, transaction, player1)
+	replayTick := world.CurrentTick()
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(replayTick)
+	assert.Equal(t, eris.Errorf("proof for send energy tx from planet with location hash %s has already been used", levelTwoPlanet.LocationHash).Error(), receipts[0].Errs[0].Error())
+
+	// 4) A new proof for the same public inputs is rejected as well, proofs are malleable so only the public inputs count
+	transaction.Proof, err = getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+	SendEnergy(world, transaction, player1)
+	freshTick := world.CurrentTick()
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(freshTick)
+	assert.Equal(t, eris.Errorf("proof for send energy tx from planet with location hash %s has already been used", levelTwoPlanet.LocationHash).Error(), receipts[0].Errs[0].Error())
+
+	// 5) The same move with another nonce is accepted
+	moveAssignment.Nonce = 1
+	transaction.Nonce = 1
+	transaction.Proof, err = getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+	SendEnergy(world, transaction, player1)
+	nonceTick := world.CurrentTick()
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(nonceTick)
+	assert.Equal(t, 0, len(receipts[0].Errs))
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 11) Verify that proofs of several messages sent in the same tick are checked independently
+func TestSendEnergyWithSeveralProofsInOneTick(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "Player1"
+
+	// 1) Create two planets owned by Player1
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	_, toPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+	assert.NoError(t, err)
+
+	// 2) Generate a proof for the move
+	distance := 11 // distance between the two planets is ~10.6
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelTwoPlanetTwo.LocationHash, 16)
+	assert.True(t, ok2)
+	moveAssignment := move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelTwoPlanetTwo.X,
+		Y2:      levelTwoPlanetTwo.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.Itoa(distance),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
+	}
+	proof, err := getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+
+	// 3) Queue a message whose public inputs don't match the proof, followed by a valid one
+	valid := tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelTwoPlanetTwo.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(distance),
+		Proof:            proof,
+	}
+	invalid := valid
+	invalid.MaxDistance++
+
+	signedPayload := sign.Transaction{
+		PersonaTag: player1,
+	}
+	invalidHash := tx.SendEnergy.AddToQueue(world, invalid, &signedPayload)
+	validHash := tx.SendEnergy.AddToQueue(world, valid, &signedPayload)
+	sentTick := world.CurrentTick()
+	doTick()
+
+	// 4) Only the message with mismatching public inputs is rejected
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(sentTick)
+	assert.Equal(t, 2, len(receipts))
+	for _, receipt := range receipts {
+		switch receipt.TxHash {
+		case invalidHash:
+			assert.Equal(t, 1, len(receipt.Errs))
+		case validHash:
+			assert.Equal(t, 0, len(receipt.Errs))
+		default:
+			t.Fatalf("unexpected receipt for tx %s", receipt.TxHash)
+		}
+	}
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 12) Verify that a send rejected by the energy checks does not use up its proof and can be retried
+func TestRetrySendEnergyRejectedAtArrivalCheck(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	player1 := "Player1"
+	player2 := "Player2"
+
+	// 1) Create planets to send and receive, Player 2 owns recipient planet
+	_, fromPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	toPlanetId, toPlanet, err := CreatePlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player2)
+	assert.NoError(t, err)
+
+	// 2) Generate a proof for the move
+	distance := 11 // distance between the two planets is ~10.6
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelTwoPlanetTwo.LocationHash, 16)
+	assert.True(t, ok2)
+	moveAssignment := move.MoveCircuit{
+		X1:      levelTwoPlanet.X,
+		Y1:      levelTwoPlanet.Y,
+		X2:      levelTwoPlanetTwo.X,
+		Y2:      levelTwoPlanetTwo.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.Itoa(distance),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelTwoPlanetTwo.Perlin, 10),
+		Nonce:   0,
+	}
+	proof, err := getProofForMoveCircuit(t, moveAssignment)
+	assert.NoError(t, err)
+
+	transaction := tx.SendEnergyMsg{
+		LocationHashFrom: fromPlanet.LocationHash,
+		LocationHashTo:   toPlanet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelTwoPlanetTwo.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(distance),
+		Proof:            proof,
+	}
+
+	// 3) A negative defense makes the ship arrive without energy, the send is rejected by the arrival check
+	defense := toPlanet.Defense
+	toPlanet.Defense = utils.StrToDec("-1")
+	err = toPlanet.Set(wCtx, toPlanetId)
+	assert.NoError(t, err)
+
+	SendEnergy(world, transaction, player1)
+	rejectedTick := world.CurrentTick()
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(rejectedTick)
+	assert.Equal(t, eris.Errorf("ship did not have enough energy to arrive at enemy planet").Error(), receipts[0].Errs[0].Error())
+
+	// 4) Once the defense is restored the same message and proof are accepted
+	toPlanet.Defense = defense
+	err = toPlanet.Set(wCtx, toPlanetId)
+	assert.NoError(t, err)
+
+	SendEnergy(world, transaction, player1)
+	retryTick := world.CurrentTick()
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(retryTick)
+	assert.Equal(t, 0, len(receipts[0].Errs))
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,228 @@
+package utils
+
+import (
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/query"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/ericlagergren/decimal"
+	"github.com/stretchr/testify/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// Owner and energy of a planet after all ships landed
+type planetOutcome struct {
+	Owner  string
+	Energy string
+}
+
+// 1) Verify that ships landing on the same planet in the same tick are applied in the same order on every run
+func TestSimultaneousShipArrivalsAreDeterministic(t *testing.T) {
+	player1 := "Player1"
+	player2 := "Player2"
+	player3 := "Player3"
+
+	// Both ships travel over the same proven distance from planets with the same speed, so they land in the same tick
+	distance := 26 // distance from inRange to levelTwoPlanet is ~25.3, from levelTwoPlanetTwo ~10.6
+	proveMove := func(from NewPlanetInfo) string {
+		pub1, ok1 := new(big.Int).SetString(from.LocationHash, 16)
+		assert.True(t, ok1)
+		pub2, ok2 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+		assert.True(t, ok2)
+		proof, err := getProofForMoveCircuit(t, move.MoveCircuit{
+			X1:      from.X,
+			Y1:      from.Y,
+			X2:      levelTwoPlanet.X,
+			Y2:      levelTwoPlanet.Y,
+			R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+			DistMax: strconv.Itoa(distance),
+			Scale:   strconv.Itoa(game.WorldConstants.Scale),
+			XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+			YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+			Pub1:    pub1,
+			Pub2:    pub2,
+			Perl2:   strconv.FormatInt(levelTwoPlanet.Perlin, 10),
+			Nonce:   0,
+		})
+		assert.NoError(t, err)
+		return proof
+	}
+	proofFromPlayer1 := proveMove(levelTwoPlanetTwo)
+	proofFromPlayer2 := proveMove(inRange)
+
+	// Replays the same transactions in a fresh world and returns the state of the planets afterward
+	replay := func() map[string]planetOutcome {
+		// 0) Setup world
+		world, doTick := ScaffoldTestWorld(t)
+		wCtx := cardinal.TestingWorldToWorldContext(world)
+
+		// 1) Player1 and Player2 both attack the planet of Player3
+		_, planet1, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+		assert.NoError(t, err)
+		_, planet2, err := CreateMaxEnergyPlanetByLocationHash(world, inRange.LocationHash, inRange.Perlin, player2)
+		assert.NoError(t, err)
+		_, target, err := CreatePlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player3)
+		assert.NoError(t, err)
+
+		SendEnergy(world, tx.SendEnergyMsg{
+			LocationHashFrom: planet1.LocationHash,
+			LocationHashTo:   target.LocationHash,
+			Energy:           1300,
+			PerlinTo:         levelTwoPlanet.Perlin,
+			RadiusTo:         game.WorldConstants.RadiusMax,
+			MaxDistance:      int64(distance),
+			Proof:            proofFromPlayer1,
+		}, player1)
+		SendEnergy(world, tx.SendEnergyMsg{
+			LocationHashFrom: planet2.LocationHash,
+			LocationHashTo:   target.LocationHash,
+			Energy:           1300,
+			PerlinTo:         levelTwoPlanet.Perlin,
+			RadiusTo:         game.WorldConstants.RadiusMax,
+			MaxDistance:      int64(distance),
+			Proof:            proofFromPlayer2,
+		}, player2)
+
+		// 2) Run the world until both ships landed
+		energySendTick := world.CurrentTick()
+		energyArrivalTick := utils.ShipArrivalTick(utils.IntToDec(distance), utils.ScaleDownByTickRate(planet1.Speed), int64(energySendTick))
+		for i := int64(0); i <= energyArrivalTick-int64(energySendTick); i++ {
+			doTick()
+		}
+
+		receipts, _ := world.TestingGetTransactionReceiptsForTick(energySendTick)
+		assert.Equal(t, 2, len(receipts))
+		for _, receipt := range receipts {
+			assert.Equal(t, 0, len(receipt.Errs))
+		}
+
+		// 3) Both ships must have landed in the same tick
+		remainingShips := 0
+		component.ShipIndex.Range(func(key, value interface{}) bool {
+			remainingShips++
+			return true
+		})
+		assert.Equal(t, 0, remainingShips)
+
+		outcome := map[string]planetOutcome{}
+		for _, locationHash := range []string{planet1.LocationHash, planet2.LocationHash, target.LocationHash} {
+			planet, err := GetPlanetByLocationHash(wCtx, locationHash)
+			assert.NoError(t, err)
+			outcome[locationHash] = planetOutcome{Owner: planet.OwnerPersonaTag, Energy: utils.DecToStr(planet.EnergyCurrent)}
+		}
+
+		err = world.ShutDown()
+		assert.NoError(t, err)
+		return outcome
+	}
+
+	// 4) Every replay must end in the same state
+	expected := replay()
+	for run := 1; run < 10; run++ {
+		assert.Equal(t, expected, replay(), "run %d diverged", run)
+	}
+}
+
+// 2) Verify that attackers landing on the same planet in the same tick fight each other before the planet
+func TestSimultaneousAttacksAreNetted(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	player1 := "Player1"
+	player2 := "Player2"
+	player3 := "Player3"
+
+	// 1) Player1 and Player2 both attack the planet of Player3, Player1 with more energy
+	_, planet1, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+	assert.NoError(t, err)
+	_, planet2, err := CreateMaxEnergyPlanetByLocationHash(world, inRange.LocationHash, inRange.Perlin, player2)
+	assert.NoError(t, err)
+	_, target, err := CreatePlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player3)
+	assert.NoError(t, err)
+
+	// 1a) Generate proofs over the same distance so that both ships land in the same tick
+	distance := 26 // distance from inRange to levelTwoPlanet is ~25.3, from levelTwoPlanetTwo ~10.6
+	pubTo, okTo := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, okTo)
+	transactions := make([]tx.SendEnergyMsg, 0, 2)
+	for i, from := range []NewPlanetInfo{levelTwoPlanetTwo, inRange} {
+		pubFrom, okFrom := new(big.Int).SetString(from.LocationHash, 16)
+		assert.True(t, okFrom)
+		proof, err := getProofForMoveCircuit(t, move.MoveCircuit{
+			X1:      from.X,
+			Y1:      from.Y,
+			X2:      levelTwoPlanet.X,
+			Y2:      levelTwoPlanet.Y,
+			R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+			DistMax: strconv.Itoa(distance),
+			Scale:   strconv.Itoa(game.WorldConstants.Scale),
+			XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+			YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+			Pub1:    pubFrom,
+			Pub2:    pubTo,
+			Perl2:   strconv.FormatInt(levelTwoPlanet.Perlin, 10),
+			Nonce:   0,
+		})
+		assert.NoError(t, err)
+		transactions = append(transactions, tx.SendEnergyMsg{
+			LocationHashFrom: from.LocationHash,
+			LocationHashTo:   target.LocationHash,
+			Energy:           int64(1300 - 300*i),
+			PerlinTo:         levelTwoPlanet.Perlin,
+			RadiusTo:         game.WorldConstants.RadiusMax,
+			MaxDistance:      int64(distance),
+			Proof:            proof,
+		})
+	}
+	SendEnergy(world, transactions[0], player1)
+	SendEnergy(world, transactions[1], player2)
+
+	// 2) Run the world until both ships landed
+	energySendTick := world.CurrentTick()
+	energyArrivalTick := utils.ShipArrivalTick(utils.IntToDec(distance), utils.ScaleDownByTickRate(planet1.Speed), int64(energySendTick))
+	for i := int64(0); i <= energyArrivalTick-int64(energySendTick); i++ {
+		doTick()
+	}
+
+	// 3) The energy of Player2 is subtracted from the energy of Player1 before the defense of the planet applies
+	reply, err := query.BattleReports(wCtx, &query.BattleReportsMsg{PlanetsList: []string{target.LocationHash}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(reply.Reports))
+	report := reply.Reports[0]
+
+	energy1 := utils.EnergyOnEmbark(utils.Int64ToDec(transactions[0].Energy), planet1.EnergyMax, utils.IntToDec(distance), planet1.Range)
+	energy2 := utils.EnergyOnEmbark(utils.Int64ToDec(transactions[1].Energy), planet2.EnergyMax, utils.IntToDec(distance), planet2.Range)
+	netAttack := utils.EnergyAfterDefenseDebuff(new(decimal.Big).Sub(energy1, energy2), target.Defense)
+
+	assert.Equal(t, target.LocationHash, report.LocationHash)
+	assert.Equal(t, player3, report.DefenderPersonaTag)
+	assert.Equal(t, 2, len(report.Forces))
+	assert.Equal(t, player1, report.Forces[0].OwnerPersonaTag)
+	assert.Equal(t, utils.DecToStr(energy1), report.Forces[0].Energy)
+	assert.Equal(t, player2, report.Forces[1].OwnerPersonaTag)
+	assert.Equal(t, utils.DecToStr(energy2), report.Forces[1].Energy)
+	assert.Equal(t, player1, report.AttackerPersonaTag)
+	assert.Equal(t, utils.DecToStr(netAttack), report.NetAttackEnergy)
+
+	// 4) Player2 cannot snipe the planet, only Player1 may have conquered it
+	targetQueried, err := GetPlanetByLocationHash(wCtx, target.LocationHash)
+	assert.NoError(t, err)
+	assert.Equal(t, report.OwnerPersonaTag, targetQueried.OwnerPersonaTag)
+	assert.Equal(t, report.EnergyAfter, utils.DecToStr(targetQueried.EnergyCurrent))
+	if report.Conquered {
+		assert.Equal(t, player1, targetQueried.OwnerPersonaTag)
+	} else {
+		assert.Equal(t, player3, targetQueried.OwnerPersonaTag)
+		postAttackEnergy := new(decimal.Big).Sub(utils.StrToDec(report.DefenderEnergy), netAttack)
+		assert.Equal(t, utils.DecToStr(postAttackEnergy), report.EnergyAfter)
+	}
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -9,12 +9,13 @@ import (
 	tx "github.com/argus-labs/darkfrontier-backend/cardinal/tx"
 	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
 	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/frontend"
 	"github.com/consensys/gnark/frontend/cs/r1cs"
 	"github.com/ericlagergren/decimal"
-	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"os"
@@ -31,6 +32,15 @@ import (
 	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
 )
 
+// testLeaderboards are the leaderboards of the world created by the last call to ScaffoldTestWorld
+var testLeaderboards game.LeaderboardRegistry
+
+// testLeaderboard is the game.BoardScore leaderboard of testLeaderboards
+var testLeaderboard game.Leaderboard
+
+// testLeaderboardHistory is the leaderboard history of the world created by the last call to ScaffoldTestWorld
+var testLeaderboardHistory game.LeaderboardHistory
+
 // Miscellaneous test utilities
 func ScaffoldTestWorld(t *testing.T) (*cardinal.World, func()) {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
@@ -53,6 +63,14 @@ func ScaffoldTestWorld(t *testing.T) (*cardinal.World, func()) {
 		cardinal.WithTickChannel(time.Tick(tickRateInTime)),
 	)
 
+	// Each test world ranks its players on its own in-memory leaderboard, so tests don't need Redis
+	testLeaderboards = game.NewMemoryLeaderboardRegistry()
+	testLeaderboard, err = testLeaderboards.Board(game.BoardScore)
+	utils.Must(err)
+	testLeaderboardHistory = game.NewMemoryLeaderboardHistory()
+	scoring := system.NewScoringSystems(testLeaderboards, testLeaderboardHistory)
+	leaderboardQueries := query.NewLeaderboardQueries(testLeaderboards, testLeaderboardHistory)
+
 	// Register components
 	// NOTE: You must register your components here,
 	// otherwise it will show an error when you try to use them in a system.
@@ -60,6 +78,9 @@ func ScaffoldTestWorld(t *testing.T) (*cardinal.World, func()) {
 	utils.Must(cardinal.RegisterComponent[component.PlanetComponent](newWorld))
 	utils.Must(cardinal.RegisterComponent[component.ShipComponent](newWorld))
 	utils.Must(cardinal.RegisterComponent[component.DefaultsComponent](newWorld))
+	utils.Must(cardinal.RegisterComponent[component.ProofNullifierComponent](newWorld))
+	utils.Must(cardinal.RegisterComponent[component.BattleReportComponent](newWorld))
+	utils.Must(cardinal.RegisterComponent[component.AllianceComponent](newWorld))
 
 	// Register transactions
 	// NOTE: You must register your transactions here,
@@ -68,6 +89,16 @@ func ScaffoldTestWorld(t *testing.T) (*cardinal.World, func()) {
 		newWorld,
 		tx.SendEnergy,
 		tx.ClaimHomePlanet,
+		tx.RevealLocation,
+		tx.RecallShip,
+		tx.UpgradePlanet,
+		tx.AbandonPlanet,
+		tx.TransferPlanet,
+		tx.CreateAlliance,
+		tx.InviteToAlliance,
+		tx.AcceptAllianceInvite,
+		tx.LeaveAlliance,
+		tx.KickFromAlliance,
 		tx.SetConstant,
 	))
 
@@ -75,15 +106,36 @@ func ScaffoldTestWorld(t *testing.T) (*cardinal.World, func()) {
 	utils.Must(cardinal.RegisterQuery[query.ConstantMsg, query.ConstantReply](newWorld, "constant", query.Constants))
 	utils.Must(cardinal.RegisterQuery[query.CurrentTickMsg, query.CurrentTickReply](newWorld, "current-tick", query.CurrentTick))
 	utils.Must(cardinal.RegisterQuery[query.PlanetsMsg, query.PlanetsReply](newWorld, "planets", query.Planets))
-	utils.Must(cardinal.RegisterQuery[query.PlayerRangeMsg, query.PlayerRangeReply](newWorld, "player-range", query.PlayerRange))
-	utils.Must(cardinal.RegisterQuery[query.PlayerRankMsg, query.PlayerRankReply](newWorld, "player-rank", query.PlayerRank))
+	utils.Must(cardinal.RegisterQuery[query.PlayerRangeMsg, query.PlayerRangeReply](newWorld, "player-range", leaderboardQueries.PlayerRange))
+	utils.Must(cardinal.RegisterQuery[query.PlayerRankMsg, query.PlayerRankReply](newWorld, "player-rank", leaderboardQueries.PlayerRank))
+	utils.Must(cardinal.RegisterQuery[query.LeaderboardMsg, query.LeaderboardReply](newWorld, "leaderboard", leaderboardQueries.Leaderboard))
+	utils.Must(cardinal.RegisterQuery[query.PlayerTimelineMsg, query.PlayerTimelineReply](newWorld, "player-timeline", leaderboardQueries.PlayerTimeline))
+	utils.Must(cardinal.RegisterQuery[query.LeaderboardSnapshotMsg, query.LeaderboardSnapshotReply](newWorld, "leaderboard-snapshot", leaderboardQueries.LeaderboardSnapshot))
+	utils.Must(cardinal.RegisterQuery[query.RevealedPlanetsMsg, query.RevealedPlanetsReply](newWorld, "revealed-planets", query.RevealedPlanets))
+	utils.Must(cardinal.RegisterQuery[query.BattleReportsMsg, query.BattleReportsReply](newWorld, "battle-reports", query.BattleReports))
+	utils.Must(cardinal.RegisterQuery[query.AllianceMsg, query.AllianceReply](newWorld, "alliance", query.Alliance))
+	utils.Must(cardinal.RegisterQuery[query.AlliancePlanetsMsg, query.AlliancePlanetsReply](newWorld, "alliance-planets", query.AlliancePlanets))
 
 	// Register systems
 	utils.Must(cardinal.RegisterSystems(
 		newWorld,
-		system.SendEnergySystem,
-		system.ClaimHomePlanetSystem,
-		system.ShipArriveSystem,
+		scoring.LeaderboardCommitSystem,
+		scoring.ScoreReconcileSystem,
+		scoring.LeaderboardSnapshotSystem,
+		system.VerifyProofsSystem,
+		scoring.SendEnergySystem,
+		scoring.ClaimHomePlanetSystem,
+		system.RevealLocationSystem,
+		system.RecallShipSystem,
+		system.UpgradePlanetSystem,
+		scoring.AbandonPlanetSystem,
+		scoring.TransferPlanetSystem,
+		scoring.CreateAllianceSystem,
+		system.InviteToAllianceSystem,
+		scoring.AcceptAllianceInviteSystem,
+		scoring.LeaveAllianceSystem,
+		scoring.KickFromAllianceSystem,
+		scoring.ShipArriveSystem,
 		system.SetConstantSystem,
 	))
 
@@ -91,15 +143,10 @@ func ScaffoldTestWorld(t *testing.T) (*cardinal.World, func()) {
 	component.PlanetIndex = sync.Map{}
 	component.ShipIndex = sync.Map{}
 	component.PlayerIndex = sync.Map{}
-
-	addr := os.Getenv("REDIS_ADDRESS")
-	options := &redis.Options{
-		Addr:     addr, // Redis server address
-		Password: "",   // No password by default
-		DB:       0,    // Default database
-	}
-
-	game.LeaderboardClient = redis.NewClient(options)
+	component.ProofNullifierIndex = sync.Map{}
+	component.BattleReportIndex = sync.Map{}
+	component.AllianceIndex = sync.Map{}
+	component.AllianceMembershipIndex = sync.Map{}
 
 	go func() {
 		err := newWorld.StartGame()
@@ -121,6 +168,8 @@ func SetupCircuitsAndOverwriteConstants() {
 	game.WorldConstants.YMirror = 0
 	game.WorldConstants.MiMCNumRounds = 110
 	game.WorldConstants.PerlinNumRounds = 4
+	game.WorldConstants.PerlinOctaveWeights = []int64{2, 1, 1}
+	game.WorldConstants.PerlinBuckets = 16
 	game.WorldConstants.MiMCSeedWord = "7"
 	game.WorldConstants.PerlinSeedWord = "7"
 	game.WorldConstants.RadiusMax = 500
@@ -128,6 +177,8 @@ func SetupCircuitsAndOverwriteConstants() {
 	var newInitCircuit initialize.InitCircuit
 	newInitCircuit.PlanetHashKey = game.WorldConstants.PerlinSeedWord
 	newInitCircuit.SpaceTypeKey = game.WorldConstants.MiMCSeedWord
+	newInitCircuit.MiMCNumRounds = game.WorldConstants.MiMCNumRounds
+	newInitCircuit.PerlinProfile = game.WorldConstants.PerlinProfile()
 
 	ccs, _ := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &newInitCircuit)
 	//assert.NoError(t, err, "error compiling init circuit in SetupKeysAndParams")
@@ -136,13 +187,23 @@ func SetupCircuitsAndOverwriteConstants() {
 	pk, vk, _ := groth16.Setup(ccs)
 	//assert.NoError(t, err, "error performing trusted setup for init circuit in SetupKeysAndParams")
 
-	keys.InitVerifyingKey = vk
+	initVK := vk
 	InitProvingKey = pk
 	InitCCS = ccs
 
+	newInitRimCircuit := newInitCircuit
+	newInitRimCircuit.RimSpawn = true
+
+	ccs, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &newInitRimCircuit)
+
+	// Perform trusted setup
+	_, initRimVK, _ := groth16.Setup(ccs)
+
 	var newMoveCircuit move.MoveCircuit
 	newMoveCircuit.PlanetHashKey = game.WorldConstants.PerlinSeedWord
 	newMoveCircuit.SpaceTypeKey = game.WorldConstants.MiMCSeedWord
+	newMoveCircuit.MiMCNumRounds = game.WorldConstants.MiMCNumRounds
+	newMoveCircuit.PerlinProfile = game.WorldConstants.PerlinProfile()
 
 	ccs, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &newMoveCircuit)
 	//assert.NoError(t, err, "error compiling move circuit in SetupKeysAndParams")
@@ -151,9 +212,30 @@ func SetupCircuitsAndOverwriteConstants() {
 	pk, vk, _ = groth16.Setup(ccs)
 	//assert.NoError(t, err, "error performing trusted setup for move circuit in SetupKeysAndParams")
 
-	keys.MoveVerifyingKey = vk
 	MoveProvingKey = pk
 	MoveCCS = ccs
+	moveVK := vk
+
+	var newRevealCircuit reveal.RevealCircuit
+	newRevealCircuit.PlanetHashKey = game.WorldConstants.MiMCSeedWord
+	newRevealCircuit.MiMCNumRounds = game.WorldConstants.MiMCNumRounds
+
+	ccs, _ = frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &newRevealCircuit)
+
+	// Perform trusted setup
+	pk, vk, _ = groth16.Setup(ccs)
+
+	RevealProvingKey = pk
+	RevealCCS = ccs
+
+	keys.Register(game.WorldConstants.CircuitArtifactUUID, keys.VerifyingKeys{
+		ProofSystem: proofsystem.Groth16,
+		Init:        initVK,
+		Move:        moveVK,
+		Reveal:      vk,
+		InitRim:     initRimVK,
+		CircuitKeys: keys.WorldCircuitKeys(game.WorldConstants),
+	})
 
 	game.NebulaSpaceConstants = game.SpaceConstant{
 		Label:                   "Nebula",
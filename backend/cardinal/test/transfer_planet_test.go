@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/query"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/stretchr/testify/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// 1) Verify that a planet, its score and the ships heading to it move to the recipient
+func TestTransferPlanet(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	player1 := "TransferPlayer1"
+	player2 := "TransferPlayer2"
+
+	// 1) Player1 owns two planets, Player2 has a home planet
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	_, otherPlanet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+	assert.NoError(t, err)
+	_, _, err = CreatePlayerWithClaimedPlanet(world, player2, "0x2", levelZeroPlanet.LocationHash, levelZeroPlanet.Perlin)
+	assert.NoError(t, err)
+
+	// 2) Player1 sends energy from its other planet to the planet it is about to give away
+	distance := 11 // distance between the two planets is ~10.6
+	pub1, ok1 := new(big.Int).SetString(levelTwoPlanetTwo.LocationHash, 16)
+	assert.True(t, ok1)
+	pub2, ok2 := new(big.Int).SetString(levelTwoPlanet.LocationHash, 16)
+	assert.True(t, ok2)
+	proof, err := getProofForMoveCircuit(t, move.MoveCircuit{
+		X1:      levelTwoPlanetTwo.X,
+		Y1:      levelTwoPlanetTwo.Y,
+		X2:      levelTwoPlanet.X,
+		Y2:      levelTwoPlanet.Y,
+		R:       strconv.FormatInt(game.WorldConstants.RadiusMax, 10),
+		DistMax: strconv.Itoa(distance),
+		Scale:   strconv.Itoa(game.WorldConstants.Scale),
+		XMirror: strconv.Itoa(game.WorldConstants.XMirror),
+		YMirror: strconv.Itoa(game.WorldConstants.YMirror),
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   strconv.FormatInt(levelTwoPlanet.Perlin, 10),
+		Nonce:   0,
+	})
+	assert.NoError(t, err)
+	SendEnergy(world, tx.SendEnergyMsg{
+		LocationHashFrom: otherPlanet.LocationHash,
+		LocationHashTo:   planet.LocationHash,
+		Energy:           1000,
+		PerlinTo:         levelTwoPlanet.Perlin,
+		RadiusTo:         game.WorldConstants.RadiusMax,
+		MaxDistance:      int64(distance),
+		Proof:            proof,
+	}, player1)
+	doTick()
+
+	// The leaderboard was derived from the owned planets on the first tick
+	_, scoreBefore1, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	_, scoreBefore2, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player2)
+	assert.NoError(t, err)
+
+	// 3) Transfer the planet to Player2
+	transferTick := world.CurrentTick()
+	TransferPlanet(world, tx.TransferPlanetMsg{LocationHash: planet.LocationHash, RecipientPersonaTag: player2}, player1)
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(transferTick)
+	assert.Equal(t, 1, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	reply, ok := receipts[0].Result.(tx.TransferPlanetReply)
+	assert.True(t, ok)
+	assert.Equal(t, player2, reply.TransferredPlanet.OwnerPersonaTag)
+	assert.Equal(t, 1, len(reply.ConvertedShips))
+	assert.Equal(t, player2, reply.ConvertedShips[0].OwnerPersonaTag)
+	assert.Equal(t, planet.LocationHash, reply.ConvertedShips[0].LocationHashTo)
+
+	// 4) The score of the planet moved from Player1 to Player2 once the tick was committed
+	assert.Greater(t, reply.ScoreTransferred, 0)
+	doTick()
+	_, score1, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player1)
+	assert.NoError(t, err)
+	assert.Equal(t, scoreBefore1-float64(reply.ScoreTransferred), score1)
+	_, score2, err := testLeaderboard.GetPlayerRankAndScore(context.Background(), player2)
+	assert.NoError(t, err)
+	assert.Equal(t, scoreBefore2+float64(reply.ScoreTransferred), score2)
+
+	// 5) The converted ship lands as a reinforcement of Player2
+	for int64(world.CurrentTick()) <= reply.ConvertedShips[0].TickArrive {
+		doTick()
+	}
+	planetEntity, ok := component.LoadPlanetComponent(planet.LocationHash)
+	assert.True(t, ok)
+	assert.Equal(t, player2, planetEntity.Component.OwnerPersonaTag)
+	reports, err := query.BattleReports(wCtx, &query.BattleReportsMsg{PlanetsList: []string{planet.LocationHash}})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(reports.Reports))
+	_, ok = component.LoadShipComponent(cardinal.EntityID(reply.ConvertedShips[0].Id))
+	assert.False(t, ok)
+	assert.Equal(t, utils.DecToStr(planetEntity.Component.EnergyMax), utils.DecToStr(planetEntity.Component.EnergyCurrent))
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 2) Verify that a planet can only be transferred to an existing player
+func TestCannotTransferPlanetToUnknownPersona(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	player1 := "TransferPlayer1"
+
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	_, _, err = CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanetTwo.LocationHash, levelTwoPlanetTwo.Perlin, player1)
+	assert.NoError(t, err)
+
+	transferTick := world.CurrentTick()
+	TransferPlanet(world, tx.TransferPlanetMsg{LocationHash: planet.LocationHash, RecipientPersonaTag: "Nobody"}, player1)
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(transferTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("no player exists with persona %s", "Nobody"), receipts[0].Errs[0].Error())
+
+	planetEntity, ok := component.LoadPlanetComponent(planet.LocationHash)
+	assert.True(t, ok)
+	assert.Equal(t, player1, planetEntity.Component.OwnerPersonaTag)
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -57,6 +57,7 @@ func ClaimHomePlanet(t *testing.T, planet NewPlanetInfo, persona string) (cardin
 	transaction := tx.ClaimHomePlanetMsg{
 		LocationHash: planet.LocationHash,
 		Perlin:       planet.Perlin,
+		Radius:       game.WorldConstants.RadiusMax,
 		Proof:        proof,
 	}
 
@@ -70,3 +71,66 @@ func ClaimHomePlanet(t *testing.T, planet NewPlanetInfo, persona string) (cardin
 
 	return *world, wCtx, doTick
 }
+
+func RecallShip(world *cardinal.World, transaction tx.RecallShipMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.RecallShip.AddToQueue(world, transaction, &signedPayload)
+}
+
+func UpgradePlanet(world *cardinal.World, transaction tx.UpgradePlanetMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.UpgradePlanet.AddToQueue(world, transaction, &signedPayload)
+}
+
+func AbandonPlanet(world *cardinal.World, transaction tx.AbandonPlanetMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.AbandonPlanet.AddToQueue(world, transaction, &signedPayload)
+}
+
+func TransferPlanet(world *cardinal.World, transaction tx.TransferPlanetMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.TransferPlanet.AddToQueue(world, transaction, &signedPayload)
+}
+
+func CreateAlliance(world *cardinal.World, transaction tx.CreateAllianceMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.CreateAlliance.AddToQueue(world, transaction, &signedPayload)
+}
+
+func InviteToAlliance(world *cardinal.World, transaction tx.InviteToAllianceMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.InviteToAlliance.AddToQueue(world, transaction, &signedPayload)
+}
+
+func AcceptAllianceInvite(world *cardinal.World, transaction tx.AcceptAllianceInviteMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.AcceptAllianceInvite.AddToQueue(world, transaction, &signedPayload)
+}
+
+func LeaveAlliance(world *cardinal.World, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.LeaveAlliance.AddToQueue(world, tx.LeaveAllianceMsg{}, &signedPayload)
+}
+
+func KickFromAlliance(world *cardinal.World, transaction tx.KickFromAllianceMsg, persona string) {
+	signedPayload := sign.Transaction{
+		PersonaTag: persona,
+	}
+	tx.KickFromAlliance.AddToQueue(world, transaction, &signedPayload)
+}
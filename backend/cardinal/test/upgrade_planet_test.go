@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/system"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/tx"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/utils"
+	"github.com/ericlagergren/decimal"
+	"github.com/stretchr/testify/assert"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// 1) Verify that an owner can upgrade a stat branch of a planet up to the maximum rank of its level
+func TestUpgradePlanet(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	player1 := "Player1"
+
+	// 1) Create a level 2 planet for Player1
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, player1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), game.PlanetUpgradeConstants.MaxRank[planet.Level])
+
+	// 2) Upgrade its range
+	upgradeTick := world.CurrentTick()
+	UpgradePlanet(world, tx.UpgradePlanetMsg{LocationHash: planet.LocationHash, Branch: tx.UpgradeRange}, player1)
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(upgradeTick)
+	assert.Equal(t, 1, len(receipts))
+	assert.Equal(t, 0, len(receipts[0].Errs))
+	reply, ok := receipts[0].Result.(tx.UpgradePlanetReply)
+	assert.True(t, ok)
+
+	energyCost := new(decimal.Big).Mul(planet.EnergyMax, utils.StrToDec(game.PlanetUpgradeConstants.EnergyCost))
+	assert.Equal(t, utils.DecToStr(energyCost), reply.EnergySpent)
+	assert.Equal(t, int64(1), reply.Planet.RangeRank)
+	assert.Equal(t, utils.DecToStr(utils.UpgradedStat(planet.Range, 1)), reply.Planet.Range)
+	assert.Equal(t, utils.DecToStr(planet.Speed), reply.Planet.Speed)
+
+	// 3) Check that the upgrade is stored on the planet
+	planetQueried, err := GetPlanetByLocationHash(wCtx, planet.LocationHash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), planetQueried.RangeRank)
+	assert.Equal(t, reply.Planet.Range, utils.DecToStr(planetQueried.Range))
+	assert.Equal(t, reply.Planet.EnergyCurrent, utils.DecToStr(planetQueried.EnergyCurrent))
+
+	// 4) The range of a level 2 planet cannot be upgraded twice
+	upgradeTick = world.CurrentTick()
+	UpgradePlanet(world, tx.UpgradePlanetMsg{LocationHash: planet.LocationHash, Branch: tx.UpgradeRange}, player1)
+	doTick()
+
+	receipts, _ = world.TestingGetTransactionReceiptsForTick(upgradeTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("range of planet with location hash %s is already at the maximum rank 1 of level 2", planet.LocationHash), receipts[0].Errs[0].Error())
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 2) Verify that only the owner can upgrade a planet
+func TestCannotUpgradePlanetOfOtherPlayer(t *testing.T) {
+	// 0) Setup world
+	world, doTick := ScaffoldTestWorld(t)
+
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, "Player1")
+	assert.NoError(t, err)
+
+	upgradeTick := world.CurrentTick()
+	UpgradePlanet(world, tx.UpgradePlanetMsg{LocationHash: planet.LocationHash, Branch: tx.UpgradeSpeed}, "Player2")
+	doTick()
+
+	receipts, _ := world.TestingGetTransactionReceiptsForTick(upgradeTick)
+	assert.Equal(t, 1, len(receipts[0].Errs))
+	assert.Equal(t, fmt.Sprintf("player with persona Player2 does not own planet with location hash %s", planet.LocationHash), receipts[0].Errs[0].Error())
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
+
+// 3) Verify that upgrades survive a rebalance of the planet level
+func TestUpgradeSurvivesRebalancingPlanetLevel(t *testing.T) {
+	// 0) Force build DefaultsComponent
+	system.RebuildIndex = true
+	world, doTick := ScaffoldTestWorld(t)
+	wCtx := cardinal.TestingWorldToWorldContext(world)
+	temp := game.PlanetLevel2Stats
+	defer func() { *game.BasePlanetLevelStats[2] = temp }()
+
+	// 1) Upgrade the defense of a level 2 planet
+	_, planet, err := CreateMaxEnergyPlanetByLocationHash(world, levelTwoPlanet.LocationHash, levelTwoPlanet.Perlin, "Player1")
+	assert.NoError(t, err)
+	UpgradePlanet(world, tx.UpgradePlanetMsg{LocationHash: planet.LocationHash, Branch: tx.UpgradeDefense}, "Player1")
+	doTick()
+
+	// 2) Rebalance level 2
+	newStats := game.PlanetLevel2Stats
+	newStats.Defense = "200"
+	newStats.Range = "100"
+	SetConstant(world, tx.SetConstantMsg{
+		ConstantName: "Level2Constants",
+		Value: system.LevelConstantsMsg{
+			EnergyDefault: newStats.EnergyDefault,
+			EnergyMax:     newStats.EnergyMax,
+			EnergyRefill:  newStats.EnergyRefill,
+			Range:         newStats.Range,
+			Speed:         newStats.Speed,
+			Defense:       newStats.Defense,
+			Score:         newStats.Score,
+		},
+	}, "admin")
+	doTick()
+
+	// 3) The new defense is raised by the upgrade, the range is not
+	adjustedStats := utils.GetSpaceAdjustedPlanetStats(newStats, *game.SpaceConstants[planet.SpaceArea-1])
+	planetQueried, err := GetPlanetByLocationHash(wCtx, planet.LocationHash)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), planetQueried.DefenseRank)
+	assert.Equal(t, utils.DecToStr(utils.UpgradedStat(utils.StrToDec(adjustedStats.Defense), 1)), utils.DecToStr(planetQueried.Defense))
+	assert.Equal(t, adjustedStats.Range, utils.DecToStr(planetQueried.Range))
+
+	err = world.ShutDown()
+	assert.NoError(t, err)
+}
@@ -0,0 +1,26 @@
+package tx
+
+import (
+	"fmt"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type AbandonPlanetMsg struct {
+	LocationHash string `json:"locationHash"`
+}
+
+type AbandonPlanetReply struct {
+	AbandonedPlanet PlanetReceipt `json:"abandonedPlanet"`
+	ScoreRemoved    int           `json:"scoreRemoved"`
+}
+
+var AbandonPlanet = cardinal.NewMessageTypeWithEVMSupport[AbandonPlanetMsg, AbandonPlanetReply]("abandon-planet")
+
+func (msg AbandonPlanetMsg) Validate() error {
+	// Check that LocationHash is 64 characters long
+	if len(msg.LocationHash) != 64 {
+		return fmt.Errorf("location hash length was not 64 chars: %s", msg.LocationHash)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package tx
+
+import (
+	"fmt"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// MaxAllianceNameLength is the maximum number of characters of an alliance name
+const MaxAllianceNameLength = 32
+
+type CreateAllianceMsg struct {
+	Name string `json:"name"`
+}
+
+type InviteToAllianceMsg struct {
+	// Persona to invite to the alliance of the sender, the sender must be its leader
+	PersonaTag string `json:"personaTag"`
+}
+
+type AcceptAllianceInviteMsg struct {
+	Name string `json:"name"`
+}
+
+type LeaveAllianceMsg struct{}
+
+type KickFromAllianceMsg struct {
+	// Member to remove from the alliance of the sender, the sender must be its leader
+	PersonaTag string `json:"personaTag"`
+}
+
+// AllianceReply is the alliance after the message was applied, an alliance whose last member left has no members
+type AllianceReply struct {
+	Alliance component.AllianceComponent `json:"alliance"`
+}
+
+var CreateAlliance = cardinal.NewMessageTypeWithEVMSupport[CreateAllianceMsg, AllianceReply]("create-alliance")
+var InviteToAlliance = cardinal.NewMessageTypeWithEVMSupport[InviteToAllianceMsg, AllianceReply]("invite-to-alliance")
+var AcceptAllianceInvite = cardinal.NewMessageTypeWithEVMSupport[AcceptAllianceInviteMsg, AllianceReply]("accept-alliance-invite")
+var LeaveAlliance = cardinal.NewMessageTypeWithEVMSupport[LeaveAllianceMsg, AllianceReply]("leave-alliance")
+var KickFromAlliance = cardinal.NewMessageTypeWithEVMSupport[KickFromAllianceMsg, AllianceReply]("kick-from-alliance")
+
+func (msg CreateAllianceMsg) Validate() error {
+	return validateAllianceName(msg.Name)
+}
+
+func (msg InviteToAllianceMsg) Validate() error {
+	if msg.PersonaTag == "" {
+		return fmt.Errorf("persona tag is empty")
+	}
+
+	return nil
+}
+
+func (msg AcceptAllianceInviteMsg) Validate() error {
+	return validateAllianceName(msg.Name)
+}
+
+func (msg KickFromAllianceMsg) Validate() error {
+	if msg.PersonaTag == "" {
+		return fmt.Errorf("persona tag is empty")
+	}
+
+	return nil
+}
+
+func validateAllianceName(name string) error {
+	if name == "" {
+		return fmt.Errorf("alliance name is empty")
+	}
+	if len(name) > MaxAllianceNameLength {
+		return fmt.Errorf("alliance name is longer than %d chars: %s", MaxAllianceNameLength, name)
+	}
+
+	return nil
+}
@@ -1,15 +1,11 @@
 package tx
 
 import (
-	"bytes"
-	"encoding/base64"
 	"fmt"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/component"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
-	"github.com/argus-labs/darkfrontier-backend/cardinal/keys"
 	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
 	"math/big"
@@ -19,7 +15,10 @@ import (
 type ClaimHomePlanetMsg struct {
 	LocationHash string `json:"locationHash"`
 	Perlin       int64  `json:"perlin"`
+	Radius       int64  `json:"radius"`
 	Proof        string `json:"proof"`
+	// UUID of the circuit artifacts the proof was generated with, defaults to CircuitArtifactUUID
+	ArtifactVersion string `json:"artifactVersion,omitempty"`
 }
 
 type ClaimHomePlanetReply struct {
@@ -41,6 +40,7 @@ func (msg ClaimHomePlanetMsg) Validate() error {
 func (msg ClaimHomePlanetMsg) CreatePublicWitness() (witness.Witness, error) {
 	pub, _ := new(big.Int).SetString(msg.LocationHash, 16)
 	initAssignment := initialize.InitCircuit{
+		R:       msg.Radius,
 		Scale:   game.WorldConstants.Scale,
 		XMirror: game.WorldConstants.XMirror,
 		YMirror: game.WorldConstants.YMirror,
@@ -53,15 +53,29 @@ func (msg ClaimHomePlanetMsg) CreatePublicWitness() (witness.Witness, error) {
 
 func (msg ClaimHomePlanetMsg) VerifyInitProof(publicWitness witness.Witness) error {
 	// Parse proof from byte response
-	proofByte, err := base64.StdEncoding.DecodeString(msg.Proof)
+	vks, err := verifyingKeys(msg.ArtifactVersion)
 	if err != nil {
 		return err
 	}
-	proof := groth16.NewProof(ecc.BN254)
-	_, err = proof.ReadFrom(bytes.NewReader(proofByte))
+	// The rim-spawn variant has the same public inputs, only the verifying key differs
+	vk := vks.Init
+	if game.WorldConstants.RimSpawn {
+		if vks.InitRim == nil {
+			return fmt.Errorf("circuit artifact version %s has no rim-spawn init verifying key", artifactVersion(msg.ArtifactVersion))
+		}
+		vk = vks.InitRim
+	}
+	proof, err := parseProof(vks.ProofSystem, msg.Proof)
 	if err != nil {
 		return err
 	}
-	err = groth16.Verify(proof, keys.InitVerifyingKey, publicWitness)
+	err = vks.ProofSystem.Verify(proof, vk, publicWitness)
 	return err
 }
+
+// ProofDigest identifies the public inputs the proof was verified against for the persona, used
+// to reject claims whose public inputs were already accepted. A planet is only ever claimed as
+// a home planet once, so unlike sends claims need no nonce.
+func (msg ClaimHomePlanetMsg) ProofDigest(personaTag string, publicWitness witness.Witness) (string, error) {
+	return proofDigest(ClaimHomePlanet.Name(), personaTag, publicWitness)
+}
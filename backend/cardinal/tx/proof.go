@@ -0,0 +1,75 @@
+package tx
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
+	"github.com/argus-labs/darkfrontier-backend/cardinal/keys"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/consensys/gnark/backend/witness"
+)
+
+// parseProof decodes a base64 encoded proof of the given proof system, either raw or compressed
+func parseProof(ps proofsystem.ProofSystem, encoded string) (proofsystem.Proof, error) {
+	proofByte, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	return proofsystem.ReadProof(ps, proofByte)
+}
+
+// proofDigest returns sha256(message name || persona tag || public inputs), hex encoded. The proof itself is
+// left out on purpose: proofs are malleable, e.g. a groth16 proof can be re-randomized into
+// another valid proof of the same public inputs, so only the public inputs identify a claim.
+// Messages that may legitimately repeat their public inputs carry a nonce among them. The persona
+// tag of the signer binds the digest to them, so a pending message copied and submitted under another
+// persona doesn't use up their proof.
+func proofDigest(messageName string, personaTag string, publicWitness witness.Witness) (string, error) {
+	publicInputs, err := publicWitness.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	h.Write([]byte(messageName))
+	h.Write([]byte(personaTag))
+	h.Write(publicInputs)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyingKeys returns the keys of the artifact version a proof was generated with,
+// messages without a version use the current CircuitArtifactUUID
+func verifyingKeys(version string) (keys.VerifyingKeys, error) {
+	version = artifactVersion(version)
+	if !IsCircuitArtifactAccepted(version) {
+		return keys.VerifyingKeys{}, fmt.Errorf("circuit artifact version %s is not accepted", version)
+	}
+	vks, ok := keys.Load(version)
+	if !ok {
+		return keys.VerifyingKeys{}, fmt.Errorf("no verifying keys registered for circuit artifact version %s", version)
+	}
+	return vks, nil
+}
+
+// artifactVersion resolves the version of a message, empty means the current CircuitArtifactUUID
+func artifactVersion(version string) string {
+	if version == "" {
+		return game.WorldConstants.CircuitArtifactUUID
+	}
+	return version
+}
+
+// IsCircuitArtifactAccepted reports whether proofs of the artifact version are currently accepted
+func IsCircuitArtifactAccepted(version string) bool {
+	if version == game.WorldConstants.CircuitArtifactUUID {
+		return true
+	}
+	for _, accepted := range game.WorldConstants.AcceptedCircuitArtifactUUIDs {
+		if version == accepted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,16 @@
+package tx
+
+import (
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type RecallShipMsg struct {
+	// Id of the ship, as in SendEnergyReply.SentShip
+	ShipId uint64 `json:"shipId"`
+}
+
+type RecallShipReply struct {
+	RecalledShip ShipReceipt `json:"recalledShip"`
+}
+
+var RecallShip = cardinal.NewMessageTypeWithEVMSupport[RecallShipMsg, RecallShipReply]("recall-ship")
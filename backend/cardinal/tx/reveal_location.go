@@ -0,0 +1,67 @@
+package tx
+
+import (
+	"fmt"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/frontend"
+	"math/big"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type RevealLocationMsg struct {
+	LocationHash string `json:"locationHash"`
+	X            int64  `json:"x"`
+	Y            int64  `json:"y"`
+	Proof        string `json:"proof"`
+	// UUID of the circuit artifacts the proof was generated with, defaults to CircuitArtifactUUID
+	ArtifactVersion string `json:"artifactVersion,omitempty"`
+}
+
+type RevealLocationReply struct {
+	LocationHash    string `json:"locationHash"`
+	X               int64  `json:"x"`
+	Y               int64  `json:"y"`
+	OwnerPersonaTag string `json:"ownerPersonaTag"`
+	RevealedBy      string `json:"revealedBy"`
+	RevealedTick    int64  `json:"revealedTick"`
+}
+
+var RevealLocation = cardinal.NewMessageTypeWithEVMSupport[RevealLocationMsg, RevealLocationReply]("reveal-location")
+
+func (msg RevealLocationMsg) Validate() error {
+	// Check that LocationHash is 64 characters long
+	if len(msg.LocationHash) != 64 {
+		return fmt.Errorf("location hash length was not 64 chars: %s", msg.LocationHash)
+	}
+
+	return nil
+}
+
+func (msg RevealLocationMsg) CreatePublicWitness() (witness.Witness, error) {
+	pub, _ := new(big.Int).SetString(msg.LocationHash, 16)
+	revealAssignment := reveal.RevealCircuit{
+		X:   msg.X,
+		Y:   msg.Y,
+		Pub: pub,
+	}
+	publicWitness, err := frontend.NewWitness(&revealAssignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	return publicWitness, err
+}
+
+func (msg RevealLocationMsg) VerifyRevealProof(publicWitness witness.Witness) error {
+	vks, err := verifyingKeys(msg.ArtifactVersion)
+	if err != nil {
+		return err
+	}
+	if vks.Reveal == nil {
+		return fmt.Errorf("circuit artifact version %s has no reveal verifying key", artifactVersion(msg.ArtifactVersion))
+	}
+	proof, err := parseProof(vks.ProofSystem, msg.Proof)
+	if err != nil {
+		return err
+	}
+	err = vks.ProofSystem.Verify(proof, vks.Reveal, publicWitness)
+	return err
+}
@@ -1,14 +1,10 @@
 package tx
 
 import (
-	"bytes"
-	"encoding/base64"
 	"fmt"
 	"github.com/argus-labs/darkfrontier-backend/cardinal/game"
-	"github.com/argus-labs/darkfrontier-backend/cardinal/keys"
 	"github.com/argus-labs/darkfrontier-backend/circuit/move"
 	"github.com/consensys/gnark-crypto/ecc"
-	"github.com/consensys/gnark/backend/groth16"
 	"github.com/consensys/gnark/backend/witness"
 	"github.com/consensys/gnark/frontend"
 	"math/big"
@@ -28,6 +24,9 @@ type PlanetReceipt struct {
 	LastUpdateRefillAge string `json:"lastUpdateRefillAge"`
 	LastUpdateTick      string `json:"lastUpdateTick"`
 	SpaceArea           int64  `json:"SpaceArea"`
+	RangeRank           int64  `json:"rangeRank"`
+	SpeedRank           int64  `json:"speedRank"`
+	DefenseRank         int64  `json:"defenseRank"`
 }
 
 type ShipReceipt struct {
@@ -38,6 +37,7 @@ type ShipReceipt struct {
 	TickStart        int64  `json:"tickStart"`
 	TickArrive       int64  `json:"tickArrive"`
 	EnergyOnEmbark   string `json:"energyOnEmbark"`
+	Recalled         bool   `json:"recalled"`
 }
 
 type SendEnergyMsg struct {
@@ -48,6 +48,10 @@ type SendEnergyMsg struct {
 	MaxDistance      int64  `json:"maxDistance"`
 	Energy           int64  `json:"energy"`
 	Proof            string `json:"proof"`
+	// Public input of the move proof, tells apart sends along the same route
+	Nonce uint64 `json:"nonce"`
+	// UUID of the circuit artifacts the proof was generated with, defaults to CircuitArtifactUUID
+	ArtifactVersion string `json:"artifactVersion,omitempty"`
 }
 
 type SendEnergyReply struct {
@@ -78,6 +82,7 @@ func (msg SendEnergyMsg) CreatePublicWitness() (witness.Witness, error) {
 		Pub1:    pub1,
 		Pub2:    pub2,
 		Perl2:   msg.PerlinTo,
+		Nonce:   msg.Nonce,
 	}
 
 	publicWitness, err := frontend.NewWitness(&moveAssignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
@@ -86,15 +91,20 @@ func (msg SendEnergyMsg) CreatePublicWitness() (witness.Witness, error) {
 
 func (msg SendEnergyMsg) VerifyMoveProof(publicWitness witness.Witness) error {
 	// Parse proof from byte response
-	proofByte, err := base64.StdEncoding.DecodeString(msg.Proof)
+	vks, err := verifyingKeys(msg.ArtifactVersion)
 	if err != nil {
 		return err
 	}
-	proof := groth16.NewProof(ecc.BN254)
-	_, err = proof.ReadFrom(bytes.NewReader(proofByte))
+	proof, err := parseProof(vks.ProofSystem, msg.Proof)
 	if err != nil {
 		return err
 	}
-	err = groth16.Verify(proof, keys.MoveVerifyingKey, publicWitness)
+	err = vks.ProofSystem.Verify(proof, vks.Move, publicWitness)
 	return err
 }
+
+// ProofDigest identifies the public inputs, nonce included, the proof was verified against for
+// the persona, used to reject sends whose public inputs were already accepted
+func (msg SendEnergyMsg) ProofDigest(personaTag string, publicWitness witness.Witness) (string, error) {
+	return proofDigest(SendEnergy.Name(), personaTag, publicWitness)
+}
@@ -0,0 +1,34 @@
+package tx
+
+import (
+	"fmt"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+type TransferPlanetMsg struct {
+	LocationHash string `json:"locationHash"`
+	// Persona that receives the planet, it must have claimed a home planet
+	RecipientPersonaTag string `json:"recipientPersonaTag"`
+}
+
+type TransferPlanetReply struct {
+	TransferredPlanet PlanetReceipt `json:"transferredPlanet"`
+	ScoreTransferred  int           `json:"scoreTransferred"`
+	// Ships of the previous owner heading to the planet, they now belong to the recipient
+	ConvertedShips []ShipReceipt `json:"convertedShips"`
+}
+
+var TransferPlanet = cardinal.NewMessageTypeWithEVMSupport[TransferPlanetMsg, TransferPlanetReply]("transfer-planet")
+
+func (msg TransferPlanetMsg) Validate() error {
+	// Check that LocationHash is 64 characters long
+	if len(msg.LocationHash) != 64 {
+		return fmt.Errorf("location hash length was not 64 chars: %s", msg.LocationHash)
+	}
+
+	if msg.RecipientPersonaTag == "" {
+		return fmt.Errorf("recipient persona tag is empty")
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package tx
+
+import (
+	"fmt"
+	"pkg.world.dev/world-engine/cardinal"
+)
+
+// Stat branches of a planet that can be upgraded
+const (
+	UpgradeRange   = "range"
+	UpgradeSpeed   = "speed"
+	UpgradeDefense = "defense"
+)
+
+type UpgradePlanetMsg struct {
+	LocationHash string `json:"locationHash"`
+	// One of UpgradeRange, UpgradeSpeed or UpgradeDefense
+	Branch string `json:"branch"`
+}
+
+type UpgradePlanetReply struct {
+	Planet      PlanetReceipt `json:"planet"`
+	EnergySpent string        `json:"energySpent"`
+}
+
+var UpgradePlanet = cardinal.NewMessageTypeWithEVMSupport[UpgradePlanetMsg, UpgradePlanetReply]("upgrade-planet")
+
+func (msg UpgradePlanetMsg) Validate() error {
+	// Check that LocationHash is 64 characters long
+	if len(msg.LocationHash) != 64 {
+		return fmt.Errorf("location hash length was not 64 chars: %s", msg.LocationHash)
+	}
+
+	switch msg.Branch {
+	case UpgradeRange, UpgradeSpeed, UpgradeDefense:
+		return nil
+	default:
+		return fmt.Errorf("invalid upgrade branch %q, must be one of %s, %s or %s", msg.Branch, UpgradeRange, UpgradeSpeed, UpgradeDefense)
+	}
+}
@@ -99,6 +99,22 @@ func EnergyOnArrivalAtFriendlyPlanet(energyOnEmbark *decimal.Big) *decimal.Big {
 	return energyOnEmbark
 }
 
+// UpgradedStat raises a level and space area adjusted stat of a planet by its upgrade rank
+func UpgradedStat(stat *decimal.Big, rank int64) *decimal.Big {
+	if rank == 0 {
+		return stat
+	}
+	increase := new(decimal.Big).Mul(Int64ToDec(rank), StrToDec(game.PlanetUpgradeConstants.StatIncreasePerRank))
+	return new(decimal.Big).Mul(stat, new(decimal.Big).Add(IntToDec(1), increase))
+}
+
+// EnergyAfterRecall calculates the energy a recalled ship brings back to its origin planet,
+// the recall penalty is the fraction of the energy that is lost
+func EnergyAfterRecall(energyOnEmbark *decimal.Big, recallPenalty *decimal.Big) *decimal.Big {
+	kept := new(decimal.Big).Sub(IntToDec(1), recallPenalty)
+	return new(decimal.Big).Mul(energyOnEmbark, kept)
+}
+
 // EnergyAfterDefenseDebuff calculates the energy that will be subtracted from enemy or unclaimed planet when a ship arrives
 // which takes into account the enemy planet's defense debuff
 func EnergyAfterDefenseDebuff(energyOnEmbark *decimal.Big, destinationPlanetDefense *decimal.Big) *decimal.Big {
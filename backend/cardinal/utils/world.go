@@ -109,5 +109,15 @@ func SetConstantsFromEnv() error {
 	}
 	game.WorldConstants.RadiusMax = int64(radiusInt)
 
+	// Set RimSpawn
+	rimSpawn := os.Getenv("RIM_SPAWN")
+	if rimSpawn != "" {
+		rimSpawnBool, err := strconv.ParseBool(rimSpawn)
+		if err != nil {
+			return fmt.Errorf("RIM_SPAWN was set to an invalid value: %s", rimSpawn)
+		}
+		game.WorldConstants.RimSpawn = rimSpawnBool
+	}
+
 	return nil
 }
@@ -1,21 +1,62 @@
 package artifacts
 
-import _ "embed"
+import (
+	_ "embed"
 
-//go:embed init-084d8871-4cdb-46ab-b541-298cde6f9236-cs
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+)
+
+// UUID identifies the embedded artifacts, see CircuitArtifactUUID in game.WorldConstants
+const UUID = "376f02d4-1c5c-44f6-b2b5-86657dd15e0e"
+
+// ProofSystem the embedded artifacts were generated for, see proofsystem.Get
+const ProofSystem = "groth16"
+
+// Keys the embedded artifacts were compiled with, see the MiMC and perlin constants in game.WorldConstants
+var Keys = prover.Keys{
+	PlanetHashKey: "1",
+	SpaceTypeKey:  "1",
+	MiMCNumRounds: 110,
+	PerlinProfile: perlin.Profile{
+		Weights:   []int64{2, 1, 1},
+		Buckets:   16,
+		NumRounds: 4,
+	},
+}
+
+//go:embed init-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-cs
 var InitConstraintSystem []byte
 
-//go:embed init-084d8871-4cdb-46ab-b541-298cde6f9236-pk
+//go:embed init-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-pk
 var InitProvingKey []byte
 
-//go:embed init-084d8871-4cdb-46ab-b541-298cde6f9236-vk
+//go:embed init-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-vk
 var InitVerifyingKey []byte
 
-//go:embed move-084d8871-4cdb-46ab-b541-298cde6f9236-cs
+//go:embed init-rim-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-cs
+var InitRimConstraintSystem []byte
+
+//go:embed init-rim-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-pk
+var InitRimProvingKey []byte
+
+//go:embed init-rim-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-vk
+var InitRimVerifyingKey []byte
+
+//go:embed move-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-cs
 var MoveConstraintSystem []byte
 
-//go:embed move-084d8871-4cdb-46ab-b541-298cde6f9236-pk
+//go:embed move-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-pk
 var MoveProvingKey []byte
 
-//go:embed move-084d8871-4cdb-46ab-b541-298cde6f9236-vk
+//go:embed move-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-vk
 var MoveVerifyingKey []byte
+
+//go:embed reveal-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-cs
+var RevealConstraintSystem []byte
+
+//go:embed reveal-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-pk
+var RevealProvingKey []byte
+
+//go:embed reveal-376f02d4-1c5c-44f6-b2b5-86657dd15e0e-vk
+var RevealVerifyingKey []byte
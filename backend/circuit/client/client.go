@@ -0,0 +1,243 @@
+// Package client builds ready-to-sign cardinal messages for Go clients such as bots and tooling.
+//
+// Given the world constants from the `constant` query and private coordinates, a Client
+// computes location hashes, perlin values and distances, proves with the circuit artifacts
+// and fills in every field of claim-home-planet, send-energy and reveal-location messages,
+// artifactVersion included. Signing and submitting the messages is left to the caller.
+//
+//	constants, err := client.ParseWorldConstants(reply) // reply of the constant query for "world"
+//	c, err := client.New(constants)
+//	msg, err := c.ClaimHomePlanet(client.Coords{X: 3, Y: -3})
+//	// sign msg as msg.Name() and submit it
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit/artifacts"
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+)
+
+// WorldConstantLabel is the constantLabel to pass to the constant query
+const WorldConstantLabel = "world"
+
+// WorldConstants are the fields of game.WorldConstants a client needs, as returned by the constant query
+type WorldConstants struct {
+	MiMCSeedWord                 string
+	PerlinSeedWord               string
+	MiMCNumRounds                int
+	PerlinNumRounds              int
+	PerlinOctaveWeights          []int64
+	PerlinBuckets                int64
+	XMirror                      int64
+	YMirror                      int64
+	Scale                        int64
+	CircuitArtifactUUID          string
+	AcceptedCircuitArtifactUUIDs []string
+	RadiusMax                    int64
+	RimSpawn                     bool
+}
+
+// ParseWorldConstants decodes the reply of the constant query for WorldConstantLabel
+func ParseWorldConstants(reply []byte) (WorldConstants, error) {
+	var decoded struct {
+		Constants *WorldConstants `json:"constants"`
+	}
+	if err := json.Unmarshal(reply, &decoded); err != nil {
+		return WorldConstants{}, fmt.Errorf("failed to decode constant reply: %w", err)
+	}
+	if decoded.Constants == nil {
+		return WorldConstants{}, fmt.Errorf("constant reply has no constants")
+	}
+	return *decoded.Constants, nil
+}
+
+// Keys the circuits of the world were compiled with
+func (c WorldConstants) Keys() prover.Keys {
+	return prover.Keys{
+		PlanetHashKey: c.MiMCSeedWord,
+		SpaceTypeKey:  c.PerlinSeedWord,
+		MiMCNumRounds: c.MiMCNumRounds,
+		PerlinProfile: perlin.Profile{
+			Weights:   c.PerlinOctaveWeights,
+			Buckets:   c.PerlinBuckets,
+			NumRounds: c.PerlinNumRounds,
+		},
+	}
+}
+
+// Params are the public inputs of the world
+func (c WorldConstants) Params() prover.Params {
+	return prover.Params{Scale: c.Scale, XMirror: c.XMirror, YMirror: c.YMirror}
+}
+
+// acceptsArtifactVersion mirrors tx.IsCircuitArtifactAccepted
+func (c WorldConstants) acceptsArtifactVersion(version string) bool {
+	if version == c.CircuitArtifactUUID {
+		return true
+	}
+	for _, accepted := range c.AcceptedCircuitArtifactUUIDs {
+		if version == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+type Coords struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+}
+
+// Same shape as tx.ClaimHomePlanetMsg
+type ClaimHomePlanetMsg struct {
+	prover.ClaimHomePlanetMsg
+	ArtifactVersion string `json:"artifactVersion,omitempty"`
+}
+
+// Name of the message to sign the payload as
+func (ClaimHomePlanetMsg) Name() string {
+	return "claim-home-planet"
+}
+
+// Same shape as tx.SendEnergyMsg
+type SendEnergyMsg struct {
+	prover.SendEnergyMsg
+	ArtifactVersion string `json:"artifactVersion,omitempty"`
+}
+
+// Name of the message to sign the payload as
+func (SendEnergyMsg) Name() string {
+	return "send-energy"
+}
+
+// Same shape as tx.RevealLocationMsg
+type RevealLocationMsg struct {
+	prover.RevealLocationMsg
+	ArtifactVersion string `json:"artifactVersion,omitempty"`
+}
+
+// Name of the message to sign the payload as
+func (RevealLocationMsg) Name() string {
+	return "reveal-location"
+}
+
+type Client struct {
+	constants       WorldConstants
+	prover          *prover.Prover
+	artifactVersion string
+}
+
+// New returns a client that proves with the embedded circuit artifacts. The world must
+// accept their version, either as CircuitArtifactUUID or in AcceptedCircuitArtifactUUIDs.
+func New(constants WorldConstants) (*Client, error) {
+	if !constants.acceptsArtifactVersion(artifacts.UUID) {
+		return nil, fmt.Errorf(
+			"embedded circuit artifact version %s is not accepted by the world, which uses %s",
+			artifacts.UUID, constants.CircuitArtifactUUID,
+		)
+	}
+
+	ps, err := proofsystem.Get(artifacts.ProofSystem)
+	if err != nil {
+		return nil, err
+	}
+	p, err := prover.NewProver(
+		ps,
+		constants.Keys(),
+		artifacts.InitConstraintSystem,
+		artifacts.InitProvingKey,
+		artifacts.MoveConstraintSystem,
+		artifacts.MoveProvingKey,
+		artifacts.RevealConstraintSystem,
+		artifacts.RevealProvingKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if constants.RimSpawn {
+		if err = p.LoadRimSpawnInit(artifacts.InitRimConstraintSystem, artifacts.InitRimProvingKey); err != nil {
+			return nil, err
+		}
+	}
+
+	return NewWithProver(constants, p, artifacts.UUID), nil
+}
+
+// NewWithProver returns a client that proves with p, which must have been created with
+// constants.Keys() and artifacts of artifactVersion
+func NewWithProver(constants WorldConstants, p *prover.Prover, artifactVersion string) *Client {
+	return &Client{constants: constants, prover: p, artifactVersion: artifactVersion}
+}
+
+// LocationHash returns the location hash of the planet at coords
+func (c *Client) LocationHash(coords Coords) string {
+	return c.prover.LocationHash(coords.X, coords.Y)
+}
+
+// Perlin returns the perlin value of the planet at coords
+func (c *Client) Perlin(coords Coords) (int64, error) {
+	return c.prover.Perlin(coords.X, coords.Y, c.constants.Params())
+}
+
+// Distance returns the smallest maxDistance a move from `from` to `to` can be proven for,
+// i.e. sqrt((x1-x2)^2 + (y1-y2)^2) rounded up
+func Distance(from, to Coords) int64 {
+	dx := new(big.Int).Sub(big.NewInt(from.X), big.NewInt(to.X))
+	dy := new(big.Int).Sub(big.NewInt(from.Y), big.NewInt(to.Y))
+	distSq := dx.Mul(dx, dx)
+	distSq.Add(distSq, dy.Mul(dy, dy))
+
+	dist := new(big.Int).Sqrt(distSq)
+	if new(big.Int).Mul(dist, dist).Cmp(distSq) < 0 {
+		dist.Add(dist, big.NewInt(1))
+	}
+	return dist.Int64()
+}
+
+// ClaimHomePlanet proves that home is inside the world radius (and in its rim if RimSpawn is set)
+func (c *Client) ClaimHomePlanet(home Coords) (ClaimHomePlanetMsg, error) {
+	msg, err := c.prover.ProveInit(prover.InitInput{
+		X:        home.X,
+		Y:        home.Y,
+		R:        c.constants.RadiusMax,
+		RimSpawn: c.constants.RimSpawn,
+		Params:   c.constants.Params(),
+	})
+	if err != nil {
+		return ClaimHomePlanetMsg{}, err
+	}
+	return ClaimHomePlanetMsg{ClaimHomePlanetMsg: msg, ArtifactVersion: c.artifactVersion}, nil
+}
+
+// SendEnergy proves a move over the shortest provable distance, see Distance. Cardinal rejects
+// the message if that distance exceeds the range of the planet at `from`.
+func (c *Client) SendEnergy(from, to Coords, energy int64) (SendEnergyMsg, error) {
+	msg, err := c.prover.ProveMove(prover.MoveInput{
+		X1:      from.X,
+		Y1:      from.Y,
+		X2:      to.X,
+		Y2:      to.Y,
+		R:       c.constants.RadiusMax,
+		DistMax: Distance(from, to),
+		Energy:  energy,
+		Params:  c.constants.Params(),
+	})
+	if err != nil {
+		return SendEnergyMsg{}, err
+	}
+	return SendEnergyMsg{SendEnergyMsg: msg, ArtifactVersion: c.artifactVersion}, nil
+}
+
+// RevealLocation proves the coordinates of the planet at coords
+func (c *Client) RevealLocation(coords Coords) (RevealLocationMsg, error) {
+	msg, err := c.prover.ProveReveal(prover.RevealInput{X: coords.X, Y: coords.Y})
+	if err != nil {
+		return RevealLocationMsg{}, err
+	}
+	return RevealLocationMsg{RevealLocationMsg: msg, ArtifactVersion: c.artifactVersion}, nil
+}
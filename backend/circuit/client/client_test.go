@@ -0,0 +1,275 @@
+package client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit"
+	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+const testArtifactVersion = "00000000-0000-4000-8000-000000000000"
+
+// Reply of the constant query for "world", as cardinal marshals game.WorldConstants
+var testReply = []byte(`{"constants": {
+	"MiMCSeedWord": "7",
+	"PerlinSeedWord": "7",
+	"MiMCNumRounds": 110,
+	"PerlinNumRounds": 4,
+	"PerlinOctaveWeights": [2, 1, 1],
+	"PerlinBuckets": 16,
+	"XMirror": 0,
+	"YMirror": 0,
+	"Scale": 16,
+	"CircuitArtifactUUID": "00000000-0000-4000-8000-000000000000",
+	"AcceptedCircuitArtifactUUIDs": [],
+	"RadiusMax": 500,
+	"SpacePerlinThresholds": [15, 17],
+	"InstanceName": "dark-frontier",
+	"InstanceTimer": 1209600,
+	"TickRate": 2,
+	"RimSpawn": false
+}}`)
+
+var (
+	setupOnce                sync.Once
+	testClient               *Client
+	initVK, moveVK, revealVK proofsystem.VerifyingKey
+)
+
+// Compiles the circuits with the keys of testReply
+func setup(t *testing.T) {
+	setupOnce.Do(func() {
+		constants, err := ParseWorldConstants(testReply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys := constants.Keys()
+		ps := proofsystem.Groth16
+
+		var initCircuit initialize.InitCircuit
+		initCircuit.PlanetHashKey = keys.PlanetHashKey
+		initCircuit.SpaceTypeKey = keys.SpaceTypeKey
+		initCircuit.MiMCNumRounds = keys.MiMCNumRounds
+		initCircuit.PerlinProfile = keys.PerlinProfile
+		initCS, err := ps.Compile(&initCircuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		initPK, vk, err := ps.Setup(initCS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		initVK = vk
+
+		var moveCircuit move.MoveCircuit
+		moveCircuit.PlanetHashKey = keys.PlanetHashKey
+		moveCircuit.SpaceTypeKey = keys.SpaceTypeKey
+		moveCircuit.MiMCNumRounds = keys.MiMCNumRounds
+		moveCircuit.PerlinProfile = keys.PerlinProfile
+		moveCS, err := ps.Compile(&moveCircuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		movePK, vk, err := ps.Setup(moveCS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moveVK = vk
+
+		var revealCircuit reveal.RevealCircuit
+		revealCircuit.PlanetHashKey = keys.PlanetHashKey
+		revealCircuit.MiMCNumRounds = keys.MiMCNumRounds
+		revealCS, err := ps.Compile(&revealCircuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revealPK, vk, err := ps.Setup(revealCS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revealVK = vk
+
+		p := prover.NewProverFromKeys(ps, keys, initCS, initPK, moveCS, movePK, revealCS, revealPK)
+		testClient = NewWithProver(constants, p, testArtifactVersion)
+	})
+	if testClient == nil {
+		t.Fatal("client setup failed")
+	}
+}
+
+// Verifies the proof against the public inputs cardinal rebuilds from the message
+func verify(t *testing.T, vk proofsystem.VerifyingKey, encoded string, publicAssignment frontend.Circuit) error {
+	proofBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := proofsystem.ReadProof(proofsystem.Groth16, proofBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicWitness, err := frontend.NewWitness(publicAssignment, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return proofsystem.Groth16.Verify(proof, vk, publicWitness)
+}
+
+func hashToBigInt(locationHash string) *big.Int {
+	pub, _ := new(big.Int).SetString(locationHash, 16)
+	return pub
+}
+
+func TestParseWorldConstants(t *testing.T) {
+	constants, err := ParseWorldConstants(testReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := constants.Keys()
+	if keys.PlanetHashKey != "7" || keys.SpaceTypeKey != "7" || keys.MiMCNumRounds != 110 {
+		t.Fatalf("unexpected keys %+v", keys)
+	}
+	if err = keys.PerlinProfile.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if constants.Params() != (prover.Params{Scale: 16}) || constants.RadiusMax != 500 {
+		t.Fatalf("unexpected constants %+v", constants)
+	}
+
+	if _, err = ParseWorldConstants([]byte(`{"constants": null}`)); err == nil {
+		t.Fatal("expected error for a reply without constants")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		from, to Coords
+		want     int64
+	}{
+		{Coords{0, 0}, Coords{0, 0}, 0},
+		{Coords{0, 0}, Coords{3, 4}, 5},
+		// sqrt(113) ~ 10.6
+		{Coords{3, -3}, Coords{11, -10}, 11},
+		{Coords{-1 << 31, 0}, Coords{1 << 31, 0}, 1 << 32},
+	}
+	for _, test := range tests {
+		if got := Distance(test.from, test.to); got != test.want {
+			t.Errorf("Distance(%v, %v) = %d, want %d", test.from, test.to, got, test.want)
+		}
+	}
+}
+
+func TestNewRejectsUnacceptedArtifactVersion(t *testing.T) {
+	constants, err := ParseWorldConstants(testReply)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = New(constants); err == nil || !strings.Contains(err.Error(), "is not accepted") {
+		t.Fatalf("expected error for an artifact version the world does not accept, got %v", err)
+	}
+}
+
+func TestClaimHomePlanet(t *testing.T) {
+	setup(t)
+
+	msg, err := testClient.ClaimHomePlanet(Coords{X: 3, Y: -3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.LocationHash != testClient.LocationHash(Coords{X: 3, Y: -3}) || msg.Perlin != 16 || msg.Radius != 500 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+
+	err = verify(t, initVK, msg.Proof, &initialize.InitCircuit{
+		R:       msg.Radius,
+		Scale:   circuit.Scale,
+		XMirror: circuit.XMirror,
+		YMirror: circuit.YMirror,
+		Pub:     hashToBigInt(msg.LocationHash),
+		Perl:    msg.Perlin,
+	})
+	if err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+
+	// the JSON must be the payload of tx.ClaimHomePlanetMsg
+	var payload map[string]any
+	bz, _ := json.Marshal(msg)
+	if err = json.Unmarshal(bz, &payload); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"locationHash", "perlin", "radius", "proof", "artifactVersion"} {
+		if _, ok := payload[field]; !ok {
+			t.Fatalf("payload %s has no %s", bz, field)
+		}
+	}
+	if msg.Name() != "claim-home-planet" {
+		t.Fatalf("unexpected message name %s", msg.Name())
+	}
+}
+
+func TestSendEnergy(t *testing.T) {
+	setup(t)
+
+	from, to := Coords{X: 3, Y: -3}, Coords{X: 11, Y: -10}
+	msg, err := testClient.SendEnergy(from, to, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	perlinTo, err := testClient.Perlin(to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.LocationHashFrom != testClient.LocationHash(from) || msg.LocationHashTo != testClient.LocationHash(to) ||
+		msg.PerlinTo != perlinTo || msg.MaxDistance != 11 || msg.Energy != 42 || msg.ArtifactVersion != testArtifactVersion {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+
+	err = verify(t, moveVK, msg.Proof, &move.MoveCircuit{
+		R:       msg.RadiusTo,
+		DistMax: msg.MaxDistance,
+		Scale:   circuit.Scale,
+		XMirror: circuit.XMirror,
+		YMirror: circuit.YMirror,
+		Pub1:    hashToBigInt(msg.LocationHashFrom),
+		Pub2:    hashToBigInt(msg.LocationHashTo),
+		Perl2:   msg.PerlinTo,
+		Nonce:   msg.Nonce,
+	})
+	if err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+}
+
+func TestRevealLocation(t *testing.T) {
+	setup(t)
+
+	msg, err := testClient.RevealLocation(Coords{X: 3, Y: -3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = verify(t, revealVK, msg.Proof, &reveal.RevealCircuit{
+		X:   msg.X,
+		Y:   msg.Y,
+		Pub: hashToBigInt(msg.LocationHash),
+	})
+	if err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+
+	if _, err = testClient.RevealLocation(Coords{X: 1 << 32, Y: 0}); err == nil {
+		t.Fatal("expected error for coordinates out of range")
+	}
+}
@@ -0,0 +1,261 @@
+// Command build-artifacts compiles the init, rim-spawn init, move and reveal circuits with the given keys
+// and terrain profile, runs the setup of the chosen proof system and writes init-/init-rim-/move-/reveal-<uuid>-cs/pk/vk
+// into the artifacts directory, tagged by proofsystem-<uuid> and params-<uuid>. It then regenerates
+// artifacts/importer.go to embed the new files and prints the UUID to set as
+// CircuitArtifactUUID in game.WorldConstants.
+//
+//	go run ./cmd/build-artifacts -planet-hash-key 7 -space-type-key 7 -proof-system plonk
+//	go run ./cmd/build-artifacts -perlin-weights 3,2,1,1 -perlin-buckets 10
+//
+// The keys, MiMC rounds and perlin profile must match MiMCSeedWord, PerlinSeedWord, MiMCNumRounds,
+// PerlinNumRounds, PerlinOctaveWeights and PerlinBuckets in game.WorldConstants, cardinal checks
+// them against params-<uuid> and the embedded artifacts when it starts.
+//
+// Setup runs locally, so the resulting keys are only as trustworthy as the machine
+// they were generated on. Good enough for private instances and testing.
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit"
+	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
+	mimcbn254 "github.com/argus-labs/darkfrontier-backend/circuit/mimc"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
+	"github.com/consensys/gnark/frontend"
+)
+
+var importerTemplate = template.Must(template.New("importer").Parse(`package artifacts
+
+import (
+	_ "embed"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+)
+
+// UUID identifies the embedded artifacts, see CircuitArtifactUUID in game.WorldConstants
+const UUID = "{{.UUID}}"
+
+// ProofSystem the embedded artifacts were generated for, see proofsystem.Get
+const ProofSystem = "{{.ProofSystem}}"
+
+// Keys the embedded artifacts were compiled with, see the MiMC and perlin constants in game.WorldConstants
+var Keys = prover.Keys{
+	PlanetHashKey: {{printf "%q" .Keys.PlanetHashKey}},
+	SpaceTypeKey:  {{printf "%q" .Keys.SpaceTypeKey}},
+	MiMCNumRounds: {{.Keys.MiMCNumRounds}},
+	PerlinProfile: perlin.Profile{
+		Weights:   []int64{ {{- .Weights -}} },
+		Buckets:   {{.Keys.PerlinProfile.Buckets}},
+		NumRounds: {{.Keys.PerlinProfile.NumRounds}},
+	},
+}
+
+//go:embed init-{{.UUID}}-cs
+var InitConstraintSystem []byte
+
+//go:embed init-{{.UUID}}-pk
+var InitProvingKey []byte
+
+//go:embed init-{{.UUID}}-vk
+var InitVerifyingKey []byte
+
+//go:embed init-rim-{{.UUID}}-cs
+var InitRimConstraintSystem []byte
+
+//go:embed init-rim-{{.UUID}}-pk
+var InitRimProvingKey []byte
+
+//go:embed init-rim-{{.UUID}}-vk
+var InitRimVerifyingKey []byte
+
+//go:embed move-{{.UUID}}-cs
+var MoveConstraintSystem []byte
+
+//go:embed move-{{.UUID}}-pk
+var MoveProvingKey []byte
+
+//go:embed move-{{.UUID}}-vk
+var MoveVerifyingKey []byte
+
+//go:embed reveal-{{.UUID}}-cs
+var RevealConstraintSystem []byte
+
+//go:embed reveal-{{.UUID}}-pk
+var RevealProvingKey []byte
+
+//go:embed reveal-{{.UUID}}-vk
+var RevealVerifyingKey []byte
+`))
+
+func main() {
+	planetHashKey := flag.String("planet-hash-key", circuit.PlanetHashKey, "MiMC seed for location hashes (MiMCSeedWord)")
+	spaceTypeKey := flag.String("space-type-key", circuit.SpaceTypeKey, "MiMC seed for perlin noise (PerlinSeedWord)")
+	dir := flag.String("out", "artifacts", "artifacts directory, importer.go is regenerated in it")
+	id := flag.String("uuid", "", "artifact UUID to use instead of a fresh one")
+	proofSystem := flag.String("proof-system", proofsystem.Groth16Name, "groth16 or plonk")
+	mimcNumRounds := flag.Int("mimc-rounds", mimcbn254.DefaultNumRounds, "MiMC rounds of location hashes (MiMCNumRounds)")
+	perlinNumRounds := flag.Int("perlin-rounds", perlin.DefaultProfile.NumRounds, "MiMC rounds of perlin noise (PerlinNumRounds)")
+	perlinWeights := flag.String(
+		"perlin-weights", perlin.FormatWeights(perlin.DefaultProfile.Weights), "comma separated octave weights (PerlinOctaveWeights)",
+	)
+	perlinBuckets := flag.Int64("perlin-buckets", perlin.DefaultProfile.Buckets, "perlin values are in [0, 2*buckets] (PerlinBuckets)")
+	flag.Parse()
+
+	ps, err := proofsystem.Get(*proofSystem)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	weights, err := perlin.ParseWeights(*perlinWeights)
+	if err != nil {
+		log.Fatal(err)
+	}
+	profile := perlin.Profile{Weights: weights, Buckets: *perlinBuckets, NumRounds: *perlinNumRounds}
+	if err = profile.Validate(); err != nil {
+		log.Fatal(err)
+	}
+	if *mimcNumRounds <= 0 {
+		log.Fatalf("number of MiMC rounds must be positive, got %d", *mimcNumRounds)
+	}
+
+	if *id == "" {
+		if *id, err = newUUID(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var initCircuit initialize.InitCircuit
+	initCircuit.PlanetHashKey = *planetHashKey
+	initCircuit.SpaceTypeKey = *spaceTypeKey
+	initCircuit.MiMCNumRounds = *mimcNumRounds
+	initCircuit.PerlinProfile = profile
+	if err = build(ps, &initCircuit, *dir, "init", *id); err != nil {
+		log.Fatalf("init circuit: %s", err)
+	}
+
+	initRimCircuit := initCircuit
+	initRimCircuit.RimSpawn = true
+	if err = build(ps, &initRimCircuit, *dir, "init-rim", *id); err != nil {
+		log.Fatalf("rim-spawn init circuit: %s", err)
+	}
+
+	var moveCircuit move.MoveCircuit
+	moveCircuit.PlanetHashKey = *planetHashKey
+	moveCircuit.SpaceTypeKey = *spaceTypeKey
+	moveCircuit.MiMCNumRounds = *mimcNumRounds
+	moveCircuit.PerlinProfile = profile
+	if err = build(ps, &moveCircuit, *dir, "move", *id); err != nil {
+		log.Fatalf("move circuit: %s", err)
+	}
+
+	var revealCircuit reveal.RevealCircuit
+	revealCircuit.PlanetHashKey = *planetHashKey
+	revealCircuit.MiMCNumRounds = *mimcNumRounds
+	if err = build(ps, &revealCircuit, *dir, "reveal", *id); err != nil {
+		log.Fatalf("reveal circuit: %s", err)
+	}
+
+	tag := filepath.Join(*dir, fmt.Sprintf("proofsystem-%s", *id))
+	if err = os.WriteFile(tag, []byte(ps.Name()), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	keys := prover.Keys{
+		PlanetHashKey: *planetHashKey,
+		SpaceTypeKey:  *spaceTypeKey,
+		MiMCNumRounds: *mimcNumRounds,
+		PerlinProfile: profile,
+	}
+	params, err := json.Marshal(keys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(*dir, fmt.Sprintf("params-%s", *id)), params, 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	f, err := os.Create(filepath.Join(*dir, "importer.go"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	if err = writeImporter(f, *id, ps.Name(), keys); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("wrote artifacts to %s", *dir)
+	// Only the UUID goes to stdout so it can be captured by scripts
+	fmt.Println(*id)
+}
+
+// Compiles the circuit, runs the setup and writes <prefix>-<id>-cs/pk/vk
+func build(ps proofsystem.ProofSystem, c frontend.Circuit, dir, prefix, id string) error {
+	log.Printf("compiling %s circuit for %s", prefix, ps.Name())
+	ccs, err := ps.Compile(c)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("running setup for %s circuit (%d constraints)", prefix, ccs.GetNbConstraints())
+	pk, vk, err := ps.Setup(ccs)
+	if err != nil {
+		return err
+	}
+
+	for suffix, obj := range map[string]io.WriterTo{"cs": ccs, "pk": pk, "vk": vk} {
+		if err = writeArtifact(filepath.Join(dir, fmt.Sprintf("%s-%s-%s", prefix, id, suffix)), obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeArtifact(path string, obj io.WriterTo) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err = obj.WriteTo(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func writeImporter(w io.Writer, id, proofSystem string, keys prover.Keys) error {
+	weights := make([]string, len(keys.PerlinProfile.Weights))
+	for i, weight := range keys.PerlinProfile.Weights {
+		weights[i] = fmt.Sprint(weight)
+	}
+	return importerTemplate.Execute(w, struct {
+		UUID, ProofSystem string
+		Keys              prover.Keys
+		Weights           string
+	}{id, proofSystem, keys, strings.Join(weights, ", ")})
+}
+
+// random (version 4) UUID
+func newUUID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+)
+
+func TestNewUUID(t *testing.T) {
+	re := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, err := newUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := newUUID()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !re.MatchString(first) {
+		t.Fatalf("%s is not a version 4 UUID", first)
+	}
+	if first == second {
+		t.Fatal("expected a fresh UUID on every call")
+	}
+}
+
+// The generated importer must match the committed one for the UUID it embeds
+func TestWriteImporter(t *testing.T) {
+	expected, err := os.ReadFile("../../artifacts/importer.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := regexp.MustCompile(`init-([0-9a-f-]{36})-cs`).FindSubmatch(expected)
+	if id == nil {
+		t.Fatal("no artifact UUID in importer.go")
+	}
+	proofSystem := regexp.MustCompile(`ProofSystem = "(\w+)"`).FindSubmatch(expected)
+	if proofSystem == nil {
+		t.Fatal("no proof system in importer.go")
+	}
+
+	keys := regexp.MustCompile(
+		`PlanetHashKey: "(\w*)",\s+SpaceTypeKey:  "(\w*)",\s+MiMCNumRounds: (\d+),[\s\S]*Weights:   \[\]int64\{([\d, ]+)\},\s+Buckets:   (\d+),\s+NumRounds: (\d+),`,
+	).FindSubmatch(expected)
+	if keys == nil {
+		t.Fatal("no keys in importer.go")
+	}
+	mimcNumRounds, _ := strconv.Atoi(string(keys[3]))
+	weights, err := perlin.ParseWeights(strings.ReplaceAll(string(keys[4]), " ", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	buckets, _ := strconv.ParseInt(string(keys[5]), 10, 64)
+	perlinNumRounds, _ := strconv.Atoi(string(keys[6]))
+
+	var buf bytes.Buffer
+	err = writeImporter(&buf, string(id[1]), string(proofSystem[1]), prover.Keys{
+		PlanetHashKey: string(keys[1]),
+		SpaceTypeKey:  string(keys[2]),
+		MiMCNumRounds: mimcNumRounds,
+		PerlinProfile: perlin.Profile{Weights: weights, Buckets: buckets, NumRounds: perlinNumRounds},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != string(expected) {
+		t.Fatalf("generated importer differs from artifacts/importer.go:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,137 @@
+// Command prover generates init, move and reveal proofs from the embedded circuit artifacts.
+//
+//	prover init -x 3 -y -3 -r 500
+//	prover init -x 8 -y 4 -r 9 -rim
+//	prover move -x1 3 -y1 -3 -x2 11 -y2 -10 -r 500 -distmax 20 -energy 100
+//	prover reveal -x 3 -y -3
+//	prover serve -addr :8081
+//
+// init, move and reveal print the message JSON (claim-home-planet / send-energy /
+// reveal-location) to stdout.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit"
+	"github.com/argus-labs/darkfrontier-backend/circuit/artifacts"
+	mimcbn254 "github.com/argus-labs/darkfrontier-backend/circuit/mimc"
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/prover"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %s <init|move|reveal|serve> [flags]\n", os.Args[0])
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	fs := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	planetHashKey := fs.String("planet-hash-key", circuit.PlanetHashKey, "key the circuits were compiled with (MiMCSeedWord)")
+	spaceTypeKey := fs.String("space-type-key", circuit.SpaceTypeKey, "key the circuits were compiled with (PerlinSeedWord)")
+	scale := fs.Int64("scale", circuit.Scale, "perlin scale")
+	xMirror := fs.Int64("xmirror", circuit.XMirror, "1 to mirror along the horizontal axis")
+	yMirror := fs.Int64("ymirror", circuit.YMirror, "1 to mirror along the vertical axis")
+	mimcNumRounds := fs.Int("mimc-rounds", mimcbn254.DefaultNumRounds, "MiMC rounds the circuits were compiled with (MiMCNumRounds)")
+	perlinNumRounds := fs.Int(
+		"perlin-rounds", perlin.DefaultProfile.NumRounds, "perlin MiMC rounds the circuits were compiled with (PerlinNumRounds)",
+	)
+	perlinWeights := fs.String(
+		"perlin-weights", perlin.FormatWeights(perlin.DefaultProfile.Weights), "octave weights the circuits were compiled with (PerlinOctaveWeights)",
+	)
+	perlinBuckets := fs.Int64("perlin-buckets", perlin.DefaultProfile.Buckets, "perlin buckets the circuits were compiled with (PerlinBuckets)")
+
+	var run func(p *prover.Prover, params prover.Params) (any, error)
+	switch os.Args[1] {
+	case "init":
+		x := fs.Int64("x", 0, "x coordinate")
+		y := fs.Int64("y", 0, "y coordinate")
+		r := fs.Int64("r", circuit.RadiusMax, "world radius")
+		rim := fs.Bool("rim", false, "prove with the rim-spawn init circuit (RimSpawn)")
+		run = func(p *prover.Prover, params prover.Params) (any, error) {
+			return p.ProveInit(prover.InitInput{X: *x, Y: *y, R: *r, RimSpawn: *rim, Params: params})
+		}
+	case "move":
+		x1 := fs.Int64("x1", 0, "x coordinate of the source planet")
+		y1 := fs.Int64("y1", 0, "y coordinate of the source planet")
+		x2 := fs.Int64("x2", 0, "x coordinate of the target planet")
+		y2 := fs.Int64("y2", 0, "y coordinate of the target planet")
+		r := fs.Int64("r", circuit.RadiusMax, "world radius")
+		distMax := fs.Int64("distmax", 0, "range of the source planet")
+		energy := fs.Int64("energy", 0, "energy to send")
+		nonce := fs.Uint64("nonce", 0, "nonce of the move, 0 picks a random one")
+		run = func(p *prover.Prover, params prover.Params) (any, error) {
+			return p.ProveMove(prover.MoveInput{
+				X1: *x1, Y1: *y1, X2: *x2, Y2: *y2, R: *r, DistMax: *distMax, Energy: *energy, Nonce: *nonce, Params: params,
+			})
+		}
+	case "reveal":
+		x := fs.Int64("x", 0, "x coordinate")
+		y := fs.Int64("y", 0, "y coordinate")
+		run = func(p *prover.Prover, _ prover.Params) (any, error) {
+			return p.ProveReveal(prover.RevealInput{X: *x, Y: *y})
+		}
+	case "serve":
+		addr := fs.String("addr", ":8081", "address to listen on")
+		run = func(p *prover.Prover, params prover.Params) (any, error) {
+			log.Printf("prover listening on %s", *addr)
+			return nil, http.ListenAndServe(*addr, prover.NewHandler(p, params))
+		}
+	default:
+		usage()
+	}
+
+	_ = fs.Parse(os.Args[2:])
+
+	ps, err := proofsystem.Get(artifacts.ProofSystem)
+	if err != nil {
+		log.Fatal(err)
+	}
+	weights, err := perlin.ParseWeights(*perlinWeights)
+	if err != nil {
+		log.Fatal(err)
+	}
+	keys := prover.Keys{
+		PlanetHashKey: *planetHashKey,
+		SpaceTypeKey:  *spaceTypeKey,
+		MiMCNumRounds: *mimcNumRounds,
+		PerlinProfile: perlin.Profile{Weights: weights, Buckets: *perlinBuckets, NumRounds: *perlinNumRounds},
+	}
+	p, err := prover.NewProver(
+		ps,
+		keys,
+		artifacts.InitConstraintSystem,
+		artifacts.InitProvingKey,
+		artifacts.MoveConstraintSystem,
+		artifacts.MoveProvingKey,
+		artifacts.RevealConstraintSystem,
+		artifacts.RevealProvingKey,
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err = p.LoadRimSpawnInit(artifacts.InitRimConstraintSystem, artifacts.InitRimProvingKey); err != nil {
+		log.Fatal(err)
+	}
+
+	msg, err := run(p, prover.Params{Scale: *scale, XMirror: *xMirror, YMirror: *yMirror})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	out, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+}
@@ -1,8 +1,9 @@
 package circuit
 
+// Defaults of build-artifacts and the prover, the keys match MiMCSeedWord and PerlinSeedWord in game.WorldConstants
 const (
-	PlanetHashKey = "7"
-	SpaceTypeKey  = "7"
+	PlanetHashKey = "1"
+	SpaceTypeKey  = "1"
 	XMirror       = 0
 	YMirror       = 0
 	Scale         = 16
@@ -12,9 +12,14 @@ import (
 type InitCircuit struct {
 	X             frontend.Variable
 	Y             frontend.Variable
-	R             frontend.Variable
 	PlanetHashKey string
 	SpaceTypeKey  string
+	// Compiles the rim-spawn variant, which also checks x^2 + y^2 > 0.98 * r^2
+	RimSpawn bool
+	// Zero values fall back to mimcbn254.DefaultNumRounds and perlin.DefaultProfile
+	MiMCNumRounds int
+	PerlinProfile perlin.Profile
+	R             frontend.Variable `gnark:",public"`
 	Scale         frontend.Variable `gnark:",public"`
 	XMirror       frontend.Variable `gnark:",public"`
 	YMirror       frontend.Variable `gnark:",public"`
@@ -23,6 +28,11 @@ type InitCircuit struct {
 }
 
 func (circuit *InitCircuit) Define(api frontend.API) error {
+	mimcNumRounds := circuit.MiMCNumRounds
+	if mimcNumRounds == 0 {
+		mimcNumRounds = mimcbn254.DefaultNumRounds
+	}
+
 	pub, perl, err := Init(
 		api,
 		circuit.X,
@@ -30,10 +40,19 @@ func (circuit *InitCircuit) Define(api frontend.API) error {
 		circuit.R,
 		circuit.PlanetHashKey,
 		circuit.SpaceTypeKey,
+		mimcNumRounds,
+		circuit.PerlinProfile.OrDefault(),
 		circuit.Scale,
 		circuit.XMirror,
 		circuit.YMirror,
 	)
+	if err != nil {
+		return err
+	}
+
+	if circuit.RimSpawn {
+		RimSpawn(api, circuit.X, circuit.Y, circuit.R)
+	}
 
 	////////////////////////////
 	// Check MiMC(x, y) = pub //
@@ -45,7 +64,7 @@ func (circuit *InitCircuit) Define(api frontend.API) error {
 	///////////////////////////////
 	api.AssertIsEqual(perl, circuit.Perl)
 
-	return err
+	return nil
 }
 
 // Prove: I know (x,y) such that:
@@ -59,6 +78,8 @@ func Init(
 	r frontend.Variable,
 	planetHashKey string,
 	spaceTypeKey string,
+	mimcNumRounds int,
+	perlinProfile perlin.Profile,
 	// Must be a power of 2, at most 16384, so that DENOMINATOR works
 	scale frontend.Variable,
 	// 1 is true, 0 is false
@@ -94,15 +115,7 @@ func Init(
 		api.Sub(rSq, 1),
 	)
 
-	// When enabled, player can only spawn in the edges of the radius
-	///////////////////////////////////////////////
-	// Check x^2 + y^2 > 0.98 * r^2              //
-	// Equivalently 100 * (x^2 + y^2) > 98 * r^2 //
-	///////////////////////////////////////////////
-	// api.AssertIsLessOrEqual(
-	// 	api.Mul(rSq, 98),
-	// 	api.Sub(api.Mul(xSqPlusYSq, 100), 1),
-	// )
+	// The rim-spawn variant additionally checks x^2 + y^2 > 0.98 * r^2, see RimSpawn
 
 	////////////////////////////////////////////////////////////////////////////////////////
 	// check MiMCSponge(x,y) = pub                                                        //
@@ -113,9 +126,9 @@ func Init(
 	////////////////////////////////////////////////////////////////////////////////////////
 
 	// Circom MiMCSponge circuit uses 220 rounds b/c it uses MiMC-2n/n construction,
-	// this uses MiMC-n/n construction so will use 110 rounds
+	// this uses MiMC-n/n construction so will use 110 rounds (mimcbn254.DefaultNumRounds)
 	// Calculate mimc hash
-	mimc, err := mimcbn254.NewMiMC(api, planetHashKey, 110)
+	mimc, err := mimcbn254.NewMiMC(api, planetHashKey, mimcNumRounds)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -130,6 +143,7 @@ func Init(
 		xMirror,
 		yMirror,
 		spaceTypeKey,
+		perlinProfile,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -137,3 +151,23 @@ func Init(
 
 	return pub, perl, nil
 }
+
+// RimSpawnPercent of r^2 that x^2 + y^2 must exceed in the rim-spawn variant,
+// so that players can only spawn at the edge of the radius
+const RimSpawnPercent = 98
+
+// RimSpawn checks that (x, y) is in the outer rim of the radius.
+// x, y and r must already be range checked, as Init does.
+func RimSpawn(api frontend.API, x, y, r frontend.Variable) {
+	///////////////////////////////////////////////
+	// Check x^2 + y^2 > 0.98 * r^2              //
+	// Equivalently 100 * (x^2 + y^2) > 98 * r^2 //
+	///////////////////////////////////////////////
+	// Both sides are far below the modulus, so unlike subtracting 1 from the
+	// left hand side, adding 1 to the right hand side can't wrap around at (0, 0)
+	xSqPlusYSq := api.Add(api.Mul(x, x), api.Mul(y, y))
+	api.AssertIsLessOrEqual(
+		api.Add(api.Mul(r, r, RimSpawnPercent), 1),
+		api.Mul(xSqPlusYSq, 100),
+	)
+}
@@ -5,20 +5,28 @@ import (
 	"math/big"
 	"testing"
 
+	mimcbn254 "github.com/argus-labs/darkfrontier-backend/circuit/mimc"
+	"github.com/argus-labs/darkfrontier-backend/circuit/native"
 	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
 	"github.com/consensys/gnark-crypto/ecc"
 	"github.com/consensys/gnark/backend"
 	"github.com/consensys/gnark/test"
 )
 
+// Keys of the planets generated by the client, see cardinal/test/init.go
+const (
+	testPlanetHashKey = "7"
+	testSpaceTypeKey  = "7"
+)
+
 var (
 	initCircuit InitCircuit
 	pub         *big.Int
 )
 
 func init() {
-	initCircuit.PlanetHashKey = circuit.PlanetHashKey
-	initCircuit.SpaceTypeKey = circuit.SpaceTypeKey
+	initCircuit.PlanetHashKey = testPlanetHashKey
+	initCircuit.SpaceTypeKey = testSpaceTypeKey
 
 	var ok bool
 	// Generated by MiMCSharp
@@ -107,3 +115,67 @@ func TestMiMCConstraint(t *testing.T) {
 		test.WithCurves(ecc.BN254),
 	)
 }
+
+// Assignment for the rim-spawn variant with the planet at (8, 4), x^2 + y^2 = 80
+func rimSpawnAssignment(t *testing.T, r int64) *InitCircuit {
+	h := native.NewMiMC(testPlanetHashKey, mimcbn254.DefaultNumRounds)
+	h.WriteInt64(8, 4)
+	perl, err := native.MultiScalePerlin(
+		[2]int64{8, 4}, circuit.Scale, circuit.XMirror, circuit.YMirror, testSpaceTypeKey, perlin.DefaultProfile,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &InitCircuit{
+		X:       8,
+		Y:       4,
+		R:       r,
+		Scale:   circuit.Scale,
+		XMirror: circuit.XMirror,
+		YMirror: circuit.YMirror,
+		Pub:     h.Sum(),
+		Perl:    perl,
+	}
+}
+
+// Test that the rim-spawn variant accepts a point in the rim:
+// 100 * 80 > 98 * 9^2 and 80 <= 9^2 - 1
+func TestInitRimSpawn(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	rimCircuit := initCircuit
+	rimCircuit.RimSpawn = true
+
+	assert.ProverSucceeded(
+		&rimCircuit,
+		rimSpawnAssignment(t, 9),
+		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
+		test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+	)
+}
+
+// Test that the rim-spawn variant fails for a point inside the rim:
+// 100 * 80 <= 98 * 10^2, while the regular variant accepts it
+func TestInitRimSpawnInsideRim(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	rimCircuit := initCircuit
+	rimCircuit.RimSpawn = true
+
+	assert.ProverFailed(
+		&rimCircuit,
+		rimSpawnAssignment(t, 10),
+		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
+		test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+	)
+	assert.ProverSucceeded(
+		&initCircuit,
+		rimSpawnAssignment(t, 10),
+		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
+		test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+	)
+}
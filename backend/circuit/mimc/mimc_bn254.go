@@ -40,6 +40,9 @@ type MiMC struct {
 	api    frontend.API        // underlying constraint system
 }
 
+// DefaultNumRounds used for location hashes (ceil(log_5 p)), see MiMCNumRounds in game.WorldConstants
+const DefaultNumRounds = 110
+
 // NewMiMC returns a MiMC instance, than can be used in a gnark circuit
 // ASSUMES FRONTEND IS USING BN254 CURVE
 func NewMiMC(
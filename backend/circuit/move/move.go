@@ -16,6 +16,9 @@ type MoveCircuit struct {
 	Y2            frontend.Variable
 	PlanetHashKey string
 	SpaceTypeKey  string
+	// Zero values fall back to mimcbn254.DefaultNumRounds and perlin.DefaultProfile
+	MiMCNumRounds int
+	PerlinProfile perlin.Profile
 	R             frontend.Variable `gnark:",public"`
 	DistMax       frontend.Variable `gnark:",public"`
 	Scale         frontend.Variable `gnark:",public"` // Power of 2, Max 16384
@@ -24,9 +27,17 @@ type MoveCircuit struct {
 	Pub1          frontend.Variable `gnark:",public"`
 	Pub2          frontend.Variable `gnark:",public"`
 	Perl2         frontend.Variable `gnark:",public"`
+	// Chosen by the sender so that two moves along the same route have different public
+	// inputs, cardinal nullifies the public inputs and not the malleable proof
+	Nonce frontend.Variable `gnark:",public"`
 }
 
 func (circuit *MoveCircuit) Define(api frontend.API) error {
+	mimcNumRounds := circuit.MiMCNumRounds
+	if mimcNumRounds == 0 {
+		mimcNumRounds = mimcbn254.DefaultNumRounds
+	}
+
 	pub1, pub2, perl2, err := Move(
 		api,
 		circuit.X1,
@@ -35,6 +46,8 @@ func (circuit *MoveCircuit) Define(api frontend.API) error {
 		circuit.Y2,
 		circuit.PlanetHashKey,
 		circuit.SpaceTypeKey,
+		mimcNumRounds,
+		circuit.PerlinProfile.OrDefault(),
 		circuit.R,
 		circuit.DistMax,
 		circuit.Scale,
@@ -57,6 +70,9 @@ func (circuit *MoveCircuit) Define(api frontend.API) error {
 	////////////////////////////////
 	api.AssertIsEqual(perl2, circuit.Perl2)
 
+	// The nonce takes part in no check, squaring it keeps it constrained
+	api.Mul(circuit.Nonce, circuit.Nonce)
+
 	return err
 }
 
@@ -68,6 +84,8 @@ func Move(
 	y2 frontend.Variable,
 	planetHashKey string,
 	spaceTypeKey string,
+	mimcNumRounds int,
+	perlinProfile perlin.Profile,
 	r frontend.Variable,
 	distMax frontend.Variable,
 	scale frontend.Variable,
@@ -126,7 +144,7 @@ func Move(
 		distMaxSq,
 	)
 
-	mimc1, err := mimcbn254.NewMiMC(api, planetHashKey, 110)
+	mimc1, err := mimcbn254.NewMiMC(api, planetHashKey, mimcNumRounds)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -134,7 +152,7 @@ func Move(
 	mimc1.Write(x1, y1)
 	pub1 := mimc1.Sum()
 
-	mimc2, err := mimcbn254.NewMiMC(api, planetHashKey, 110)
+	mimc2, err := mimcbn254.NewMiMC(api, planetHashKey, mimcNumRounds)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -149,6 +167,7 @@ func Move(
 		xMirror,
 		yMirror,
 		spaceTypeKey,
+		perlinProfile,
 	)
 	if err != nil {
 		return nil, nil, nil, err
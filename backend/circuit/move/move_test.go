@@ -16,6 +16,12 @@ import (
 	"github.com/consensys/gnark/test"
 )
 
+// Keys of the planets generated by the client, see cardinal/test/init.go
+const (
+	testPlanetHashKey = "7"
+	testSpaceTypeKey  = "7"
+)
+
 var (
 	moveCircuit MoveCircuit
 	// pub1 and pub2 are valid planets that are within range of each other
@@ -27,8 +33,8 @@ var (
 )
 
 func init() {
-	moveCircuit.PlanetHashKey = circuit.PlanetHashKey
-	moveCircuit.SpaceTypeKey = circuit.SpaceTypeKey
+	moveCircuit.PlanetHashKey = testPlanetHashKey
+	moveCircuit.SpaceTypeKey = testSpaceTypeKey
 
 	var ok bool
 	pub1, ok = new(big.Int).SetString("0d01f8778431d6f04310bf3f02fb6e85a173624241fc8d8185c528306f57ca68", 16)
@@ -59,8 +65,8 @@ func TestMove(t *testing.T) {
 			Y1:            -3,
 			X2:            11,
 			Y2:            -10,
-			SpaceTypeKey:  circuit.SpaceTypeKey,
-			PlanetHashKey: circuit.PlanetHashKey,
+			SpaceTypeKey:  testSpaceTypeKey,
+			PlanetHashKey: testPlanetHashKey,
 			R:             circuit.RadiusMax,
 			DistMax:       53,
 			Scale:         circuit.Scale,
@@ -69,6 +75,7 @@ func TestMove(t *testing.T) {
 			Pub1:          pub1,
 			Pub2:          pub2,
 			Perl2:         16,
+			Nonce:         1,
 		},
 		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
 		test.WithBackends(backend.GROTH16),
@@ -101,8 +108,8 @@ func TestMiMCConstraint(t *testing.T) {
 			Y1:            -4, // Changed from -3 to -4
 			X2:            11,
 			Y2:            -10,
-			SpaceTypeKey:  circuit.SpaceTypeKey,
-			PlanetHashKey: circuit.PlanetHashKey,
+			SpaceTypeKey:  testSpaceTypeKey,
+			PlanetHashKey: testPlanetHashKey,
 			R:             circuit.RadiusMax,
 			DistMax:       53,
 			Scale:         circuit.Scale,
@@ -111,6 +118,7 @@ func TestMiMCConstraint(t *testing.T) {
 			Pub1:          pub1,
 			Pub2:          pub2,
 			Perl2:         16,
+			Nonce:         1,
 		},
 		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
 		test.WithBackends(backend.GROTH16),
@@ -134,8 +142,8 @@ func TestMoveDistanceWithinBounds(t *testing.T) {
 			Y1:            -3,
 			X2:            11,
 			Y2:            -10,
-			SpaceTypeKey:  circuit.SpaceTypeKey,
-			PlanetHashKey: circuit.PlanetHashKey,
+			SpaceTypeKey:  testSpaceTypeKey,
+			PlanetHashKey: testPlanetHashKey,
 			R:             circuit.RadiusMax,
 			DistMax:       53,
 			Scale:         circuit.Scale,
@@ -144,6 +152,7 @@ func TestMoveDistanceWithinBounds(t *testing.T) {
 			Pub1:          pub1,
 			Pub2:          pub2,
 			Perl2:         16,
+			Nonce:         1,
 		},
 		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
 		test.WithBackends(backend.GROTH16),
@@ -163,8 +172,8 @@ func TestMoveDistanceTooGreat(t *testing.T) {
 			Y1:            25,
 			X2:            -23,
 			Y2:            7,
-			SpaceTypeKey:  circuit.SpaceTypeKey,
-			PlanetHashKey: circuit.PlanetHashKey,
+			SpaceTypeKey:  testSpaceTypeKey,
+			PlanetHashKey: testPlanetHashKey,
 			R:             circuit.RadiusMax,
 			DistMax:       3,
 			Scale:         circuit.Scale,
@@ -173,6 +182,7 @@ func TestMoveDistanceTooGreat(t *testing.T) {
 			Pub1:          pub3,
 			Pub2:          pub4,
 			Perl2:         16,
+			Nonce:         1,
 		},
 		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
 		test.WithBackends(backend.GROTH16),
@@ -195,8 +205,8 @@ func TestMoveNewPointOutsideCircularBoundary(t *testing.T) {
 			Y1:            -3,
 			X2:            11,
 			Y2:            -10,
-			SpaceTypeKey:  circuit.SpaceTypeKey,
-			PlanetHashKey: circuit.PlanetHashKey,
+			SpaceTypeKey:  testSpaceTypeKey,
+			PlanetHashKey: testPlanetHashKey,
 			R:             2, // Set radius very low so it fails
 			DistMax:       53,
 			Scale:         circuit.Scale,
@@ -205,6 +215,7 @@ func TestMoveNewPointOutsideCircularBoundary(t *testing.T) {
 			Pub1:          pub1,
 			Pub2:          pub2,
 			Perl2:         16,
+			Nonce:         1,
 		},
 		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
 		test.WithBackends(backend.GROTH16),
@@ -231,8 +242,8 @@ func TestMoveInvalidKeys(t *testing.T) {
 			Y1:            -3,
 			X2:            11,
 			Y2:            -10,
-			SpaceTypeKey:  circuit.SpaceTypeKey,
-			PlanetHashKey: circuit.PlanetHashKey,
+			SpaceTypeKey:  testSpaceTypeKey,
+			PlanetHashKey: testPlanetHashKey,
 			R:             circuit.RadiusMax,
 			DistMax:       53,
 			Scale:         circuit.Scale,
@@ -241,6 +252,7 @@ func TestMoveInvalidKeys(t *testing.T) {
 			Pub1:          pub1,
 			Pub2:          pub2,
 			Perl2:         16,
+			Nonce:         1,
 		},
 		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
 		test.WithBackends(backend.GROTH16),
@@ -260,8 +272,8 @@ func TestMoveInvalidScale(t *testing.T) {
 			Y1:            -3,
 			X2:            11,
 			Y2:            10,
-			SpaceTypeKey:  circuit.SpaceTypeKey,
-			PlanetHashKey: circuit.PlanetHashKey,
+			SpaceTypeKey:  testSpaceTypeKey,
+			PlanetHashKey: testPlanetHashKey,
 			R:             circuit.RadiusMax,
 			DistMax:       53,
 			Scale:         16835,
@@ -270,6 +282,7 @@ func TestMoveInvalidScale(t *testing.T) {
 			Pub1:          pub1,
 			Pub2:          pub2,
 			Perl2:         16,
+			Nonce:         1,
 		},
 		test.WithProverOpts(backend.WithHints(perlin.ModuloHint)),
 		test.WithBackends(backend.GROTH16),
@@ -281,8 +294,8 @@ func BenchmarkMove(b *testing.B) {
 	// NOTE: PlanetHashKey and SpaceTypeKey needs to be initialized here
 	// the circuit will compile with nil value
 	var newMoveCircuit MoveCircuit
-	newMoveCircuit.PlanetHashKey = circuit.PlanetHashKey
-	newMoveCircuit.SpaceTypeKey = circuit.SpaceTypeKey
+	newMoveCircuit.PlanetHashKey = testPlanetHashKey
+	newMoveCircuit.SpaceTypeKey = testSpaceTypeKey
 
 	// Compile circuit
 	ccs, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &newMoveCircuit)
@@ -311,8 +324,8 @@ func BenchmarkMove(b *testing.B) {
 		Y1:            -3,
 		X2:            11,
 		Y2:            10,
-		SpaceTypeKey:  circuit.SpaceTypeKey,
-		PlanetHashKey: circuit.PlanetHashKey,
+		SpaceTypeKey:  testSpaceTypeKey,
+		PlanetHashKey: testPlanetHashKey,
 		R:             circuit.RadiusMax,
 		DistMax:       53,
 		Scale:         circuit.Scale,
@@ -321,6 +334,7 @@ func BenchmarkMove(b *testing.B) {
 		Pub1:          pub1,
 		Pub2:          pub2,
 		Perl2:         16,
+		Nonce:         1,
 	}
 
 	// BENCH: Witness generation
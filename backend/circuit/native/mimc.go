@@ -0,0 +1,107 @@
+// Package native is an out-of-circuit implementation of the gadgets in the
+// mimc and perlin packages. Every operation is carried out in the BN254 scalar
+// field exactly like the constraint system would, so the values produced here
+// are the same values a circuit assignment has to use.
+package native
+
+import (
+	"math/big"
+
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+	"golang.org/x/crypto/sha3"
+)
+
+// MiMC mirrors mimcbn254.MiMC outside of a circuit
+type MiMC struct {
+	params []fr.Element // constants for the encryption rounds
+	h      fr.Element   // current vector in the Miyaguchi–Preneel scheme
+	data   []fr.Element // state storage. data is updated when Write() is called. Sum sums the data.
+}
+
+// NewMiMC returns a MiMC instance using the same seed / number of rounds
+// semantics as mimcbn254.NewMiMC
+func NewMiMC(seed string, numRounds int) MiMC {
+	return MiMC{params: initConstants(seed, numRounds)}
+}
+
+// Same derivation as mimcbn254.initConstants: keccak256 the seed once, then
+// keep re-hashing the digest and reduce every digest into the field
+func initConstants(seed string, numRounds int) []fr.Element {
+	hash := sha3.NewLegacyKeccak256()
+	_, _ = hash.Write([]byte(seed))
+	rnd := hash.Sum(nil) // pre hash before use
+	hash.Reset()
+	_, _ = hash.Write(rnd)
+
+	constants := make([]fr.Element, numRounds)
+	for i := 0; i < numRounds; i++ {
+		rnd = hash.Sum(nil)
+		constants[i].SetBytes(rnd)
+		hash.Reset()
+		_, _ = hash.Write(rnd)
+	}
+
+	return constants
+}
+
+// Write adds more data to the running hash.
+// Negative values are mapped into the field (i.e. -1 becomes p-1).
+func (h *MiMC) Write(data ...*big.Int) {
+	for _, d := range data {
+		var e fr.Element
+		e.SetBigInt(d)
+		h.data = append(h.data, e)
+	}
+}
+
+// WriteInt64 is a convenience wrapper around Write for coordinates
+func (h *MiMC) WriteInt64(data ...int64) {
+	for _, d := range data {
+		var e fr.Element
+		e.SetInt64(d)
+		h.data = append(h.data, e)
+	}
+}
+
+// Reset resets the Hash to its initial state.
+func (h *MiMC) Reset() {
+	h.data = nil
+	h.h.SetZero()
+}
+
+// Sum returns the hash of everything written so far (see mimcbn254.MiMC.Sum)
+func (h *MiMC) Sum() *big.Int {
+	h.sum()
+	return h.h.BigInt(new(big.Int))
+}
+
+func (h *MiMC) sum() fr.Element {
+	for i := range h.data {
+		r := h.encryptPow5(h.data[i])
+		h.h.Add(&h.h, &r)
+		h.h.Add(&h.h, &h.data[i])
+	}
+
+	h.data = nil // flush the data already hashed
+
+	return h.h
+}
+
+func (h *MiMC) encryptPow5(m fr.Element) fr.Element {
+	x := m
+	for i := range h.params {
+		x.Add(&x, &h.h)
+		x.Add(&x, &h.params[i])
+		x = pow5(x)
+	}
+	x.Add(&x, &h.h)
+	return x
+}
+
+func pow5(x fr.Element) fr.Element {
+	var r fr.Element
+	r.Square(&x)
+	r.Square(&r)
+	r.Mul(&r, &x)
+	return r
+}
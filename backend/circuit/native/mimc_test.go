@@ -0,0 +1,116 @@
+package native
+
+import (
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"os"
+	"testing"
+
+	mimcbn254 "github.com/argus-labs/darkfrontier-backend/circuit/mimc"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+type MiMCCircuit struct {
+	seed      string
+	numRounds int
+
+	Input  []frontend.Variable
+	Output frontend.Variable
+}
+
+func (circuit *MiMCCircuit) Define(api frontend.API) error {
+	mimc, err := mimcbn254.NewMiMC(api, circuit.seed, circuit.numRounds)
+	if err != nil {
+		return err
+	}
+	mimc.Write(circuit.Input...)
+	api.AssertIsEqual(mimc.Sum(), circuit.Output)
+	return nil
+}
+
+func assertMiMCMatchesCircuit(t *testing.T, seed string, numRounds int, input []*big.Int) {
+	t.Helper()
+
+	h := NewMiMC(seed, numRounds)
+	h.Write(input...)
+	out := h.Sum()
+
+	circuit := MiMCCircuit{seed: seed, numRounds: numRounds, Input: make([]frontend.Variable, len(input))}
+	assignment := MiMCCircuit{Input: make([]frontend.Variable, len(input)), Output: out}
+	for i := range input {
+		assignment.Input[i] = input[i]
+	}
+
+	if err := test.IsSolved(&circuit, &assignment, ecc.BN254.ScalarField()); err != nil {
+		t.Fatalf("native MiMC(%q, %d) of %v = %s does not match circuit: %v", seed, numRounds, input, out, err)
+	}
+}
+
+func TestMiMCVectors(t *testing.T) {
+	// Same vectors as the mimc package
+	vectorsContent, err := os.ReadFile("../mimc/vectors.json")
+	if err != nil {
+		t.Fatal("failed to open test vector file")
+	}
+
+	var testVectors []struct {
+		In  []string `json:"in"`
+		Out string   `json:"out"`
+	}
+	err = json.Unmarshal(vectorsContent, &testVectors)
+	if err != nil {
+		t.Fatal("failed to unmarshal test vector")
+	}
+
+	for _, testVector := range testVectors {
+		h := NewMiMC("seed", 110)
+		for _, inHex := range testVector.In {
+			// Can use base 0 because strings contain "0x" prefix
+			in, ok := new(big.Int).SetString(inHex, 0)
+			if !ok {
+				t.Fatal("failed to parse big.Int from input")
+			}
+			h.Write(in)
+		}
+		expected, ok := new(big.Int).SetString(testVector.Out, 0)
+		if !ok {
+			t.Fatal("failed to parse big.Int from output")
+		}
+
+		if out := h.Sum(); out.Cmp(expected) != 0 {
+			t.Fatalf("expected %x, got %x", expected, out)
+		}
+	}
+}
+
+func TestMiMCReset(t *testing.T) {
+	h := NewMiMC("7", 110)
+	h.WriteInt64(3, -3)
+	first := h.Sum()
+
+	h.Reset()
+	h.WriteInt64(3, -3)
+	if second := h.Sum(); first.Cmp(second) != 0 {
+		t.Fatalf("expected %x after reset, got %x", first, second)
+	}
+}
+
+func TestMiMCDifferential(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	seeds := []string{"seed", "7", "darkfrontier"}
+
+	for i := 0; i < 50; i++ {
+		input := make([]*big.Int, 1+rng.Intn(3))
+		for j := range input {
+			input[j] = big.NewInt(rng.Int63n(1<<32) - 1<<31)
+		}
+		assertMiMCMatchesCircuit(t, seeds[i%len(seeds)], 1+rng.Intn(110), input)
+	}
+
+	// field elements on both sides of p/2
+	p := ecc.BN254.ScalarField()
+	assertMiMCMatchesCircuit(t, "seed", 110, []*big.Int{new(big.Int).Sub(p, big.NewInt(1)), new(big.Int).Rsh(p, 1)})
+}
@@ -0,0 +1,281 @@
+package native
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/consensys/gnark-crypto/ecc/bn254/fr"
+)
+
+// good for length scales up to 16384. 2^50 * 1000
+const denominator = 1125899906842624000
+
+// coordinates are range proven to this absolute value by perlin.MultiScalePerlin
+const maxAbsCoord = int64(1) << 31
+
+var (
+	halfModulus = new(big.Int).Rsh(fr.Modulus(), 1)
+
+	vecs = [16][2]int64{{1000, 0}, {923, 382}, {707, 707}, {382, 923}, {0, 1000}, {-383, 923}, {-708, 707}, {-924, 382}, {-1000, 0}, {-924, -383}, {-708, -708}, {-383, -924}, {-1, -1000}, {382, -924}, {707, -708}, {923, -383}}
+)
+
+// Random mirrors perlin.Random: the 4 lowest bits of a numRounds round MiMC over x, y, scale
+func Random(in [3]int64, key string, numRounds int) uint64 {
+	var e [3]fr.Element
+	for i := range in {
+		e[i].SetInt64(in[i])
+	}
+	return random(e, key, numRounds)
+}
+
+func random(in [3]fr.Element, key string, numRounds int) uint64 {
+	mimc := NewMiMC(key, numRounds)
+	mimc.data = append(mimc.data, in[:]...)
+	sum := mimc.sum()
+	return sum.Bits()[0] & 15
+}
+
+// 1 if `in` in (p/2, p], 0 otherwise
+func isNegative(in *fr.Element) bool {
+	return in.BigInt(new(big.Int)).Cmp(halfModulus) == 1
+}
+
+// modulo mirrors perlin.Modulo (including perlin.ModuloHint)
+// -8 % 5 = 2, (-8 - 2) // 5 = -2
+func modulo(dividend, divisor fr.Element) (quotient, remainder fr.Element) {
+	negative := isNegative(&dividend)
+
+	absDividend := dividend
+	if negative {
+		absDividend.Neg(&dividend)
+	}
+
+	bDivisor := divisor.BigInt(new(big.Int))
+	bRemainder := absDividend.BigInt(new(big.Int))
+	bRemainder.Mod(bRemainder, bDivisor)
+	if negative && bRemainder.Sign() != 0 {
+		bRemainder.Sub(bDivisor, bRemainder)
+	}
+	remainder.SetBigInt(bRemainder)
+
+	quotient.Sub(&dividend, &remainder)
+	quotient.Div(&quotient, &divisor)
+
+	return quotient, remainder
+}
+
+func randomGradientAt(in [2]fr.Element, scale fr.Element, key string, numRounds int) [2]fr.Element {
+	rand := random([3]fr.Element{in[0], in[1], scale}, key, numRounds)
+
+	var vectorDenominator fr.Element
+	vectorDenominator.SetUint64(denominator / 1000)
+
+	var grad [2]fr.Element
+	grad[0].SetInt64(vecs[rand][0])
+	grad[0].Mul(&grad[0], &vectorDenominator)
+	grad[1].SetInt64(vecs[rand][1])
+	grad[1].Mul(&grad[1], &vectorDenominator)
+
+	return grad
+}
+
+// returns the 4 corners (BL, BR, TL, TR) of the scale x scale square containing p
+// and a parallel array of gradient vector NUMERATORS
+func getCornersAndGradVectors(p [2]fr.Element, scale fr.Element, key string, numRounds int) ([4][2]fr.Element, [4][2]fr.Element) {
+	_, xRemainder := modulo(p[0], scale)
+	_, yRemainder := modulo(p[1], scale)
+
+	var left, bottom, right, top fr.Element
+	left.Sub(&p[0], &xRemainder)
+	bottom.Sub(&p[1], &yRemainder)
+	right.Add(&left, &scale)
+	top.Add(&bottom, &scale)
+
+	coords := [4][2]fr.Element{
+		{left, bottom},
+		{right, bottom},
+		{left, top},
+		{right, top},
+	}
+
+	var grads [4][2]fr.Element
+	for i := range coords {
+		grads[i] = randomGradientAt(coords[i], scale, key, numRounds)
+	}
+
+	return coords, grads
+}
+
+func getWeight(denom fr.Element, diff [2]fr.Element) fr.Element {
+	var a, b fr.Element
+	a.Sub(&denom, &diff[0])
+	b.Sub(&denom, &diff[1])
+	a.Mul(&a, &b)
+	return *a.Div(&a, &denom)
+}
+
+// corner index follows getCornersAndGradVectors: BL, BR, TL, TR
+func getWeightAt(i int, denom fr.Element, corner, p [2]fr.Element) fr.Element {
+	var diff [2]fr.Element
+	if i%2 == 0 {
+		diff[0].Sub(&p[0], &corner[0])
+	} else {
+		diff[0].Sub(&corner[0], &p[0])
+	}
+	if i < 2 {
+		diff[1].Sub(&p[1], &corner[1])
+	} else {
+		diff[1].Sub(&corner[1], &p[1])
+	}
+	return getWeight(denom, diff)
+}
+
+func dot(denom fr.Element, a, b [2]fr.Element) fr.Element {
+	var x, y fr.Element
+	x.Mul(&a[0], &b[0])
+	y.Mul(&a[1], &b[1])
+	x.Add(&x, &y)
+	return *x.Div(&x, &denom)
+}
+
+// mirrors perlin.PerlinValue, coords and p are already multiplied by the denominator
+func perlinValue(denom fr.Element, coords, grads [4][2]fr.Element, scale fr.Element, p [2]fr.Element) fr.Element {
+	var scaledP [2]fr.Element
+	scaledP[0].Div(&p[0], &scale)
+	scaledP[1].Div(&p[1], &scale)
+
+	var total fr.Element
+	for i := 0; i < 4; i++ {
+		var corner [2]fr.Element
+		corner[0].Div(&coords[i][0], &scale)
+		corner[1].Div(&coords[i][1], &scale)
+		weight := getWeightAt(i, denom, corner, scaledP)
+
+		var scaledDistVec [2]fr.Element
+		scaledDistVec[0].Sub(&p[0], &coords[i][0])
+		scaledDistVec[0].Div(&scaledDistVec[0], &scale)
+		scaledDistVec[1].Sub(&p[1], &coords[i][1])
+		scaledDistVec[1].Div(&scaledDistVec[1], &scale)
+
+		d := dot(denom, grads[i], scaledDistVec)
+
+		var term fr.Element
+		term.Mul(&d, &weight)
+		term.Div(&term, &denom)
+		total.Add(&total, &term)
+	}
+
+	return total
+}
+
+func singleScalePerlin(p [2]fr.Element, scale fr.Element, key string, numRounds int) fr.Element {
+	var denom fr.Element
+	denom.SetUint64(denominator)
+
+	coords, grads := getCornersAndGradVectors(p, scale, key, numRounds)
+
+	var denomP [2]fr.Element
+	denomP[0].Mul(&denom, &p[0])
+	denomP[1].Mul(&denom, &p[1])
+
+	var denomCoords [4][2]fr.Element
+	for i := range coords {
+		denomCoords[i][0].Mul(&denom, &coords[i][0])
+		denomCoords[i][1].Mul(&denom, &coords[i][1])
+	}
+
+	return perlinValue(denom, denomCoords, grads, scale, denomP)
+}
+
+// SingleScalePerlin mirrors perlin.SingleScalePerlin.
+// The result is a NUMERATOR over 2^50 * 1000, negative values are returned as such.
+func SingleScalePerlin(p [2]int64, scale int64, key string, numRounds int) *big.Int {
+	var ep [2]fr.Element
+	ep[0].SetInt64(p[0])
+	ep[1].SetInt64(p[1])
+	var eScale fr.Element
+	eScale.SetInt64(scale)
+
+	v := singleScalePerlin(ep, eScale, key, numRounds)
+	return toSigned(&v)
+}
+
+// MultiScalePerlin mirrors perlin.MultiScalePerlin and returns the same value
+// the circuit computes for the planet at p (the `Perl` public input)
+func MultiScalePerlin(
+	p [2]int64,
+	// power of 2, at most 16384 once multiplied by 2^(number of octaves - 1), so that denominator works
+	scale int64,
+	// 1 is true, 0 is false
+	xMirror int64,
+	// 1 is true, 0 is false
+	yMirror int64,
+	key string,
+	profile perlin.Profile,
+) (int64, error) {
+	if err := profile.Validate(); err != nil {
+		return 0, err
+	}
+	if xMirror != 0 && xMirror != 1 {
+		return 0, fmt.Errorf("xMirror must be 0 or 1, got %d", xMirror)
+	}
+	if yMirror != 0 && yMirror != 1 {
+		return 0, fmt.Errorf("yMirror must be 0 or 1, got %d", yMirror)
+	}
+	if scale <= 0 {
+		return 0, fmt.Errorf("scale must be positive, got %d", scale)
+	}
+	for _, c := range p {
+		if c > maxAbsCoord || c < -maxAbsCoord {
+			return 0, fmt.Errorf("coordinate %d out of range, abs value must be at most 2^31", c)
+		}
+	}
+
+	// should flip sign of x coord if yMirror is true (i.e. flip along vertical axis) and x is negative
+	x := p[0]
+	if yMirror == 1 && x < 0 {
+		x = -x
+	}
+	// should flip sign of y coord if xMirror is true (i.e. flip along horizontal axis) and y is negative
+	y := p[1]
+	if xMirror == 1 && y < 0 {
+		y = -y
+	}
+
+	var adjusted [2]fr.Element
+	adjusted[0].SetInt64(x)
+	adjusted[1].SetInt64(y)
+
+	// add weights[i] * perlins[i] for every octave
+	var total fr.Element
+	for i, weight := range profile.Weights {
+		var s, w fr.Element
+		s.SetInt64(scale << i)
+		w.SetInt64(weight)
+		octave := singleScalePerlin(adjusted, s, key, profile.NumRounds)
+		octave.Mul(&octave, &w)
+		total.Add(&total, &octave)
+	}
+
+	var buckets, divisor fr.Element
+	buckets.SetInt64(profile.Buckets)
+	total.Mul(&total, &buckets)
+	divisor.SetUint64(denominator)
+	divisor.Mul(&divisor, new(fr.Element).SetInt64(profile.TotalWeight()))
+	quotient, _ := modulo(total, divisor)
+
+	out := toSigned(&quotient)
+	return out.Int64() + profile.Buckets, nil
+}
+
+// interprets a field element in (p/2, p] as a negative integer
+func toSigned(e *fr.Element) *big.Int {
+	if isNegative(e) {
+		var neg fr.Element
+		neg.Neg(e)
+		b := neg.BigInt(new(big.Int))
+		return b.Neg(b)
+	}
+	return e.BigInt(new(big.Int))
+}
@@ -0,0 +1,315 @@
+package native
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit"
+	mimcbn254 "github.com/argus-labs/darkfrontier-backend/circuit/mimc"
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/test"
+)
+
+// Keys of the planets generated by the client, see cardinal/test/init.go
+const (
+	testPlanetHashKey = "7"
+	testSpaceTypeKey  = "7"
+)
+
+type RandomCircuit struct {
+	key string
+
+	In  [3]frontend.Variable
+	Out frontend.Variable
+}
+
+func (c *RandomCircuit) Define(api frontend.API) error {
+	out, err := perlin.Random(api, c.In, c.key, perlin.DefaultProfile.NumRounds)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(out, c.Out)
+	return nil
+}
+
+type SingleScalePerlinCircuit struct {
+	key string
+
+	P     [2]frontend.Variable
+	Scale frontend.Variable
+	Out   frontend.Variable
+}
+
+func (c *SingleScalePerlinCircuit) Define(api frontend.API) error {
+	out, err := perlin.SingleScalePerlin(api, big.NewInt(denominator), c.P, c.Scale, c.key, perlin.DefaultProfile.NumRounds)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(out, c.Out)
+	return nil
+}
+
+type MultiScalePerlinCircuit struct {
+	key     string
+	profile perlin.Profile
+
+	P       [2]frontend.Variable
+	Scale   frontend.Variable
+	XMirror frontend.Variable
+	YMirror frontend.Variable
+	Out     frontend.Variable
+}
+
+func (c *MultiScalePerlinCircuit) Define(api frontend.API) error {
+	out, err := perlin.MultiScalePerlin(api, c.P, c.Scale, c.XMirror, c.YMirror, c.key, c.profile)
+	if err != nil {
+		return err
+	}
+	api.AssertIsEqual(out, c.Out)
+	return nil
+}
+
+func isSolved(circuit, assignment frontend.Circuit) error {
+	return test.IsSolved(
+		circuit,
+		assignment,
+		ecc.BN254.ScalarField(),
+		test.WithBackendProverOptions(backend.WithHints(perlin.ModuloHint)),
+	)
+}
+
+func randomCoord(rng *rand.Rand, maxAbs int64) int64 {
+	return rng.Int63n(2*maxAbs+1) - maxAbs
+}
+
+func TestRandomDifferential(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	for i := 0; i < 50; i++ {
+		in := [3]int64{randomCoord(rng, 1<<31), randomCoord(rng, 1<<31), 1 << rng.Intn(15)}
+		out := Random(in, testSpaceTypeKey, perlin.DefaultProfile.NumRounds)
+		if out > 15 {
+			t.Fatalf("random value %d out of range", out)
+		}
+
+		err := isSolved(
+			&RandomCircuit{key: testSpaceTypeKey},
+			&RandomCircuit{In: [3]frontend.Variable{in[0], in[1], in[2]}, Out: out},
+		)
+		if err != nil {
+			t.Fatalf("native Random(%v) = %d does not match circuit: %v", in, out, err)
+		}
+	}
+}
+
+func TestSingleScalePerlinDifferential(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+
+	for i := 0; i < 30; i++ {
+		p := [2]int64{randomCoord(rng, 100000), randomCoord(rng, 100000)}
+		scale := int64(1) << rng.Intn(15)
+		out := SingleScalePerlin(p, scale, testSpaceTypeKey, perlin.DefaultProfile.NumRounds)
+
+		// the test engine does not reduce assignments, so negative values have to be mapped into the field
+		reduced := new(big.Int).Mod(out, ecc.BN254.ScalarField())
+		err := isSolved(
+			&SingleScalePerlinCircuit{key: testSpaceTypeKey},
+			&SingleScalePerlinCircuit{P: [2]frontend.Variable{p[0], p[1]}, Scale: scale, Out: reduced},
+		)
+		if err != nil {
+			t.Fatalf("native SingleScalePerlin(%v, %d) = %s does not match circuit: %v", p, scale, out, err)
+		}
+	}
+}
+
+// Planets generated by the client, see cardinal/test/init.go
+var knownPlanets = []struct {
+	p            [2]int64
+	locationHash string
+	perlin       int64
+}{
+	{[2]int64{-13, 0}, "0c00eab1ba08a68b0bc4767349a3f3c295011a54127e4713920318d6fbc6b662", 14},
+	{[2]int64{-21, 5}, "23014b713b8c41d5116c6334c9daf595d8a23edaa06a21dee833ca79f3452344", 16},
+	{[2]int64{6, -21}, "2b0173c32d6d3c7526dc27aeb69791ea79d19b28571325d4f99d31cd17cb8062", 18},
+	{[2]int64{3, -3}, "0d01f8778431d6f04310bf3f02fb6e85a173624241fc8d8185c528306f57ca68", 16},
+	{[2]int64{11, -10}, "0d00974b8d6df596c0eaf0c8fb15a45dc2da397c3f2d2107661feea02d200e27", 16},
+	{[2]int64{0, 22}, "10012a1b9bb877625c03c2d253dc4b18a9171162c10b70870c38fb16b67f1251", 11},
+	{[2]int64{-29, 28}, "0b00e8083a5ad331e3e5fc97c6ed06ffbdb2c9fb292974ce57a25cf5a5b6b668", 14},
+}
+
+func TestKnownPlanets(t *testing.T) {
+	for _, planet := range knownPlanets {
+		h := NewMiMC(testPlanetHashKey, mimcbn254.DefaultNumRounds)
+		h.WriteInt64(planet.p[0], planet.p[1])
+		if locationHash := fmt.Sprintf("%064x", h.Sum()); locationHash != planet.locationHash {
+			t.Fatalf("expected location hash %s at %v, got %s", planet.locationHash, planet.p, locationHash)
+		}
+
+		out, err := MultiScalePerlin(planet.p, circuit.Scale, circuit.XMirror, circuit.YMirror, testSpaceTypeKey, perlin.DefaultProfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != planet.perlin {
+			t.Fatalf("expected perlin %d at %v, got %d", planet.perlin, planet.p, out)
+		}
+	}
+}
+
+func TestMultiScalePerlinDifferential(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+
+	points := [][2]int64{
+		{0, 0}, {1, 1}, {-1, -1}, {-16, 16}, {16, -16},
+		{1 << 31, 1 << 31}, {-(1 << 31), -(1 << 31)},
+	}
+	for i := 0; i < 40; i++ {
+		points = append(points, [2]int64{randomCoord(rng, 10000), randomCoord(rng, 10000)})
+	}
+	for i := 0; i < 10; i++ {
+		points = append(points, [2]int64{randomCoord(rng, 1<<31), randomCoord(rng, 1<<31)})
+	}
+
+	for i, p := range points {
+		scale := int64(1) << rng.Intn(15)
+		if i%3 == 0 {
+			scale = circuit.Scale
+		}
+		xMirror, yMirror := int64(i%2), int64((i/2)%2)
+
+		out, err := MultiScalePerlin(p, scale, xMirror, yMirror, testSpaceTypeKey, perlin.DefaultProfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = isSolved(
+			&MultiScalePerlinCircuit{key: testSpaceTypeKey, profile: perlin.DefaultProfile},
+			&MultiScalePerlinCircuit{
+				P:       [2]frontend.Variable{p[0], p[1]},
+				Scale:   scale,
+				XMirror: xMirror,
+				YMirror: yMirror,
+				Out:     out,
+			},
+		)
+		if err != nil {
+			t.Fatalf(
+				"native MultiScalePerlin(%v, scale=%d, xMirror=%d, yMirror=%d) = %d does not match circuit: %v",
+				p, scale, xMirror, yMirror, out, err,
+			)
+		}
+
+		// a wrong value must not satisfy the circuit, otherwise the comparison above proves nothing
+		err = isSolved(
+			&MultiScalePerlinCircuit{key: testSpaceTypeKey, profile: perlin.DefaultProfile},
+			&MultiScalePerlinCircuit{
+				P:       [2]frontend.Variable{p[0], p[1]},
+				Scale:   scale,
+				XMirror: xMirror,
+				YMirror: yMirror,
+				Out:     out + 1,
+			},
+		)
+		if err == nil {
+			t.Fatalf("circuit accepted wrong perlin value %d at %v", out+1, p)
+		}
+	}
+}
+
+// Terrain profiles other than the default must still match the circuit
+func TestMultiScalePerlinProfiles(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+
+	profiles := []perlin.Profile{
+		{Weights: []int64{1}, Buckets: 8, NumRounds: 3},
+		// a total weight of 3 does not divide the sum of the octaves
+		{Weights: []int64{1, 1, 1}, Buckets: 7, NumRounds: 4},
+		{Weights: []int64{3, 2, 1, 1}, Buckets: 10, NumRounds: 5},
+	}
+
+	for _, profile := range profiles {
+		for i := 0; i < 8; i++ {
+			p := [2]int64{randomCoord(rng, 10000), randomCoord(rng, 10000)}
+
+			out, err := MultiScalePerlin(p, circuit.Scale, 0, 0, testSpaceTypeKey, profile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out < 0 || out > 2*profile.Buckets {
+				t.Fatalf("perlin %d at %v is outside [0, %d] for %+v", out, p, 2*profile.Buckets, profile)
+			}
+
+			err = isSolved(
+				&MultiScalePerlinCircuit{key: testSpaceTypeKey, profile: profile},
+				&MultiScalePerlinCircuit{
+					P:       [2]frontend.Variable{p[0], p[1]},
+					Scale:   circuit.Scale,
+					XMirror: 0,
+					YMirror: 0,
+					Out:     out,
+				},
+			)
+			if err != nil {
+				t.Fatalf("native MultiScalePerlin(%v, %+v) = %d does not match circuit: %v", p, profile, out, err)
+			}
+		}
+	}
+}
+
+func TestMultiScalePerlinInvalidProfiles(t *testing.T) {
+	invalid := []perlin.Profile{
+		{Weights: []int64{}, Buckets: 16, NumRounds: 4},
+		{Weights: []int64{2, 0, 1}, Buckets: 16, NumRounds: 4},
+		{Weights: []int64{2, 1, 1}, Buckets: 0, NumRounds: 4},
+		{Weights: []int64{2, 1, 1}, Buckets: 16, NumRounds: 0},
+		{Weights: []int64{1 << 40}, Buckets: 1 << 40, NumRounds: 4},
+	}
+
+	for _, profile := range invalid {
+		_, err := MultiScalePerlin([2]int64{0, 0}, circuit.Scale, 0, 0, testSpaceTypeKey, profile)
+		if err == nil {
+			t.Fatalf("expected error for %+v", profile)
+		}
+	}
+}
+
+func TestMultiScalePerlinMirror(t *testing.T) {
+	p := [2]int64{-1234, -4321}
+
+	mirrored, err := MultiScalePerlin(p, circuit.Scale, 1, 1, testSpaceTypeKey, perlin.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reflected, err := MultiScalePerlin([2]int64{1234, 4321}, circuit.Scale, 0, 0, testSpaceTypeKey, perlin.DefaultProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mirrored != reflected {
+		t.Fatalf("expected mirrored perlin %d to equal reflected perlin %d", mirrored, reflected)
+	}
+}
+
+func TestMultiScalePerlinInvalidInputs(t *testing.T) {
+	invalid := []struct {
+		p                []int64
+		scale            int64
+		xMirror, yMirror int64
+	}{
+		{[]int64{0, 0}, 16, 2, 0},
+		{[]int64{0, 0}, 16, 0, -1},
+		{[]int64{0, 0}, 0, 0, 0},
+		{[]int64{1<<31 + 1, 0}, 16, 0, 0},
+		{[]int64{0, -(1<<31 + 1)}, 16, 0, 0},
+	}
+
+	for _, tc := range invalid {
+		_, err := MultiScalePerlin([2]int64{tc.p[0], tc.p[1]}, tc.scale, tc.xMirror, tc.yMirror, testSpaceTypeKey, perlin.DefaultProfile)
+		if err == nil {
+			t.Fatalf("expected error for %+v", tc)
+		}
+	}
+}
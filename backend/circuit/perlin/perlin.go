@@ -15,8 +15,9 @@ func Random(
 	api frontend.API,
 	in [3]frontend.Variable,
 	key string,
+	numRounds int,
 ) (frontend.Variable, error) {
-	mimc, err := mimcbn254.NewMiMC(api, key, 4)
+	mimc, err := mimcbn254.NewMiMC(api, key, numRounds)
 	if err != nil {
 		return nil, err
 	}
@@ -128,6 +129,7 @@ func RandomGradientAt(
 	in [2]frontend.Variable,
 	scale frontend.Variable,
 	key string,
+	numRounds int,
 ) ([2]frontend.Variable, error) {
 	vecs := [16][2]frontend.Variable{{1000, 0}, {923, 382}, {707, 707}, {382, 923}, {0, 1000}, {-383, 923}, {-708, 707}, {-924, 382}, {-1000, 0}, {-924, -383}, {-708, -708}, {-383, -924}, {-1, -1000}, {382, -924}, {707, -708}, {923, -383}}
 
@@ -135,6 +137,7 @@ func RandomGradientAt(
 		api,
 		[3]frontend.Variable{in[0], in[1], scale},
 		key,
+		numRounds,
 	)
 	if err != nil {
 		return [2]frontend.Variable{nil, nil}, err
@@ -169,6 +172,7 @@ func GetCornersAndGradVectors(
 	p [2]frontend.Variable,
 	scale frontend.Variable,
 	key string,
+	numRounds int,
 ) (
 	[4][2]frontend.Variable,
 	[4][2]frontend.Variable,
@@ -191,6 +195,7 @@ func GetCornersAndGradVectors(
 			[2]frontend.Variable{x, y},
 			scale,
 			key,
+			numRounds,
 		)
 	}
 
@@ -447,6 +452,7 @@ func SingleScalePerlin(
 	p [2]frontend.Variable,
 	scale frontend.Variable,
 	key string,
+	numRounds int,
 ) (frontend.Variable, error) {
 	coords, grads, err := GetCornersAndGradVectors(
 		api,
@@ -454,6 +460,7 @@ func SingleScalePerlin(
 		p,
 		scale,
 		key,
+		numRounds,
 	)
 	if err != nil {
 		return nil, err
@@ -482,18 +489,23 @@ func SingleScalePerlin(
 	), nil
 }
 
-// TODO(stretch): Make this parameterizable by number of single scale perlin values (currently hardcoded to 3)
+// Weighted average of one SingleScalePerlin per octave of the profile, bucketed into [0, 2*profile.Buckets]
 func MultiScalePerlin(
 	api frontend.API,
 	p [2]frontend.Variable,
-	// power of 2 at most 16384 so that denominator works
+	// power of 2, at most 16384 once multiplied by 2^(number of octaves - 1), so that denominator works
 	scale frontend.Variable,
 	// 1 is true, 0 is false
 	xMirror frontend.Variable,
 	// 1 is true, 0 is false
 	yMirror frontend.Variable,
 	key string,
+	profile Profile,
 ) (frontend.Variable, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
 	// good for length scales up to 16384. 2^50 * 1000
 	denominator := big.NewInt(1125899906842624000)
 
@@ -539,22 +551,9 @@ func MultiScalePerlin(
 		),
 	)
 
-	// add perlins[0], perlins[1], perlins[2], and perlins[0] (again)
-	firstPerlin, err := SingleScalePerlin(
-		api,
-		denominator,
-		[2]frontend.Variable{xAdjusted, yAdjusted},
-		scale,
-		key,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	// api.Println("firstPerlin", firstPerlin)
-	total := api.Add(firstPerlin, firstPerlin)
-
-	for i := 1; i < 3; i++ {
+	// add weights[i] * perlins[i] for every octave
+	var total frontend.Variable = 0
+	for i, weight := range profile.Weights {
 		lShift := big.NewInt(1)
 		lShift.Lsh(lShift, uint(i))
 		perlin, err := SingleScalePerlin(
@@ -563,27 +562,29 @@ func MultiScalePerlin(
 			[2]frontend.Variable{xAdjusted, yAdjusted},
 			api.Mul(scale, lShift),
 			key,
+			profile.NumRounds,
 		)
 		if err != nil {
 			return nil, err
 		}
 		// api.Println("perlin", perlin)
 
-		total = api.MulAcc(total, perlin, 1)
+		total = api.Add(total, api.Mul(perlin, weight))
 	}
 
-	totalDividedByCount := api.Div(total, 4)
-	// api.Println("totalDividedByCount", totalDividedByCount)
-
-	// totalDividedByCount is between [-DENOMINATOR*sqrt(2)/2, DENOMINATOR*sqrt(2)/2]
-	divBy16Quotient, _ := Modulo(
+	// total / totalWeight is between [-DENOMINATOR*sqrt(2)/2, DENOMINATOR*sqrt(2)/2],
+	// so buckets * total / (totalWeight * DENOMINATOR) is between [-buckets, buckets].
+	// Dividing in the field would only be exact if totalWeight divides total, so it is
+	// folded into the divisor instead.
+	divisor := new(big.Int).Mul(denominator, big.NewInt(profile.TotalWeight()))
+	bucketQuotient, _ := Modulo(
 		api,
-		api.Mul(totalDividedByCount, 16),
-		denominator,
+		api.Mul(total, profile.Buckets),
+		divisor,
 	)
-	// api.Println("divBy16Quotient", divBy16Quotient)
+	// api.Println("bucketQuotient", bucketQuotient)
 
-	var out = api.Add(divBy16Quotient, 16)
+	var out = api.Add(bucketQuotient, profile.Buckets)
 	// api.Println("Perlin:", out)
 
 	return out, nil
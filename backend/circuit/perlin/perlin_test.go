@@ -129,6 +129,7 @@ func (circuit *RandomGradientAtCircuit) Define(api frontend.API) error {
 		circuit.In,
 		circuit.Scale,
 		"7",
+		4,
 	)
 	return err
 }
@@ -175,6 +176,7 @@ func (circuit *GetCornersAndGradVectorsCircuit) Define(api frontend.API) error {
 		circuit.P,
 		circuit.Scale,
 		"7",
+		4,
 	)
 	return err
 }
@@ -225,6 +227,7 @@ func (circuit *SingleScalePerlinCircuit) Define(api frontend.API) error {
 		circuit.P,
 		circuit.Scale,
 		"7",
+		4,
 	)
 	return err
 }
@@ -275,6 +278,7 @@ func (circuit *MultiScalePerlinCircuit) Define(api frontend.API) error {
 		circuit.XMirror,
 		circuit.YMirror,
 		"7",
+		DefaultProfile,
 	)
 	return err
 }
@@ -0,0 +1,111 @@
+package perlin
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Profile describes the terrain MultiScalePerlin generates. It's fixed when the
+// circuits are compiled, see PerlinNumRounds, PerlinOctaveWeights and PerlinBuckets
+// in game.WorldConstants.
+type Profile struct {
+	// One octave per weight, octave i is sampled at scale * 2^i.
+	// The octaves are averaged with these (integer) weights.
+	Weights []int64
+	// The average in [-1, 1] is mapped to [0, 2*Buckets]
+	Buckets int64
+	// MiMC rounds used by Random to pick gradient vectors
+	NumRounds int
+}
+
+// DefaultProfile double-weights the first of three octaves and buckets into [0, 32]
+var DefaultProfile = Profile{
+	Weights:   []int64{2, 1, 1},
+	Buckets:   16,
+	NumRounds: 4,
+}
+
+// at most 2^15 octaves would overflow the scale, but a handful already makes the circuit huge
+const maxOctaves = 8
+
+// keeps sum(weights) * buckets * DENOMINATOR below sqrt(p), see perlin.Modulo
+const maxWeightedBuckets = int64(1) << 32
+
+// OrDefault returns DefaultProfile if p is the zero value, p otherwise
+func (p Profile) OrDefault() Profile {
+	if len(p.Weights) == 0 && p.Buckets == 0 && p.NumRounds == 0 {
+		return DefaultProfile
+	}
+	return p
+}
+
+// Equal reports whether both profiles compile to the same circuits, the zero value equals DefaultProfile
+func (p Profile) Equal(other Profile) bool {
+	p, other = p.OrDefault(), other.OrDefault()
+	if p.Buckets != other.Buckets || p.NumRounds != other.NumRounds || len(p.Weights) != len(other.Weights) {
+		return false
+	}
+	for i := range p.Weights {
+		if p.Weights[i] != other.Weights[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that the profile can be compiled into the circuits
+func (p Profile) Validate() error {
+	if len(p.Weights) == 0 || len(p.Weights) > maxOctaves {
+		return fmt.Errorf("number of octaves must be between 1 and %d, got %d", maxOctaves, len(p.Weights))
+	}
+	if p.Buckets <= 0 {
+		return fmt.Errorf("buckets must be positive, got %d", p.Buckets)
+	}
+	if p.NumRounds <= 0 {
+		return fmt.Errorf("number of rounds must be positive, got %d", p.NumRounds)
+	}
+
+	weightedBuckets := int64(0)
+	for _, w := range p.Weights {
+		if w <= 0 {
+			return fmt.Errorf("octave weights must be positive, got %v", p.Weights)
+		}
+		if w > maxWeightedBuckets/p.Buckets || weightedBuckets+w*p.Buckets > maxWeightedBuckets {
+			return fmt.Errorf("sum of octave weights times buckets must be at most %d", maxWeightedBuckets)
+		}
+		weightedBuckets += w * p.Buckets
+	}
+	return nil
+}
+
+// TotalWeight is the sum of the octave weights
+func (p Profile) TotalWeight() int64 {
+	total := int64(0)
+	for _, w := range p.Weights {
+		total += w
+	}
+	return total
+}
+
+// ParseWeights parses comma separated octave weights, e.g. "2,1,1"
+func ParseWeights(s string) ([]int64, error) {
+	var weights []int64
+	for _, field := range strings.Split(s, ",") {
+		w, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid octave weight %q: %w", field, err)
+		}
+		weights = append(weights, w)
+	}
+	return weights, nil
+}
+
+// FormatWeights is the inverse of ParseWeights
+func FormatWeights(weights []int64) string {
+	fields := make([]string, len(weights))
+	for i, w := range weights {
+		fields[i] = strconv.FormatInt(w, 10)
+	}
+	return strings.Join(fields, ",")
+}
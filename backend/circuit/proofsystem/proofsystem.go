@@ -0,0 +1,282 @@
+// Package proofsystem hides the proving scheme behind a single interface so that the
+// artifact tooling, the prover and cardinal work the same with groth16 and PLONK (KZG).
+// Artifacts are tagged with the name of the system they were generated for.
+package proofsystem
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	kzg "github.com/consensys/gnark-crypto/ecc/bn254/fr/kzg"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/backend/plonk"
+	"github.com/consensys/gnark/backend/witness"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+	"github.com/consensys/gnark/frontend/cs/scs"
+)
+
+const (
+	Groth16Name = "groth16"
+	PlonkName   = "plonk"
+)
+
+// Serializable is implemented by proving keys, verifying keys and proofs of every system
+type Serializable interface {
+	io.WriterTo
+	io.ReaderFrom
+}
+
+type ProvingKey Serializable
+type VerifyingKey Serializable
+type Proof Serializable
+
+// ProofSystem is a proving scheme over BN254
+type ProofSystem interface {
+	Name() string
+	Compile(circuit frontend.Circuit) (constraint.ConstraintSystem, error)
+	// Setup runs a local setup, the randomness is thrown away afterwards
+	Setup(ccs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error)
+	Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error)
+	Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error
+
+	NewCS() constraint.ConstraintSystem
+	NewProvingKey() ProvingKey
+	NewVerifyingKey() VerifyingKey
+	NewProof() Proof
+	// EncodeProof returns the canonical (uncompressed) encoding of the proof
+	EncodeProof(proof Proof) ([]byte, error)
+}
+
+var (
+	Groth16 ProofSystem = groth16System{}
+	Plonk   ProofSystem = plonkSystem{}
+)
+
+// Get returns the proof system with the given name, an empty name is groth16
+// since artifacts predating the tag were all generated for it
+func Get(name string) (ProofSystem, error) {
+	switch name {
+	case Groth16Name, "":
+		return Groth16, nil
+	case PlonkName:
+		return Plonk, nil
+	default:
+		return nil, fmt.Errorf("unknown proof system %q", name)
+	}
+}
+
+type groth16System struct{}
+
+func (groth16System) Name() string {
+	return Groth16Name
+}
+
+func (groth16System) Compile(circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	return frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, circuit)
+}
+
+func (groth16System) Setup(ccs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
+	return groth16.Setup(ccs)
+}
+
+func (groth16System) Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+	groth16PK, ok := pk.(groth16.ProvingKey)
+	if !ok {
+		return nil, fmt.Errorf("expected a groth16 proving key, got %T", pk)
+	}
+	return groth16.Prove(ccs, groth16PK, fullWitness, opts...)
+}
+
+func (groth16System) Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
+	groth16Proof, ok := proof.(groth16.Proof)
+	if !ok {
+		return fmt.Errorf("expected a groth16 proof, got %T", proof)
+	}
+	groth16VK, ok := vk.(groth16.VerifyingKey)
+	if !ok {
+		return fmt.Errorf("expected a groth16 verifying key, got %T", vk)
+	}
+	return groth16.Verify(groth16Proof, groth16VK, publicWitness)
+}
+
+func (groth16System) NewCS() constraint.ConstraintSystem {
+	return groth16.NewCS(ecc.BN254)
+}
+
+func (groth16System) NewProvingKey() ProvingKey {
+	return groth16.NewProvingKey(ecc.BN254)
+}
+
+func (groth16System) NewVerifyingKey() VerifyingKey {
+	return groth16.NewVerifyingKey(ecc.BN254)
+}
+
+func (groth16System) NewProof() Proof {
+	return groth16.NewProof(ecc.BN254)
+}
+
+func (groth16System) EncodeProof(proof Proof) ([]byte, error) {
+	groth16Proof, ok := proof.(groth16.Proof)
+	if !ok {
+		return nil, fmt.Errorf("expected a groth16 proof, got %T", proof)
+	}
+	buf := bytes.Buffer{}
+	if _, err := groth16Proof.WriteRawTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+type plonkSystem struct{}
+
+// plonkProvingKey and plonkVerifyingKey write the KZG SRS after the key,
+// since gnark doesn't serialize it and the key is unusable without it
+type plonkProvingKey struct {
+	pk  plonk.ProvingKey
+	srs *kzg.SRS
+}
+
+type plonkVerifyingKey struct {
+	vk  plonk.VerifyingKey
+	srs *kzg.SRS
+}
+
+func (plonkSystem) Name() string {
+	return PlonkName
+}
+
+func (plonkSystem) Compile(circuit frontend.Circuit) (constraint.ConstraintSystem, error) {
+	return frontend.Compile(ecc.BN254.ScalarField(), scs.NewBuilder, circuit)
+}
+
+func (plonkSystem) Setup(ccs constraint.ConstraintSystem) (ProvingKey, VerifyingKey, error) {
+	alpha, err := rand.Int(rand.Reader, ecc.BN254.ScalarField())
+	if err != nil {
+		return nil, nil, err
+	}
+	size := ecc.NextPowerOfTwo(uint64(ccs.GetNbConstraints()+ccs.GetNbPublicVariables())) + 3
+	srs, err := kzg.NewSRS(size, alpha)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pk, vk, err := plonk.Setup(ccs, srs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &plonkProvingKey{pk: pk, srs: srs}, &plonkVerifyingKey{vk: vk, srs: srs}, nil
+}
+
+func (plonkSystem) Prove(ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) (Proof, error) {
+	plonkPK, ok := pk.(*plonkProvingKey)
+	if !ok {
+		return nil, fmt.Errorf("expected a plonk proving key, got %T", pk)
+	}
+	return plonk.Prove(ccs, plonkPK.pk, fullWitness, opts...)
+}
+
+func (plonkSystem) Verify(proof Proof, vk VerifyingKey, publicWitness witness.Witness) error {
+	plonkProof, ok := proof.(plonk.Proof)
+	if !ok {
+		return fmt.Errorf("expected a plonk proof, got %T", proof)
+	}
+	plonkVK, ok := vk.(*plonkVerifyingKey)
+	if !ok {
+		return fmt.Errorf("expected a plonk verifying key, got %T", vk)
+	}
+	return plonk.Verify(plonkProof, plonkVK.vk, publicWitness)
+}
+
+func (plonkSystem) NewCS() constraint.ConstraintSystem {
+	return plonk.NewCS(ecc.BN254)
+}
+
+func (plonkSystem) NewProvingKey() ProvingKey {
+	return &plonkProvingKey{pk: plonk.NewProvingKey(ecc.BN254), srs: new(kzg.SRS)}
+}
+
+func (plonkSystem) NewVerifyingKey() VerifyingKey {
+	return &plonkVerifyingKey{vk: plonk.NewVerifyingKey(ecc.BN254), srs: new(kzg.SRS)}
+}
+
+func (plonkSystem) NewProof() Proof {
+	return plonk.NewProof(ecc.BN254)
+}
+
+func (plonkSystem) EncodeProof(proof Proof) ([]byte, error) {
+	plonkProof, ok := proof.(plonk.Proof)
+	if !ok {
+		return nil, fmt.Errorf("expected a plonk proof, got %T", proof)
+	}
+	buf := bytes.Buffer{}
+	if _, err := plonkProof.WriteRawTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (k *plonkProvingKey) WriteTo(w io.Writer) (int64, error) {
+	return writeWithSRS(w, k.pk, k.srs)
+}
+
+func (k *plonkProvingKey) ReadFrom(r io.Reader) (int64, error) {
+	n, err := readWithSRS(r, k.pk, k.srs)
+	if err != nil {
+		return n, err
+	}
+	return n, k.pk.InitKZG(k.srs)
+}
+
+func (k *plonkVerifyingKey) WriteTo(w io.Writer) (int64, error) {
+	return writeWithSRS(w, k.vk, k.srs)
+}
+
+func (k *plonkVerifyingKey) ReadFrom(r io.Reader) (int64, error) {
+	n, err := readWithSRS(r, k.vk, k.srs)
+	if err != nil {
+		return n, err
+	}
+	return n, k.vk.InitKZG(k.srs)
+}
+
+func writeWithSRS(w io.Writer, key io.WriterTo, srs *kzg.SRS) (int64, error) {
+	n, err := key.WriteTo(w)
+	if err != nil {
+		return n, err
+	}
+	n2, err := srs.WriteTo(w)
+	return n + n2, err
+}
+
+func readWithSRS(r io.Reader, key io.ReaderFrom, srs *kzg.SRS) (int64, error) {
+	n, err := key.ReadFrom(r)
+	if err != nil {
+		return n, err
+	}
+	n2, err := srs.ReadFrom(r)
+	return n + n2, err
+}
+
+// ProveAndEncode is a shorthand for Prove followed by EncodeProof
+func ProveAndEncode(ps ProofSystem, ccs constraint.ConstraintSystem, pk ProvingKey, fullWitness witness.Witness, opts ...backend.ProverOption) ([]byte, error) {
+	proof, err := ps.Prove(ccs, pk, fullWitness, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return ps.EncodeProof(proof)
+}
+
+// ReadProof parses a proof in either compressed or raw encoding
+func ReadProof(ps ProofSystem, b []byte) (Proof, error) {
+	proof := ps.NewProof()
+	if _, err := proof.ReadFrom(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
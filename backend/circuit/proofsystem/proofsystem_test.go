@@ -0,0 +1,106 @@
+package proofsystem
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/frontend"
+)
+
+// x * x == y, with y public
+type squareCircuit struct {
+	X frontend.Variable
+	Y frontend.Variable `gnark:",public"`
+}
+
+func (c *squareCircuit) Define(api frontend.API) error {
+	api.AssertIsEqual(api.Mul(c.X, c.X), c.Y)
+	return nil
+}
+
+// Keys are round tripped through their serialized form like the artifacts are
+func TestRoundTrip(t *testing.T) {
+	for _, name := range []string{Groth16Name, PlonkName} {
+		t.Run(name, func(t *testing.T) {
+			ps, err := Get(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ps.Name() != name {
+				t.Fatalf("expected %s, got %s", name, ps.Name())
+			}
+
+			ccs, err := ps.Compile(&squareCircuit{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			pk, vk, err := ps.Setup(ccs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ccsBuf, pkBuf, vkBuf bytes.Buffer
+			if _, err = ccs.WriteTo(&ccsBuf); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = pk.WriteTo(&pkBuf); err != nil {
+				t.Fatal(err)
+			}
+			if _, err = vk.WriteTo(&vkBuf); err != nil {
+				t.Fatal(err)
+			}
+			readCCS := ps.NewCS()
+			if _, err = readCCS.ReadFrom(&ccsBuf); err != nil {
+				t.Fatal(err)
+			}
+			readPK := ps.NewProvingKey()
+			if _, err = readPK.ReadFrom(&pkBuf); err != nil {
+				t.Fatal(err)
+			}
+			readVK := ps.NewVerifyingKey()
+			if _, err = readVK.ReadFrom(&vkBuf); err != nil {
+				t.Fatal(err)
+			}
+
+			fullWitness, err := frontend.NewWitness(&squareCircuit{X: 3, Y: 9}, ecc.BN254.ScalarField())
+			if err != nil {
+				t.Fatal(err)
+			}
+			encoded, err := ProveAndEncode(ps, readCCS, readPK, fullWitness)
+			if err != nil {
+				t.Fatal(err)
+			}
+			proof, err := ReadProof(ps, encoded)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			publicWitness, err := frontend.NewWitness(&squareCircuit{Y: 9}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = ps.Verify(proof, readVK, publicWitness); err != nil {
+				t.Fatalf("proof does not verify: %v", err)
+			}
+
+			wrongWitness, err := frontend.NewWitness(&squareCircuit{Y: 10}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = ps.Verify(proof, readVK, wrongWitness); err == nil {
+				t.Fatal("proof verified with a wrong public input")
+			}
+		})
+	}
+}
+
+func TestMismatchedSystems(t *testing.T) {
+	proof := Plonk.NewProof()
+	if err := Groth16.Verify(proof, Groth16.NewVerifyingKey(), nil); err == nil {
+		t.Fatal("expected an error when verifying a plonk proof with groth16")
+	}
+	if _, err := Get("stark"); err == nil {
+		t.Fatal("expected an error for an unknown proof system")
+	}
+}
@@ -0,0 +1,72 @@
+package prover
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// NewHandler serves
+//
+//	POST /prove/init {x, y, r[, rimSpawn, scale, xMirror, yMirror]} -> claim-home-planet message
+//	POST /prove/move {x1, y1, x2, y2, r, distMax, energy[, nonce, scale, xMirror, yMirror]} -> send-energy message
+//	POST /prove/reveal {x, y} -> reveal-location message
+//
+// Public params missing from a request fall back to `defaults`.
+func NewHandler(p *Prover, defaults Params) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/prove/init", func(w http.ResponseWriter, r *http.Request) {
+		in := InitInput{Params: defaults}
+		if !decodeRequest(w, r, &in) {
+			return
+		}
+		msg, err := p.ProveInit(in)
+		writeResponse(w, msg, err)
+	})
+
+	mux.HandleFunc("/prove/move", func(w http.ResponseWriter, r *http.Request) {
+		in := MoveInput{Params: defaults}
+		if !decodeRequest(w, r, &in) {
+			return
+		}
+		msg, err := p.ProveMove(in)
+		writeResponse(w, msg, err)
+	})
+
+	mux.HandleFunc("/prove/reveal", func(w http.ResponseWriter, r *http.Request) {
+		var in RevealInput
+		if !decodeRequest(w, r, &in) {
+			return
+		}
+		msg, err := p.ProveReveal(in)
+		writeResponse(w, msg, err)
+	})
+
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	return mux
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, in any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %s", err), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+func writeResponse(w http.ResponseWriter, msg any, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(msg)
+}
@@ -0,0 +1,411 @@
+// Package prover builds full witnesses for the init, move and reveal circuits and
+// produces proofs in the format cardinal expects, i.e. the fields of
+// tx.ClaimHomePlanetMsg, tx.SendEnergyMsg and tx.RevealLocationMsg.
+package prover
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
+	mimcbn254 "github.com/argus-labs/darkfrontier-backend/circuit/mimc"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/argus-labs/darkfrontier-backend/circuit/native"
+	"github.com/argus-labs/darkfrontier-backend/circuit/perlin"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/backend/hint"
+	"github.com/consensys/gnark/constraint"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/std"
+)
+
+func init() {
+	// Deserialized constraint systems don't know about the hints used when they were compiled
+	std.RegisterHints()
+	hint.Register(perlin.ModuloHint)
+}
+
+// Keys and parameters the circuits were compiled with. They are not part of the witness,
+// but the prover needs them to compute the location hash and perlin values.
+// Zero values fall back to mimcbn254.DefaultNumRounds and perlin.DefaultProfile.
+type Keys struct {
+	PlanetHashKey string
+	SpaceTypeKey  string
+	MiMCNumRounds int
+	PerlinProfile perlin.Profile
+}
+
+// Params are the public inputs shared by both circuits (see game.WorldConstants)
+type Params struct {
+	Scale   int64 `json:"scale"`
+	XMirror int64 `json:"xMirror"`
+	YMirror int64 `json:"yMirror"`
+}
+
+type InitInput struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+	R int64 `json:"r"`
+	// Prove with the rim-spawn init circuit, see LoadRimSpawnInit
+	RimSpawn bool `json:"rimSpawn,omitempty"`
+	Params
+}
+
+type MoveInput struct {
+	X1      int64 `json:"x1"`
+	Y1      int64 `json:"y1"`
+	X2      int64 `json:"x2"`
+	Y2      int64 `json:"y2"`
+	R       int64 `json:"r"`
+	DistMax int64 `json:"distMax"`
+	// Not part of the circuit, passed through to the message
+	Energy int64 `json:"energy"`
+	// Zero picks a random nonce, moves with the same public inputs and nonce are only accepted once
+	Nonce uint64 `json:"nonce,omitempty"`
+	Params
+}
+
+type RevealInput struct {
+	X int64 `json:"x"`
+	Y int64 `json:"y"`
+}
+
+// Same shape as tx.ClaimHomePlanetMsg
+type ClaimHomePlanetMsg struct {
+	LocationHash string `json:"locationHash"`
+	Perlin       int64  `json:"perlin"`
+	Radius       int64  `json:"radius"`
+	Proof        string `json:"proof"`
+}
+
+// Same shape as tx.SendEnergyMsg
+type SendEnergyMsg struct {
+	LocationHashFrom string `json:"locationHashFrom"`
+	LocationHashTo   string `json:"locationHashTo"`
+	PerlinTo         int64  `json:"perlinTo"`
+	RadiusTo         int64  `json:"radiusTo"`
+	MaxDistance      int64  `json:"maxDistance"`
+	Energy           int64  `json:"energy"`
+	Nonce            uint64 `json:"nonce"`
+	Proof            string `json:"proof"`
+}
+
+// Same shape as tx.RevealLocationMsg
+type RevealLocationMsg struct {
+	LocationHash string `json:"locationHash"`
+	X            int64  `json:"x"`
+	Y            int64  `json:"y"`
+	Proof        string `json:"proof"`
+}
+
+type Prover struct {
+	ps       proofsystem.ProofSystem
+	keys     Keys
+	initCS   constraint.ConstraintSystem
+	initPK   proofsystem.ProvingKey
+	moveCS   constraint.ConstraintSystem
+	movePK   proofsystem.ProvingKey
+	revealCS constraint.ConstraintSystem
+	revealPK proofsystem.ProvingKey
+	// nil unless LoadRimSpawnInit was called
+	initRimCS constraint.ConstraintSystem
+	initRimPK proofsystem.ProvingKey
+}
+
+// NewProver parses serialized constraint systems and proving keys generated for ps,
+// e.g. artifacts.InitConstraintSystem and artifacts.InitProvingKey
+func NewProver(ps proofsystem.ProofSystem, keys Keys, initCS, initPK, moveCS, movePK, revealCS, revealPK []byte) (*Prover, error) {
+	p := &Prover{
+		ps:       ps,
+		keys:     keys,
+		initCS:   ps.NewCS(),
+		initPK:   ps.NewProvingKey(),
+		moveCS:   ps.NewCS(),
+		movePK:   ps.NewProvingKey(),
+		revealCS: ps.NewCS(),
+		revealPK: ps.NewProvingKey(),
+	}
+
+	if _, err := p.initCS.ReadFrom(bytes.NewReader(initCS)); err != nil {
+		return nil, fmt.Errorf("failed to read init constraint system: %w", err)
+	}
+	if _, err := p.initPK.ReadFrom(bytes.NewReader(initPK)); err != nil {
+		return nil, fmt.Errorf("failed to read init proving key: %w", err)
+	}
+	if _, err := p.moveCS.ReadFrom(bytes.NewReader(moveCS)); err != nil {
+		return nil, fmt.Errorf("failed to read move constraint system: %w", err)
+	}
+	if _, err := p.movePK.ReadFrom(bytes.NewReader(movePK)); err != nil {
+		return nil, fmt.Errorf("failed to read move proving key: %w", err)
+	}
+	if _, err := p.revealCS.ReadFrom(bytes.NewReader(revealCS)); err != nil {
+		return nil, fmt.Errorf("failed to read reveal constraint system: %w", err)
+	}
+	if _, err := p.revealPK.ReadFrom(bytes.NewReader(revealPK)); err != nil {
+		return nil, fmt.Errorf("failed to read reveal proving key: %w", err)
+	}
+
+	return p, nil
+}
+
+// NewProverFromKeys uses already deserialized constraint systems and proving keys
+func NewProverFromKeys(
+	ps proofsystem.ProofSystem,
+	keys Keys,
+	initCS constraint.ConstraintSystem,
+	initPK proofsystem.ProvingKey,
+	moveCS constraint.ConstraintSystem,
+	movePK proofsystem.ProvingKey,
+	revealCS constraint.ConstraintSystem,
+	revealPK proofsystem.ProvingKey,
+) *Prover {
+	return &Prover{
+		ps:       ps,
+		keys:     keys,
+		initCS:   initCS,
+		initPK:   initPK,
+		moveCS:   moveCS,
+		movePK:   movePK,
+		revealCS: revealCS,
+		revealPK: revealPK,
+	}
+}
+
+// LoadRimSpawnInit parses the serialized rim-spawn init constraint system and proving key,
+// e.g. artifacts.InitRimConstraintSystem and artifacts.InitRimProvingKey, so that ProveInit
+// can prove InitInput with RimSpawn set
+func (p *Prover) LoadRimSpawnInit(initRimCS, initRimPK []byte) error {
+	cs := p.ps.NewCS()
+	if _, err := cs.ReadFrom(bytes.NewReader(initRimCS)); err != nil {
+		return fmt.Errorf("failed to read rim-spawn init constraint system: %w", err)
+	}
+	pk := p.ps.NewProvingKey()
+	if _, err := pk.ReadFrom(bytes.NewReader(initRimPK)); err != nil {
+		return fmt.Errorf("failed to read rim-spawn init proving key: %w", err)
+	}
+	p.initRimCS, p.initRimPK = cs, pk
+	return nil
+}
+
+// LocationHash returns MiMC(x, y) the same way the circuits do, hex encoded without 0x prefix
+func (p *Prover) LocationHash(x, y int64) string {
+	mimc := native.NewMiMC(p.keys.PlanetHashKey, p.keys.mimcNumRounds())
+	mimc.WriteInt64(x, y)
+	return fmt.Sprintf("%064x", mimc.Sum())
+}
+
+// Perlin returns the perlin value of (x, y) the same way the circuits do
+func (p *Prover) Perlin(x, y int64, params Params) (int64, error) {
+	return native.MultiScalePerlin(
+		[2]int64{x, y}, params.Scale, params.XMirror, params.YMirror, p.keys.SpaceTypeKey, p.keys.PerlinProfile.OrDefault(),
+	)
+}
+
+func (k Keys) mimcNumRounds() int {
+	if k.MiMCNumRounds == 0 {
+		return mimcbn254.DefaultNumRounds
+	}
+	return k.MiMCNumRounds
+}
+
+// Equal reports whether both keys compile to the same circuits, zero values are compared as their defaults
+func (k Keys) Equal(other Keys) bool {
+	return k.PlanetHashKey == other.PlanetHashKey && k.SpaceTypeKey == other.SpaceTypeKey &&
+		k.mimcNumRounds() == other.mimcNumRounds() && k.PerlinProfile.Equal(other.PerlinProfile)
+}
+
+// ProveInit proves knowledge of (x, y) inside radius r and returns a claim-home-planet message.
+// With RimSpawn (x, y) must also be in the rim of the radius.
+func (p *Prover) ProveInit(in InitInput) (ClaimHomePlanetMsg, error) {
+	if !isInsideRadius(in.X, in.Y, in.R) {
+		return ClaimHomePlanetMsg{}, fmt.Errorf("(%d, %d) is not inside radius %d", in.X, in.Y, in.R)
+	}
+	cs, pk := p.initCS, p.initPK
+	if in.RimSpawn {
+		if p.initRimCS == nil {
+			return ClaimHomePlanetMsg{}, fmt.Errorf("rim-spawn init circuit is not loaded")
+		}
+		if !isInsideRim(in.X, in.Y, in.R) {
+			return ClaimHomePlanetMsg{}, fmt.Errorf("(%d, %d) is not in the rim of radius %d", in.X, in.Y, in.R)
+		}
+		cs, pk = p.initRimCS, p.initRimPK
+	}
+
+	perl, err := p.Perlin(in.X, in.Y, in.Params)
+	if err != nil {
+		return ClaimHomePlanetMsg{}, err
+	}
+	locationHash := p.LocationHash(in.X, in.Y)
+	pub, _ := new(big.Int).SetString(locationHash, 16)
+
+	assignment := initialize.InitCircuit{
+		X:       in.X,
+		Y:       in.Y,
+		R:       in.R,
+		Scale:   in.Scale,
+		XMirror: in.XMirror,
+		YMirror: in.YMirror,
+		Pub:     pub,
+		Perl:    perl,
+	}
+	proof, err := p.prove(cs, pk, &assignment)
+	if err != nil {
+		return ClaimHomePlanetMsg{}, fmt.Errorf("failed to prove init circuit: %w", err)
+	}
+
+	return ClaimHomePlanetMsg{
+		LocationHash: locationHash,
+		Perlin:       perl,
+		Radius:       in.R,
+		Proof:        proof,
+	}, nil
+}
+
+// ProveMove proves a move from (x1, y1) to (x2, y2) and returns a send-energy message
+func (p *Prover) ProveMove(in MoveInput) (SendEnergyMsg, error) {
+	if !isInsideRadius(in.X2, in.Y2, in.R) {
+		return SendEnergyMsg{}, fmt.Errorf("(%d, %d) is not inside radius %d", in.X2, in.Y2, in.R)
+	}
+	if !isWithinDistance(in.X1, in.Y1, in.X2, in.Y2, in.DistMax) {
+		return SendEnergyMsg{}, fmt.Errorf(
+			"(%d, %d) is further than %d away from (%d, %d)", in.X2, in.Y2, in.DistMax, in.X1, in.Y1,
+		)
+	}
+
+	perl2, err := p.Perlin(in.X2, in.Y2, in.Params)
+	if err != nil {
+		return SendEnergyMsg{}, err
+	}
+	locationHashFrom := p.LocationHash(in.X1, in.Y1)
+	locationHashTo := p.LocationHash(in.X2, in.Y2)
+	pub1, _ := new(big.Int).SetString(locationHashFrom, 16)
+	pub2, _ := new(big.Int).SetString(locationHashTo, 16)
+	nonce := in.Nonce
+	if nonce == 0 {
+		if nonce, err = randomNonce(); err != nil {
+			return SendEnergyMsg{}, err
+		}
+	}
+
+	assignment := move.MoveCircuit{
+		X1:      in.X1,
+		Y1:      in.Y1,
+		X2:      in.X2,
+		Y2:      in.Y2,
+		R:       in.R,
+		DistMax: in.DistMax,
+		Scale:   in.Scale,
+		XMirror: in.XMirror,
+		YMirror: in.YMirror,
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   perl2,
+		Nonce:   nonce,
+	}
+	proof, err := p.prove(p.moveCS, p.movePK, &assignment)
+	if err != nil {
+		return SendEnergyMsg{}, fmt.Errorf("failed to prove move circuit: %w", err)
+	}
+
+	return SendEnergyMsg{
+		LocationHashFrom: locationHashFrom,
+		LocationHashTo:   locationHashTo,
+		PerlinTo:         perl2,
+		RadiusTo:         in.R,
+		MaxDistance:      in.DistMax,
+		Energy:           in.Energy,
+		Nonce:            nonce,
+		Proof:            proof,
+	}, nil
+}
+
+// ProveReveal proves that (x, y) hashes to its location hash and returns a reveal-location message
+func (p *Prover) ProveReveal(in RevealInput) (RevealLocationMsg, error) {
+	if in.X > maxAbsCoord || in.X < -maxAbsCoord || in.Y > maxAbsCoord || in.Y < -maxAbsCoord {
+		return RevealLocationMsg{}, fmt.Errorf("(%d, %d) is out of range, abs value must be at most 2^31", in.X, in.Y)
+	}
+
+	locationHash := p.LocationHash(in.X, in.Y)
+	pub, _ := new(big.Int).SetString(locationHash, 16)
+
+	assignment := reveal.RevealCircuit{
+		X:   in.X,
+		Y:   in.Y,
+		Pub: pub,
+	}
+	proof, err := p.prove(p.revealCS, p.revealPK, &assignment)
+	if err != nil {
+		return RevealLocationMsg{}, fmt.Errorf("failed to prove reveal circuit: %w", err)
+	}
+
+	return RevealLocationMsg{
+		LocationHash: locationHash,
+		X:            in.X,
+		Y:            in.Y,
+		Proof:        proof,
+	}, nil
+}
+
+// returns the proof in raw form, base64 encoded
+func (p *Prover) prove(cs constraint.ConstraintSystem, pk proofsystem.ProvingKey, assignment frontend.Circuit) (string, error) {
+	fullWitness, err := frontend.NewWitness(assignment, ecc.BN254.ScalarField())
+	if err != nil {
+		return "", err
+	}
+
+	proof, err := proofsystem.ProveAndEncode(p.ps, cs, pk, fullWitness, backend.WithHints(perlin.ModuloHint))
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(proof), nil
+}
+
+// returns a non-zero random move nonce
+func randomNonce() (uint64, error) {
+	var b [8]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return 0, fmt.Errorf("failed to generate nonce: %w", err)
+		}
+		if nonce := binary.BigEndian.Uint64(b[:]); nonce != 0 {
+			return nonce, nil
+		}
+	}
+}
+
+// coordinates are range proven to this absolute value by all circuits
+const maxAbsCoord = int64(1) << 31
+
+// x^2 + y^2 <= r^2 - 1, as constrained by both circuits
+func isInsideRadius(x, y, r int64) bool {
+	rSq := new(big.Int).Mul(big.NewInt(r), big.NewInt(r))
+	return sumOfSquares(x, y).Cmp(rSq.Sub(rSq, big.NewInt(1))) <= 0
+}
+
+// 100 * (x^2 + y^2) > 98 * r^2, as constrained by the rim-spawn init circuit
+func isInsideRim(x, y, r int64) bool {
+	rim := new(big.Int).Mul(big.NewInt(r), big.NewInt(r))
+	rim.Mul(rim, big.NewInt(initialize.RimSpawnPercent))
+	dist := sumOfSquares(x, y)
+	return dist.Mul(dist, big.NewInt(100)).Cmp(rim) > 0
+}
+
+// (x1-x2)^2 + (y1-y2)^2 <= distMax^2, as constrained by the move circuit
+func isWithinDistance(x1, y1, x2, y2, distMax int64) bool {
+	distMaxSq := new(big.Int).Mul(big.NewInt(distMax), big.NewInt(distMax))
+	return sumOfSquares(x1-x2, y1-y2).Cmp(distMaxSq) <= 0
+}
+
+func sumOfSquares(x, y int64) *big.Int {
+	xSq := new(big.Int).Mul(big.NewInt(x), big.NewInt(x))
+	ySq := new(big.Int).Mul(big.NewInt(y), big.NewInt(y))
+	return xSq.Add(xSq, ySq)
+}
@@ -0,0 +1,359 @@
+package prover
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/argus-labs/darkfrontier-backend/circuit"
+	"github.com/argus-labs/darkfrontier-backend/circuit/initialize"
+	"github.com/argus-labs/darkfrontier-backend/circuit/move"
+	"github.com/argus-labs/darkfrontier-backend/circuit/proofsystem"
+	"github.com/argus-labs/darkfrontier-backend/circuit/reveal"
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend/groth16"
+	"github.com/consensys/gnark/frontend"
+	"github.com/consensys/gnark/frontend/cs/r1cs"
+)
+
+// Keys of the planets generated by the client, see cardinal/test/init.go
+const (
+	testPlanetHashKey = "7"
+	testSpaceTypeKey  = "7"
+)
+
+var (
+	setupOnce                sync.Once
+	testProver               *Prover
+	initVK, moveVK, revealVK groth16.VerifyingKey
+	testParams               = Params{Scale: circuit.Scale, XMirror: circuit.XMirror, YMirror: circuit.YMirror}
+)
+
+// Compiles all circuits and round trips the artifacts through NewProver
+func setup(t *testing.T) {
+	setupOnce.Do(func() {
+		keys := Keys{PlanetHashKey: testPlanetHashKey, SpaceTypeKey: testSpaceTypeKey}
+
+		var initCircuit initialize.InitCircuit
+		initCircuit.PlanetHashKey = keys.PlanetHashKey
+		initCircuit.SpaceTypeKey = keys.SpaceTypeKey
+		initCS, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &initCircuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		initPK, vk, err := groth16.Setup(initCS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		initVK = vk
+
+		var moveCircuit move.MoveCircuit
+		moveCircuit.PlanetHashKey = keys.PlanetHashKey
+		moveCircuit.SpaceTypeKey = keys.SpaceTypeKey
+		moveCS, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &moveCircuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		movePK, vk, err := groth16.Setup(moveCS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moveVK = vk
+
+		var revealCircuit reveal.RevealCircuit
+		revealCircuit.PlanetHashKey = keys.PlanetHashKey
+		revealCS, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &revealCircuit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revealPK, vk, err := groth16.Setup(revealCS)
+		if err != nil {
+			t.Fatal(err)
+		}
+		revealVK = vk
+
+		var buffers [6]bytes.Buffer
+		_, _ = initCS.WriteTo(&buffers[0])
+		_, _ = initPK.WriteTo(&buffers[1])
+		_, _ = moveCS.WriteTo(&buffers[2])
+		_, _ = movePK.WriteTo(&buffers[3])
+		_, _ = revealCS.WriteTo(&buffers[4])
+		_, _ = revealPK.WriteTo(&buffers[5])
+
+		testProver, err = NewProver(
+			proofsystem.Groth16,
+			keys,
+			buffers[0].Bytes(),
+			buffers[1].Bytes(),
+			buffers[2].Bytes(),
+			buffers[3].Bytes(),
+			buffers[4].Bytes(),
+			buffers[5].Bytes(),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	if testProver == nil {
+		t.Fatal("prover setup failed")
+	}
+}
+
+// Verifies the proof the same way cardinal does in tx.ClaimHomePlanetMsg
+func verifyInit(t *testing.T, msg ClaimHomePlanetMsg, params Params) error {
+	return verifyInitWith(t, initVK, msg, params)
+}
+
+func verifyInitWith(t *testing.T, vk groth16.VerifyingKey, msg ClaimHomePlanetMsg, params Params) error {
+	pub, _ := new(big.Int).SetString(msg.LocationHash, 16)
+	publicWitness, err := frontend.NewWitness(&initialize.InitCircuit{
+		R:       msg.Radius,
+		Scale:   params.Scale,
+		XMirror: params.XMirror,
+		YMirror: params.YMirror,
+		Pub:     pub,
+		Perl:    msg.Perlin,
+	}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return groth16.Verify(readProof(t, msg.Proof), vk, publicWitness)
+}
+
+// Verifies the proof the same way cardinal does in tx.SendEnergyMsg
+func verifyMove(t *testing.T, msg SendEnergyMsg, params Params) error {
+	pub1, _ := new(big.Int).SetString(msg.LocationHashFrom, 16)
+	pub2, _ := new(big.Int).SetString(msg.LocationHashTo, 16)
+	publicWitness, err := frontend.NewWitness(&move.MoveCircuit{
+		R:       msg.RadiusTo,
+		DistMax: msg.MaxDistance,
+		Scale:   params.Scale,
+		XMirror: params.XMirror,
+		YMirror: params.YMirror,
+		Pub1:    pub1,
+		Pub2:    pub2,
+		Perl2:   msg.PerlinTo,
+		Nonce:   msg.Nonce,
+	}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return groth16.Verify(readProof(t, msg.Proof), moveVK, publicWitness)
+}
+
+// Verifies the proof the same way cardinal does in tx.RevealLocationMsg
+func verifyReveal(t *testing.T, msg RevealLocationMsg) error {
+	pub, _ := new(big.Int).SetString(msg.LocationHash, 16)
+	publicWitness, err := frontend.NewWitness(&reveal.RevealCircuit{
+		X:   msg.X,
+		Y:   msg.Y,
+		Pub: pub,
+	}, ecc.BN254.ScalarField(), frontend.PublicOnly())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return groth16.Verify(readProof(t, msg.Proof), revealVK, publicWitness)
+}
+
+func readProof(t *testing.T, encoded string) groth16.Proof {
+	proofBytes, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof := groth16.NewProof(ecc.BN254)
+	if _, err = proof.ReadFrom(bytes.NewReader(proofBytes)); err != nil {
+		t.Fatal(err)
+	}
+	return proof
+}
+
+func TestProveInit(t *testing.T) {
+	setup(t)
+
+	msg, err := testProver.ProveInit(InitInput{X: 3, Y: -3, R: circuit.RadiusMax, Params: testParams})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.LocationHash != "0d01f8778431d6f04310bf3f02fb6e85a173624241fc8d8185c528306f57ca68" {
+		t.Fatalf("unexpected location hash %s", msg.LocationHash)
+	}
+	if msg.Perlin != 16 {
+		t.Fatalf("unexpected perlin %d", msg.Perlin)
+	}
+	if err = verifyInit(t, msg, testParams); err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+
+	// tampering with a public input must break verification
+	msg.Perlin++
+	if err = verifyInit(t, msg, testParams); err == nil {
+		t.Fatal("proof verified with a wrong perlin value")
+	}
+}
+
+func TestProveInitOutsideRadius(t *testing.T) {
+	setup(t)
+
+	_, err := testProver.ProveInit(InitInput{X: 400, Y: 400, R: circuit.RadiusMax, Params: testParams})
+	if err == nil {
+		t.Fatal("expected error for a planet outside the radius")
+	}
+}
+
+func TestProveInitRimSpawn(t *testing.T) {
+	setup(t)
+
+	in := InitInput{X: 8, Y: 4, R: 9, RimSpawn: true, Params: testParams}
+	if _, err := testProver.ProveInit(in); err == nil {
+		t.Fatal("expected error before the rim-spawn circuit is loaded")
+	}
+
+	var rimCircuit initialize.InitCircuit
+	rimCircuit.PlanetHashKey = testPlanetHashKey
+	rimCircuit.SpaceTypeKey = testSpaceTypeKey
+	rimCircuit.RimSpawn = true
+	rimCS, err := frontend.Compile(ecc.BN254.ScalarField(), r1cs.NewBuilder, &rimCircuit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rimPK, rimVK, err := groth16.Setup(rimCS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var csBuf, pkBuf bytes.Buffer
+	_, _ = rimCS.WriteTo(&csBuf)
+	_, _ = rimPK.WriteTo(&pkBuf)
+
+	// don't leak the rim-spawn circuit into the other tests
+	rimProver := *testProver
+	if err = rimProver.LoadRimSpawnInit(csBuf.Bytes(), pkBuf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+
+	msg, err := rimProver.ProveInit(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyInitWith(t, rimVK, msg, testParams); err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+	// the regular init circuit has a different verifying key
+	if err = verifyInit(t, msg, testParams); err == nil {
+		t.Fatal("rim-spawn proof verified against the init verifying key")
+	}
+
+	// 100 * 80 <= 98 * 10^2
+	in.R = 10
+	if _, err = rimProver.ProveInit(in); err == nil {
+		t.Fatal("expected error for a planet inside the rim")
+	}
+}
+
+func TestProveMove(t *testing.T) {
+	setup(t)
+
+	msg, err := testProver.ProveMove(MoveInput{
+		X1: 3, Y1: -3, X2: 11, Y2: -10, R: circuit.RadiusMax, DistMax: 11, Energy: 42, Params: testParams,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.LocationHashFrom != "0d01f8778431d6f04310bf3f02fb6e85a173624241fc8d8185c528306f57ca68" ||
+		msg.LocationHashTo != "0d00974b8d6df596c0eaf0c8fb15a45dc2da397c3f2d2107661feea02d200e27" {
+		t.Fatalf("unexpected location hashes %s -> %s", msg.LocationHashFrom, msg.LocationHashTo)
+	}
+	if msg.PerlinTo != 16 || msg.RadiusTo != circuit.RadiusMax || msg.MaxDistance != 11 || msg.Energy != 42 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if err = verifyMove(t, msg, testParams); err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+
+	// the nonce is a public input, the proof must not verify for another one
+	if msg.Nonce == 0 {
+		t.Fatal("expected a random nonce")
+	}
+	msg.Nonce++
+	if err = verifyMove(t, msg, testParams); err == nil {
+		t.Fatal("proof verified with another nonce")
+	}
+}
+
+func TestProveMoveOutOfRange(t *testing.T) {
+	setup(t)
+
+	// distance between (3, -3) and (11, -10) is ~10.6
+	_, err := testProver.ProveMove(MoveInput{
+		X1: 3, Y1: -3, X2: 11, Y2: -10, R: circuit.RadiusMax, DistMax: 10, Params: testParams,
+	})
+	if err == nil {
+		t.Fatal("expected error for a move further than distMax")
+	}
+}
+
+func TestProveReveal(t *testing.T) {
+	setup(t)
+
+	msg, err := testProver.ProveReveal(RevealInput{X: 3, Y: -3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.LocationHash != "0d01f8778431d6f04310bf3f02fb6e85a173624241fc8d8185c528306f57ca68" || msg.X != 3 || msg.Y != -3 {
+		t.Fatalf("unexpected message %+v", msg)
+	}
+	if err = verifyReveal(t, msg); err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+
+	// claiming other coordinates for the same location hash must break verification
+	msg.X = 4
+	if err = verifyReveal(t, msg); err == nil {
+		t.Fatal("proof verified with wrong coordinates")
+	}
+
+	if _, err = testProver.ProveReveal(RevealInput{X: 1 << 32, Y: 0}); err == nil {
+		t.Fatal("expected error for coordinates out of range")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	setup(t)
+
+	server := httptest.NewServer(NewHandler(testProver, testParams))
+	defer server.Close()
+
+	res, err := http.Post(server.URL+"/prove/init", "application/json", strings.NewReader(`{"x": 3, "y": -3, "r": 500}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", res.StatusCode)
+	}
+
+	var msg ClaimHomePlanetMsg
+	if err = json.NewDecoder(res.Body).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if err = verifyInit(t, msg, testParams); err != nil {
+		t.Fatalf("proof does not verify: %v", err)
+	}
+
+	res, err = http.Post(server.URL+"/prove/init", "application/json", strings.NewReader(`{"x": 400, "y": 400, "r": 500}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected status %d, got %d", http.StatusUnprocessableEntity, res.StatusCode)
+	}
+}
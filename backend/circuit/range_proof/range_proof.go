@@ -1,6 +1,7 @@
 package range_proof
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/consensys/gnark/frontend"
@@ -41,24 +42,22 @@ func RangeProof(
 	// I don't like the third option, and I only want to do the second if the first is wrong
 	inPlusMax := api.Add(in, maxAbsValue)
 	twoMax := api.Mul(maxAbsValue, 2)
-	// api.AssertIsLessOrEqual giving me issues here
-	// (can't pass in an int literal to first arg),
-	// so using api.Cmp
-	// https://github.com/ConsenSys/gnark/pull/511 should fix this
-	// api.AssertIsDifferent(
-	// 	api.Cmp(
-	// 		0,
-	// 		inPlusMax,
-	// 	),
-	// 	1,
-	// )
-	api.AssertIsDifferent(
-		api.Cmp(
-			inPlusMax,
-			twoMax,
-		),
-		1,
-	)
+	// api.Cmp is not an option here: the PLONK (scs) builder can't mark the
+	// bits of a constant operand as boolean, and maxAbsValue often is one
+	// (neither can AssertIsLessOrEqual take a constant first arg, so a
+	// constant in + max is checked while compiling)
+	if c, ok := api.Compiler().ConstantValue(inPlusMax); ok {
+		if bound, ok := api.Compiler().ConstantValue(twoMax); ok {
+			if c.Cmp(bound) > 0 {
+				panic(fmt.Sprintf("constant %s is out of range [0, %s]", c, bound))
+			}
+			return
+		}
+		// in and max fit in numBits bits, so 2*max - (in + max) does too unless it's negative
+		api.ToBinary(api.Sub(twoMax, inPlusMax), numBits+2)
+		return
+	}
+	api.AssertIsLessOrEqual(inPlusMax, twoMax)
 }
 
 // Constrain 0 <= abs(in[i]) <= maxAbsValue
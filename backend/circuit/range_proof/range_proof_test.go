@@ -138,3 +138,36 @@ func TestMultiRangeFuzz(t *testing.T) {
 
 	assert.Fuzz(&circuit, 100)
 }
+
+// Constant bounds, as perlin.Modulo uses, must compile for PLONK too
+type ConstantMaxRangeCircuit struct {
+	In frontend.Variable
+}
+
+func (circuit *ConstantMaxRangeCircuit) Define(api frontend.API) error {
+	RangeProof(api, 64, 100, circuit.In)
+	RangeProof(api, 64, 100, 42)
+	return nil
+}
+
+func TestConstantMaxRange(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	var circuit ConstantMaxRangeCircuit
+
+	assert.ProverSucceeded(
+		&circuit,
+		&ConstantMaxRangeCircuit{In: -100},
+		test.NoFuzzing(),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16, backend.PLONK),
+	)
+
+	assert.ProverFailed(
+		&circuit,
+		&ConstantMaxRangeCircuit{In: 101},
+		test.NoFuzzing(),
+		test.WithCurves(ecc.BN254),
+		test.WithBackends(backend.GROTH16, backend.PLONK),
+	)
+}
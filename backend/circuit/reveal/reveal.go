@@ -0,0 +1,70 @@
+package reveal
+
+import (
+	"math/big"
+
+	mimcbn254 "github.com/argus-labs/darkfrontier-backend/circuit/mimc"
+
+	"github.com/consensys/gnark/frontend"
+)
+
+// Unlike the init and move circuits the coordinates are public,
+// the proof publishes where the planet with location hash Pub is
+type RevealCircuit struct {
+	PlanetHashKey string
+	// Zero falls back to mimcbn254.DefaultNumRounds
+	MiMCNumRounds int
+	X             frontend.Variable `gnark:",public"`
+	Y             frontend.Variable `gnark:",public"`
+	Pub           frontend.Variable `gnark:",public"`
+}
+
+func (circuit *RevealCircuit) Define(api frontend.API) error {
+	mimcNumRounds := circuit.MiMCNumRounds
+	if mimcNumRounds == 0 {
+		mimcNumRounds = mimcbn254.DefaultNumRounds
+	}
+
+	pub, err := Reveal(
+		api,
+		circuit.X,
+		circuit.Y,
+		circuit.PlanetHashKey,
+		mimcNumRounds,
+	)
+
+	////////////////////////////
+	// Check MiMC(x, y) = pub //
+	////////////////////////////
+	api.AssertIsEqual(pub, circuit.Pub)
+
+	return err
+}
+
+// Prove: (x,y) is a valid location such that:
+// - abs(x), abs(y) <= 2^31
+// - MiMC(x,y) = pub
+func Reveal(
+	api frontend.API,
+	x frontend.Variable,
+	y frontend.Variable,
+	planetHashKey string,
+	mimcNumRounds int,
+) (frontend.Variable, error) {
+	//////////////////////////////////
+	// Check abs(x), abs(y) <= 2^31 //
+	//////////////////////////////////
+	lShift := big.NewInt(1)
+	lShift.Lsh(lShift, uint(31))
+	api.ToBinary(api.Add(x, lShift), 32)
+	api.ToBinary(api.Add(y, lShift), 32)
+
+	// Same hash as the init and move circuits, see mimcbn254.DefaultNumRounds
+	mimc, err := mimcbn254.NewMiMC(api, planetHashKey, mimcNumRounds)
+	if err != nil {
+		return nil, err
+	}
+	mimc.Write(x, y)
+
+	return mimc.Sum(), nil
+}
@@ -0,0 +1,76 @@
+package reveal
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/consensys/gnark-crypto/ecc"
+	"github.com/consensys/gnark/backend"
+	"github.com/consensys/gnark/test"
+)
+
+// Key of the planets generated by the client, see cardinal/test/init.go
+const testPlanetHashKey = "7"
+
+var (
+	revealCircuit RevealCircuit
+	pub           *big.Int
+)
+
+func init() {
+	revealCircuit.PlanetHashKey = testPlanetHashKey
+
+	var ok bool
+	// Generated by MiMCSharp, same planet as initialize.TestInit
+	pub, ok = new(big.Int).SetString("0d01f8778431d6f04310bf3f02fb6e85a173624241fc8d8185c528306f57ca68", 16)
+	if !ok {
+		panic("failed to parse big.Int from MiMCSharp string output")
+	}
+}
+
+func TestReveal(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverSucceeded(
+		&revealCircuit,
+		&RevealCircuit{
+			X:   3,
+			Y:   -3,
+			Pub: pub,
+		},
+		test.WithBackends(backend.GROTH16, backend.PLONK),
+		test.WithCurves(ecc.BN254),
+	)
+}
+
+// Revealing other coordinates for the same location hash must fail
+func TestRevealWrongCoordinates(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverFailed(
+		&revealCircuit,
+		&RevealCircuit{
+			X:   3,
+			Y:   -4, // Changed from -3 to -4
+			Pub: pub,
+		},
+		test.WithBackends(backend.GROTH16, backend.PLONK),
+		test.WithCurves(ecc.BN254),
+	)
+}
+
+// Coordinates must be within the range the other circuits accept
+func TestRevealCoordinatesOutOfRange(t *testing.T) {
+	assert := test.NewAssert(t)
+
+	assert.ProverFailed(
+		&revealCircuit,
+		&RevealCircuit{
+			X:   new(big.Int).Lsh(big.NewInt(1), 40),
+			Y:   -3,
+			Pub: pub,
+		},
+		test.WithBackends(backend.GROTH16),
+		test.WithCurves(ecc.BN254),
+	)
+}
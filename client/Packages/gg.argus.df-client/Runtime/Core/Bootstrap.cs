@@ -508,10 +508,13 @@ private void InitializeOnlineEvents()
             // ReSharper disable once AsyncVoidLambda - Decent broadly scoped advice, but this is fine.
             _eventManager.GameplayEvents.SendEnergyRequested += async (positionFrom, positionTo, msg) =>
             {
+                msg.nonce = MoveAssignment.NewNonce();
+                
                 (ProofResponse response, bool wasSuccessful) = await _cloudProver.MoveRequest(
                     positionFrom, positionTo,
                     msg.locationHashFrom, msg.locationHashTo,
                     msg.perlinTo,
+                    msg.nonce,
                     _gameConfig,
                     Application.exitCancellationToken);
 
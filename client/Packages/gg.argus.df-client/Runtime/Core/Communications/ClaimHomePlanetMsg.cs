@@ -24,6 +24,8 @@ public struct ClaimHomePlanetMsg
     {
         public string locationHash;
         public int perlin;
+        // Radius the "init" circuit was proven against, must not exceed the world radius
+        public long radius;
         // Proof from the GenerateProofResponse for the "init" circuit
         public string proof;
     }
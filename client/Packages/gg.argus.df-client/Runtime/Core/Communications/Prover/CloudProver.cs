@@ -37,9 +37,9 @@ public async Task<(ProofResponse, bool)> InitRequest(Vector2Int p, string locati
             return await RequestProofAsync(msg, cancellation);
         }
         
-        public async Task<(ProofResponse, bool)> MoveRequest(Vector2Int positionFrom, Vector2Int positionTo, string locationHashFrom, string locationHashTo, int PerlinTo, GameConfig config, CancellationToken cancellation)
+        public async Task<(ProofResponse, bool)> MoveRequest(Vector2Int positionFrom, Vector2Int positionTo, string locationHashFrom, string locationHashTo, int PerlinTo, long nonce, GameConfig config, CancellationToken cancellation)
         {
-            var assignment = new MoveAssignment(positionFrom, positionTo, locationHashFrom, locationHashTo, PerlinTo, config);
+            var assignment = new MoveAssignment(positionFrom, positionTo, locationHashFrom, locationHashTo, PerlinTo, nonce, config);
             var msg = new GenerateProofRequestMsg
             {
                 circuitType = "move",
@@ -13,6 +13,7 @@
 // limitations under the License.
 
 using System;
+using System.Security.Cryptography;
 using ArgusLabs.DF.Core.Configs;
 using UnityEngine;
 
@@ -36,8 +37,9 @@ public sealed class MoveAssignment
         public string pub1; // LocationHashFrom
         public string pub2; // LocationHashTo
         public string perl2; // PerlinTo
+        public string nonce; // Nonce, tells apart moves along the same route
 
-        public MoveAssignment(Vector2Int positionFrom, Vector2Int positionTo, string locationHashFrom, string locationHashTo, int perlinTo, GameConfig config)
+        public MoveAssignment(Vector2Int positionFrom, Vector2Int positionTo, string locationHashFrom, string locationHashTo, int perlinTo, long moveNonce, GameConfig config)
         {
             x1 = positionFrom.x.ToString();
             y1 = positionFrom.y.ToString();
@@ -51,6 +53,19 @@ public MoveAssignment(Vector2Int positionFrom, Vector2Int positionTo, string loc
             pub1 = locationHashFrom;
             pub2 = locationHashTo;
             perl2 = perlinTo.ToString();
+            nonce = moveNonce.ToString();
+        }
+
+        /// <summary>
+        /// Cardinal accepts every combination of public inputs only once, so each move needs a fresh nonce.
+        /// </summary>
+        public static long NewNonce()
+        {
+            var bytes = new byte[8];
+            using (var rng = RandomNumberGenerator.Create())
+                rng.GetBytes(bytes);
+
+            return (BitConverter.ToInt64(bytes, 0) & long.MaxValue) | 1;
         }
     }
 }
\ No newline at end of file
@@ -29,6 +29,8 @@ public struct SendEnergyMsg
         public int maxDistance;
         public int energy;
         public int requestId;
+        // Public input of the "move" circuit, must be the nonce the proof was generated with
+        public long nonce;
         // Proof from the GenerateProofResponse for the "move" circuit
         public string proof;
     }
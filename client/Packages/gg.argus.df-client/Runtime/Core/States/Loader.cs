@@ -236,7 +236,8 @@ void OnHomePlanetClaimDenied()
                         new ClaimHomePlanetMsg
                         {
                             perlin = planet.PerlinValue,
-                            locationHash = planet.LocationHash
+                            locationHash = planet.LocationHash,
+                            radius = _map.WorldRadius
                         });
                 },
                 Exited = () =>